@@ -2,16 +2,20 @@ module github.com/idongju/t9s
 
 go 1.20
 
+require (
+	github.com/fsnotify/fsnotify v1.7.0
+	github.com/gdamore/tcell/v2 v2.8.1
+	github.com/mattn/go-sqlite3 v1.14.32
+	github.com/rivo/tview v0.42.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
 require (
 	github.com/gdamore/encoding v1.0.1 // indirect
-	github.com/gdamore/tcell/v2 v2.8.1 // indirect
 	github.com/lucasb-eyer/go-colorful v1.2.0 // indirect
 	github.com/mattn/go-runewidth v0.0.16 // indirect
-	github.com/mattn/go-sqlite3 v1.14.32 // indirect
-	github.com/rivo/tview v0.42.0 // indirect
 	github.com/rivo/uniseg v0.4.7 // indirect
 	golang.org/x/sys v0.29.0 // indirect
 	golang.org/x/term v0.28.0 // indirect
 	golang.org/x/text v0.21.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
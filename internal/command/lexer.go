@@ -0,0 +1,371 @@
+package command
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type quoteKind int
+
+const (
+	unquoted quoteKind = iota
+	singleQuoted
+	doubleQuoted
+)
+
+// part is a piece of a word with uniform quoting
+type part struct {
+	text        string // literal text with escapes resolved
+	raw         string // literal text as written, for shell scripts
+	placeholder string // placeholder name when the part is {name}
+	quote       quoteKind
+}
+
+// token is either a word made of parts or a shell operator
+type token struct {
+	parts []part
+	op    string
+	space bool // preceded by whitespace
+	pos   int  // byte offset in the lexed text
+}
+
+// operators recognised outside of quotes, longest first
+var operators = []string{"||", "&&", ">>", ">&", "<&", "|", "&", ";", ">", "<"}
+
+var (
+	identPattern      = regexp.MustCompile(`^[a-z_][a-z0-9_]*`)
+	assignmentPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*=`)
+)
+
+// missing reports whether the word references an unset or empty placeholder
+func (t token) missing(vars Vars) bool {
+	for _, p := range t.parts {
+		if p.placeholder != "" && vars[p.placeholder] == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// value returns the word with placeholders substituted
+func (t token) value(vars Vars) string {
+	var b strings.Builder
+	for _, p := range t.parts {
+		if p.placeholder != "" {
+			b.WriteString(vars[p.placeholder])
+		} else {
+			b.WriteString(p.text)
+		}
+	}
+	return b.String()
+}
+
+// shellText returns the word for a shell script, quoting substituted values
+func (t token) shellText(vars Vars) string {
+	var b strings.Builder
+	for _, p := range t.parts {
+		switch {
+		case p.placeholder != "":
+			b.WriteString(Quote(vars[p.placeholder]))
+		case p.quote == singleQuoted:
+			b.WriteString("'" + p.raw + "'")
+		case p.quote == doubleQuoted:
+			b.WriteString(`"` + p.raw + `"`)
+		default:
+			b.WriteString(p.raw)
+		}
+	}
+	return b.String()
+}
+
+// isAssignment reports whether the word looks like NAME=value
+func isAssignment(t token) bool {
+	if len(t.parts) == 0 || t.parts[0].quote != unquoted || t.parts[0].placeholder != "" {
+		return false
+	}
+	return assignmentPattern.MatchString(t.parts[0].text)
+}
+
+// lex splits a template into words and operators
+func lex(text string) ([]token, error) {
+	var tokens []token
+	var cur *token
+	space := true
+	at := 0 // start of the character being lexed
+
+	addPart := func(p part) {
+		if cur == nil {
+			cur = &token{space: space, pos: at}
+		}
+		// Merge adjacent literals with the same quoting
+		if n := len(cur.parts); n > 0 && p.placeholder == "" {
+			last := &cur.parts[n-1]
+			if last.placeholder == "" && last.quote == p.quote {
+				last.text += p.text
+				last.raw += p.raw
+				return
+			}
+		}
+		cur.parts = append(cur.parts, p)
+	}
+	endWord := func() {
+		if cur != nil {
+			tokens = append(tokens, *cur)
+			cur = nil
+		}
+	}
+
+	for i := 0; i < len(text); {
+		c := text[i]
+		at = i
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			endWord()
+			space = true
+			i++
+			continue
+
+		case c == '\\':
+			if i+1 >= len(text) {
+				return nil, fmt.Errorf("trailing backslash in template")
+			}
+			addPart(part{text: text[i+1 : i+2], raw: text[i : i+2]})
+			i += 2
+
+		case c == '\'':
+			end := strings.IndexByte(text[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated single quote in template")
+			}
+			s := text[i+1 : i+1+end]
+			addPart(part{text: s, raw: s, quote: singleQuoted})
+			i += end + 2
+
+		case c == '"':
+			n, err := lexDoubleQuoted(text[i+1:], addPart)
+			if err != nil {
+				return nil, err
+			}
+			i += n + 2
+
+		case c == '{':
+			if name, n := placeholderAt(text[i:]); n > 0 {
+				addPart(part{placeholder: name})
+				i += n
+			} else {
+				addPart(part{text: "{", raw: "{"})
+				i++
+			}
+
+		default:
+			if op := operatorAt(text[i:]); op != "" {
+				endWord()
+				tokens = append(tokens, token{op: op, space: space, pos: i})
+				i += len(op)
+				space = false
+				continue
+			}
+			addPart(part{text: text[i : i+1], raw: text[i : i+1]})
+			i++
+		}
+		space = false
+	}
+	endWord()
+
+	return tokens, nil
+}
+
+// lexDoubleQuoted reads the body of a double quoted string and returns its length
+func lexDoubleQuoted(text string, addPart func(part)) (int, error) {
+	var lit, raw strings.Builder
+	flush := func() {
+		if raw.Len() > 0 {
+			addPart(part{text: lit.String(), raw: raw.String(), quote: doubleQuoted})
+			lit.Reset()
+			raw.Reset()
+		}
+	}
+
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == '"':
+			flush()
+			if i == 0 {
+				// Keep empty strings as an (empty) argument
+				addPart(part{quote: doubleQuoted})
+			}
+			return i, nil
+		case c == '\\' && i+1 < len(text) && strings.IndexByte("\"\\$`", text[i+1]) >= 0:
+			lit.WriteByte(text[i+1])
+			raw.WriteString(text[i : i+2])
+			i += 2
+		case c == '{':
+			if name, n := placeholderAt(text[i:]); n > 0 {
+				flush()
+				addPart(part{placeholder: name, quote: doubleQuoted})
+				i += n
+				continue
+			}
+			lit.WriteByte(c)
+			raw.WriteByte(c)
+			i++
+		default:
+			lit.WriteByte(c)
+			raw.WriteByte(c)
+			i++
+		}
+	}
+	return 0, fmt.Errorf("unterminated double quote in template")
+}
+
+// placeholderAt returns the placeholder name and its length if text starts with {name}
+func placeholderAt(text string) (string, int) {
+	name := identPattern.FindString(text[1:])
+	if name == "" || len(text) < len(name)+2 || text[len(name)+1] != '}' {
+		return "", 0
+	}
+	return name, len(name) + 2
+}
+
+// isPlaceholder reports whether name is one of the placeholders filled in by t9s
+func isPlaceholder(name string) bool {
+	for _, p := range Placeholders {
+		if p == name {
+			return true
+		}
+	}
+	return false
+}
+
+// unknownPlaceholder returns the error for a misspelled or unsupported placeholder
+func unknownPlaceholder(name string) error {
+	return fmt.Errorf("unknown placeholder {%s}, expected one of %s", name, strings.Join(Placeholders, ", "))
+}
+
+// operatorAt returns the shell operator text starts with, if any
+func operatorAt(text string) string {
+	for _, op := range operators {
+		if strings.HasPrefix(text, op) {
+			return op
+		}
+	}
+	return ""
+}
+
+// quoteState follows the quoting of a template character by character, so that
+// braces inside single quotes or after a backslash are not taken for syntax
+type quoteState struct {
+	single, double bool
+}
+
+// step moves over the character at text[i]. It returns the number of bytes it spans,
+// two for an escape, and whether it is active syntax: outside single quotes and not escaped.
+func (q *quoteState) step(text string, i int) (int, bool) {
+	c := text[i]
+	switch {
+	case q.single:
+		if c == '\'' {
+			q.single = false
+		}
+		return 1, false
+	case c == '\\' && i+1 < len(text):
+		// Inside double quotes a backslash only escapes " \ $ and `
+		if q.double && strings.IndexByte("\"\\$`", text[i+1]) < 0 {
+			return 1, false
+		}
+		return 2, false
+	case c == '"':
+		q.double = !q.double
+	case c == '\'' && !q.double:
+		q.single = true
+		return 1, false
+	}
+	return 1, true
+}
+
+// expandSections resolves {?name ...} and {!name ...} conditional sections
+func expandSections(text string, vars Vars) (string, error) {
+	return expandSectionsFrom(text, vars, quoteState{})
+}
+
+// expandSectionsFrom resolves the sections of text, which starts with the given quoting.
+// Placeholders are checked in removed sections too, so a typo fails whatever is set.
+func expandSectionsFrom(text string, vars Vars, q quoteState) (string, error) {
+	var b strings.Builder
+
+	for i := 0; i < len(text); {
+		n, active := q.step(text, i)
+		if !active || text[i] != '{' || i+1 >= len(text) || (text[i+1] != '?' && text[i+1] != '!') {
+			if active && text[i] == '{' {
+				if name, _ := placeholderAt(text[i:]); name != "" && !isPlaceholder(name) {
+					return "", unknownPlaceholder(name)
+				}
+			}
+			b.WriteString(text[i : i+n])
+			i += n
+			continue
+		}
+
+		negate := text[i+1] == '!'
+		name := identPattern.FindString(text[i+2:])
+		if name == "" {
+			b.WriteByte('{')
+			i++
+			continue
+		}
+
+		if !isPlaceholder(name) {
+			return "", unknownPlaceholder(name)
+		}
+
+		start := i + 2 + len(name)
+		end := closingBrace(text, start, q)
+		if end < 0 {
+			return "", fmt.Errorf("unterminated section {%c%s", text[i+1], name)
+		}
+
+		inner, err := expandSectionsFrom(text[start:end], vars, q)
+		if err != nil {
+			return "", err
+		}
+		if set := vars[name] != ""; set != negate {
+			b.WriteString(inner)
+		}
+		i = end + 1
+	}
+
+	return b.String(), nil
+}
+
+// closingBrace returns the index of the brace closing a section whose body starts at
+// start, skipping nested and quoted braces, or -1
+func closingBrace(text string, start int, q quoteState) int {
+	depth := 1
+	for j := start; j < len(text); {
+		n, active := q.step(text, j)
+		if active {
+			switch text[j] {
+			case '{':
+				depth++
+			case '}':
+				depth--
+				if depth == 0 {
+					return j
+				}
+			}
+		}
+		j += n
+	}
+	return -1
+}
+
+// isRedirect reports whether an operator redirects input or output
+func isRedirect(op string) bool {
+	switch op {
+	case ">", ">>", "<", ">&", "<&":
+		return true
+	}
+	return false
+}
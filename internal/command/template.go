@@ -0,0 +1,297 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// Vars holds the values substituted into a command template.
+// Known placeholders are {dir}, {stack}, {env}, {varfile}, {initconf}, {workspace} and {branch}.
+type Vars map[string]string
+
+// Placeholders lists the placeholders filled in by t9s, in display order
+var Placeholders = []string{"dir", "stack", "env", "varfile", "initconf", "workspace", "branch"}
+
+// shellPrefix marks a template that must be run through "sh -c"
+const shellPrefix = "shell:"
+
+// Command is a rendered command template
+type Command struct {
+	Argv   []string // program and arguments, passed to the process as-is
+	Env    []string // NAME=value assignments from the template prefix
+	Shell  bool     // true when the template asked for shell execution
+	Script string   // shell script, only set when Shell is true
+}
+
+// Render renders a command template with the given variables.
+//
+// The template is split into words like a POSIX shell does: single quotes,
+// double quotes and backslash escapes are honoured, and substituted values
+// are never split again. Additional rules:
+//
+//   - {name} is replaced by the value of name (not inside single quotes)
+//   - a word that references an unset or empty placeholder is dropped,
+//     so "-var-file={varfile}" disappears when no var file is selected
+//   - a placeholder that is not in Placeholders is an error, so a typo
+//     like {varfiel} fails instead of silently dropping its word
+//   - {?name ...} keeps its content only when name is set,
+//     {!name ...} only when name is unset
+//   - leading NAME=value words become environment variables
+//   - "shell:" as prefix runs the rest through "sh -c"; pipes, redirects
+//     and command lists are rejected without it
+func Render(template string, vars Vars) (*Command, error) {
+	text := strings.TrimSpace(template)
+	shell := false
+	if strings.HasPrefix(text, shellPrefix) {
+		shell = true
+		text = strings.TrimSpace(strings.TrimPrefix(text, shellPrefix))
+	}
+
+	text, err := expandSections(text, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := lex(text)
+	if err != nil {
+		return nil, err
+	}
+
+	if shell {
+		return renderShell(tokens, vars)
+	}
+	return renderArgv(tokens, vars)
+}
+
+// renderArgv turns tokens into an argv without involving a shell
+func renderArgv(tokens []token, vars Vars) (*Command, error) {
+	cmd := &Command{}
+	for _, tok := range tokens {
+		if tok.op != "" {
+			return nil, fmt.Errorf("shell operator %q requires a %q template", tok.op, shellPrefix)
+		}
+		if tok.missing(vars) {
+			continue
+		}
+		word := tok.value(vars)
+		if len(cmd.Argv) == 0 && isAssignment(tok) {
+			cmd.Env = append(cmd.Env, word)
+			continue
+		}
+		cmd.Argv = append(cmd.Argv, word)
+	}
+
+	if len(cmd.Argv) == 0 {
+		return nil, fmt.Errorf("template renders to an empty command")
+	}
+	return cmd, nil
+}
+
+// renderShell turns tokens into a script for "sh -c" with every value quoted
+func renderShell(tokens []token, vars Vars) (*Command, error) {
+	var b strings.Builder
+	for _, tok := range tokens {
+		text := tok.op
+		if text == "" {
+			if tok.missing(vars) {
+				continue
+			}
+			text = tok.shellText(vars)
+		}
+		if tok.space && b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(text)
+	}
+
+	script := b.String()
+	if script == "" {
+		return nil, fmt.Errorf("template renders to an empty command")
+	}
+	return &Command{
+		Argv:   []string{"sh", "-c", script},
+		Shell:  true,
+		Script: script,
+	}, nil
+}
+
+// Append adds arguments to the end of the command. For a shell template they are added
+// to the terraform command of the script, or to its first command if none runs terraform,
+// before any redirect, pipe or command list: "terraform apply 2>&1 | tee out.log" becomes
+// "terraform apply -auto-approve 2>&1 | tee out.log".
+func (c *Command) Append(args ...string) {
+	if c.Shell {
+		quoted := make([]string, len(args))
+		for i, arg := range args {
+			quoted[i] = Quote(arg)
+		}
+		at := appendOffset(c.Script)
+		head := strings.TrimRight(c.Script[:at], " \t")
+		c.Script = head + " " + strings.Join(quoted, " ") + c.Script[len(head):]
+		c.Argv = []string{"sh", "-c", c.Script}
+		return
+	}
+	c.Argv = append(c.Argv, args...)
+}
+
+// appendOffset returns where arguments are added to a script: the end of the words of its
+// first terraform command, or of its first command. A file descriptor number written
+// right before a redirect, like the 2 of 2>&1, is not a word of the command.
+func appendOffset(script string) int {
+	tokens, err := lex(script)
+	if err != nil {
+		return len(script)
+	}
+
+	// Simple commands as ranges of tokens, split at operators other than redirects
+	type simple struct{ from, to int }
+	var commands []simple
+	from := 0
+	for i, tok := range tokens {
+		if tok.op != "" && !isRedirect(tok.op) {
+			commands = append(commands, simple{from, i})
+			from = i + 1
+		}
+	}
+	commands = append(commands, simple{from, len(tokens)})
+
+	chosen := commands[0]
+	for _, cmd := range commands {
+		if runsTerraform(tokens[cmd.from:cmd.to]) {
+			chosen = cmd
+			break
+		}
+	}
+
+	// The words end at the first redirect of the command
+	end := chosen.to
+	for i := chosen.from; i < chosen.to; i++ {
+		if tokens[i].op != "" {
+			end = i
+			if i > chosen.from && !tokens[i].space && isFileDescriptor(tokens[i-1]) {
+				end = i - 1
+			}
+			break
+		}
+	}
+	if end < len(tokens) {
+		return tokens[end].pos
+	}
+	return len(script)
+}
+
+// runsTerraform reports whether a simple command runs terraform or a wrapper of it
+func runsTerraform(tokens []token) bool {
+	for _, tok := range tokens {
+		if tok.op != "" {
+			return false
+		}
+		if isAssignment(tok) {
+			continue
+		}
+		name := tok.value(nil)
+		if i := strings.LastIndexByte(name, '/'); i >= 0 {
+			name = name[i+1:]
+		}
+		return name == "terraform" || name == "tofu" || name == "terragrunt"
+	}
+	return false
+}
+
+// isFileDescriptor reports whether a word is a file descriptor number such as 2
+func isFileDescriptor(tok token) bool {
+	if len(tok.parts) != 1 || tok.parts[0].quote != unquoted || tok.parts[0].placeholder != "" {
+		return false
+	}
+	return strings.Trim(tok.parts[0].text, "0123456789") == ""
+}
+
+// HasArg reports whether the command contains the given argument
+func (c *Command) HasArg(arg string) bool {
+	if c.Shell {
+		for _, field := range strings.Fields(c.Script) {
+			if strings.Trim(field, `'"`) == arg {
+				return true
+			}
+		}
+		return false
+	}
+	for _, a := range c.Argv {
+		if a == arg {
+			return true
+		}
+	}
+	return false
+}
+
+// Clone returns a copy of the command that can be modified independently
+func (c *Command) Clone() *Command {
+	clone := *c
+	clone.Argv = append([]string(nil), c.Argv...)
+	clone.Env = append([]string(nil), c.Env...)
+	return &clone
+}
+
+// String returns a shell-quoted preview of the command
+func (c *Command) String() string {
+	if c.Shell {
+		return "sh -c " + Quote(c.Script)
+	}
+	parts := make([]string, 0, len(c.Env)+len(c.Argv))
+	for _, env := range c.Env {
+		name, value, _ := strings.Cut(env, "=")
+		parts = append(parts, name+"="+Quote(value))
+	}
+	for _, arg := range c.Argv {
+		parts = append(parts, Quote(arg))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Exec builds an exec.Cmd that runs in dir with the template environment added
+func (c *Command) Exec(dir string) *exec.Cmd {
+	return c.ExecEnv(dir, os.Environ())
+}
+
+// ExecEnv builds an exec.Cmd that runs in dir with base as environment,
+// plus the assignments from the template
+func (c *Command) ExecEnv(dir string, base []string) *exec.Cmd {
+	cmd := exec.Command(c.Argv[0], c.Argv[1:]...)
+	cmd.Dir = dir
+	cmd.Env = append(append([]string(nil), base...), c.Env...)
+	return cmd
+}
+
+// Preview renders a template with sample values for a dry run
+func Preview(template string) (*Command, error) {
+	return Render(template, SampleVars())
+}
+
+// SampleVars returns example values for every placeholder
+func SampleVars() Vars {
+	return Vars{
+		"dir":       "/infra/platform/network",
+		"stack":     "platform/network",
+		"env":       "prod",
+		"varfile":   "/infra/platform/network/config/prod.tfvars",
+		"initconf":  "/infra/platform/network/config/prod.conf",
+		"workspace": "default",
+		"branch":    "main",
+	}
+}
+
+var safeWord = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// Quote quotes a string for display or use in a POSIX shell
+func Quote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	if safeWord.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
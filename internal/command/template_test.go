@@ -0,0 +1,276 @@
+package command
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRenderArgv(t *testing.T) {
+	vars := Vars{
+		"dir":     "/infra/net",
+		"varfile": "/infra/net/config/prod env.tfvars",
+		"env":     "prod",
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		argv     []string
+		env      []string
+	}{
+		{
+			name:     "plain words",
+			template: "terraform plan -input=false",
+			argv:     []string{"terraform", "plan", "-input=false"},
+		},
+		{
+			name:     "placeholder value is not split",
+			template: "terraform plan -var-file={varfile}",
+			argv:     []string{"terraform", "plan", "-var-file=/infra/net/config/prod env.tfvars"},
+		},
+		{
+			name:     "word with unset placeholder is dropped",
+			template: "terraform init -backend-config={initconf} -upgrade",
+			argv:     []string{"terraform", "init", "-upgrade"},
+		},
+		{
+			name:     "single quotes keep placeholders literal",
+			template: "echo '{env}' {env}",
+			argv:     []string{"echo", "{env}", "prod"},
+		},
+		{
+			name:     "double quotes substitute placeholders",
+			template: `echo "env is {env}"`,
+			argv:     []string{"echo", "env is prod"},
+		},
+		{
+			name:     "backslash escapes",
+			template: `echo a\ b \"c\" "d\"e" "f\g"`,
+			argv:     []string{"echo", "a b", `"c"`, `d"e`, `f\g`},
+		},
+		{
+			name:     "empty double quoted argument is kept",
+			template: `echo ""`,
+			argv:     []string{"echo", ""},
+		},
+		{
+			name:     "leading assignments become environment",
+			template: "TF_LOG=debug TF_IN_AUTOMATION=1 terraform plan A=b",
+			argv:     []string{"terraform", "plan", "A=b"},
+			env:      []string{"TF_LOG=debug", "TF_IN_AUTOMATION=1"},
+		},
+		{
+			name:     "set section is kept",
+			template: "terraform plan {?varfile -var-file={varfile}}",
+			argv:     []string{"terraform", "plan", "-var-file=/infra/net/config/prod env.tfvars"},
+		},
+		{
+			name:     "unset section is removed",
+			template: "terraform init {?initconf -backend-config={initconf} -reconfigure} -upgrade",
+			argv:     []string{"terraform", "init", "-upgrade"},
+		},
+		{
+			name:     "negated section",
+			template: "terraform init {!initconf -backend=false}",
+			argv:     []string{"terraform", "init", "-backend=false"},
+		},
+		{
+			name:     "nested sections",
+			template: "terraform plan {?env {?varfile -var-file={varfile}} -var=env={env}}",
+			argv:     []string{"terraform", "plan", "-var-file=/infra/net/config/prod env.tfvars", "-var=env=prod"},
+		},
+		{
+			name:     "apostrophe in double quotes before a section",
+			template: `terraform plan -var "note=it's" {?initconf -backend-config={initconf}}`,
+			argv:     []string{"terraform", "plan", "-var", "note=it's"},
+		},
+		{
+			name:     "escaped apostrophe before a section",
+			template: `terraform plan -var note=it\'s {?initconf -backend-config={initconf}}`,
+			argv:     []string{"terraform", "plan", "-var", "note=it's"},
+		},
+		{
+			name:     "section syntax in single quotes is literal",
+			template: "echo '{?env x}' {?env y}",
+			argv:     []string{"echo", "{?env x}", "y"},
+		},
+		{
+			name:     "escaped brace is literal",
+			template: `echo \{?env x}`,
+			argv:     []string{"echo", "{?env", "x}"},
+		},
+		{
+			name:     "quoted brace inside a section",
+			template: `terraform plan {?env -var 'brace=}' -var "apostrophe='"}`,
+			argv:     []string{"terraform", "plan", "-var", "brace=}", "-var", "apostrophe='"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := Render(tt.template, vars)
+			if err != nil {
+				t.Fatalf("Render(%q): %v", tt.template, err)
+			}
+			if !reflect.DeepEqual(cmd.Argv, tt.argv) {
+				t.Errorf("Render(%q).Argv = %q, want %q", tt.template, cmd.Argv, tt.argv)
+			}
+			if !reflect.DeepEqual(cmd.Env, tt.env) {
+				t.Errorf("Render(%q).Env = %q, want %q", tt.template, cmd.Env, tt.env)
+			}
+		})
+	}
+}
+
+func TestRenderShell(t *testing.T) {
+	vars := Vars{"varfile": "config/prod env.tfvars", "stack": "net"}
+
+	tests := []struct {
+		template string
+		script   string
+	}{
+		{"shell: terraform plan {?varfile -var-file={varfile}} | tee plan.log", "terraform plan -var-file='config/prod env.tfvars' | tee plan.log"},
+		{"shell: terraform plan {?initconf -backend-config={initconf}} && echo done", "terraform plan && echo done"},
+		{`shell: echo "stack {stack}" > out.txt`, `echo "stack "net > out.txt`},
+		{"shell: terraform plan 2>&1", "terraform plan 2>&1"},
+	}
+
+	for _, tt := range tests {
+		cmd, err := Render(tt.template, vars)
+		if err != nil {
+			t.Fatalf("Render(%q): %v", tt.template, err)
+		}
+		if cmd.Script != tt.script {
+			t.Errorf("Render(%q).Script = %q, want %q", tt.template, cmd.Script, tt.script)
+		}
+		if !cmd.Shell || !reflect.DeepEqual(cmd.Argv, []string{"sh", "-c", tt.script}) {
+			t.Errorf("Render(%q).Argv = %q, want sh -c script", tt.template, cmd.Argv)
+		}
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	tests := []struct {
+		template string
+		err      string
+	}{
+		{"terraform plan | tee out.log", "requires"},
+		{"terraform plan 'unterminated", "unterminated single quote"},
+		{`terraform plan "unterminated`, "unterminated double quote"},
+		{`terraform plan \`, "trailing backslash"},
+		{"terraform plan {?varfile -var-file={varfile}", "unterminated section"},
+		{"{varfile}", "empty command"},
+		{"terraform plan -var-file={varfiel}", "unknown placeholder {varfiel}"},
+		{`terraform plan "-var-file={varfiel}"`, "unknown placeholder {varfiel}"},
+		{"terraform plan {?varfiel -var-file={varfile}}", "unknown placeholder {varfiel}"},
+		{"terraform init {!initcnf -backend=false}", "unknown placeholder {initcnf}"},
+		{"terraform init {?initconf -backend-config={initcnf}}", "unknown placeholder {initcnf}"},
+	}
+
+	for _, tt := range tests {
+		_, err := Render(tt.template, Vars{})
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("Render(%q) error = %v, want %q", tt.template, err, tt.err)
+		}
+	}
+}
+
+func TestAppend(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		args     []string
+		want     string // script for shell templates, space-joined argv otherwise
+	}{
+		{
+			name:     "argv",
+			template: "terraform apply -var-file={varfile}",
+			args:     []string{"-input=false", "-auto-approve"},
+			want:     "terraform apply -var-file=prod.tfvars -input=false -auto-approve",
+		},
+		{
+			name:     "shell without operators",
+			template: "shell: terraform apply",
+			args:     []string{"-auto-approve"},
+			want:     "terraform apply -auto-approve",
+		},
+		{
+			name:     "shell pipe",
+			template: "shell: terraform apply {?varfile -var-file={varfile}} | tee out.log",
+			args:     []string{"-input=false", "-auto-approve"},
+			want:     "terraform apply -var-file=prod.tfvars -input=false -auto-approve | tee out.log",
+		},
+		{
+			name:     "shell redirect with file descriptor",
+			template: "shell: terraform apply 2>&1 | tee out.log",
+			args:     []string{"-json"},
+			want:     "terraform apply -json 2>&1 | tee out.log",
+		},
+		{
+			name:     "shell output redirect",
+			template: "shell: terraform plan >plan.log",
+			args:     []string{"-target=aws_s3_bucket.logs"},
+			want:     "terraform plan -target=aws_s3_bucket.logs >plan.log",
+		},
+		{
+			name:     "shell terraform after another command",
+			template: "shell: cd {dir} && TF_LOG=info terraform plan; echo done",
+			args:     []string{"-out=/tmp/plan file"},
+			want:     "cd /infra/net && TF_LOG=info terraform plan '-out=/tmp/plan file'; echo done",
+		},
+		{
+			name:     "shell without terraform uses the first command",
+			template: "shell: ./wrapper.sh plan | tee out.log",
+			args:     []string{"-replace=aws_instance.web"},
+			want:     "./wrapper.sh plan -replace=aws_instance.web | tee out.log",
+		},
+		{
+			name:     "shell operator inside quotes",
+			template: `shell: terraform apply -var 'x=a|b' && echo "done; ok"`,
+			args:     []string{"-auto-approve"},
+			want:     `terraform apply -var 'x=a|b' -auto-approve && echo "done; ok"`,
+		},
+	}
+
+	vars := Vars{"varfile": "prod.tfvars", "dir": "/infra/net"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := Render(tt.template, vars)
+			if err != nil {
+				t.Fatalf("Render(%q): %v", tt.template, err)
+			}
+			cmd.Append(tt.args...)
+			got := strings.Join(cmd.Argv, " ")
+			if cmd.Shell {
+				got = cmd.Script
+				if cmd.Argv[2] != cmd.Script {
+					t.Errorf("Argv not updated: %q", cmd.Argv)
+				}
+			}
+			if got != tt.want {
+				t.Errorf("Append(%q) = %q, want %q", tt.args, got, tt.want)
+			}
+			for _, arg := range tt.args {
+				if !cmd.HasArg(arg) && !strings.Contains(arg, " ") {
+					t.Errorf("HasArg(%q) = false after Append", arg)
+				}
+			}
+		})
+	}
+}
+
+func TestQuote(t *testing.T) {
+	tests := map[string]string{
+		"":              "''",
+		"plain":         "plain",
+		"-var-file=a/b": "-var-file=a/b",
+		"a b":           "'a b'",
+		"it's":          `'it'\''s'`,
+	}
+	for in, want := range tests {
+		if got := Quote(in); got != want {
+			t.Errorf("Quote(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
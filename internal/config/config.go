@@ -4,16 +4,57 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
 // Config represents the application configuration
 type Config struct {
-	TerraformRoot   string         `yaml:"terraform_root"`
-	Backend         BackendConfig  `yaml:"backend"`
-	Defaults        DefaultsConfig `yaml:"defaults"`
-	Commands        CommandsConfig `yaml:"commands"`
+	TerraformRoot  string          `yaml:"terraform_root"`
+	CurrentContext string          `yaml:"current_context,omitempty"`
+	Contexts       []ContextConfig `yaml:"contexts,omitempty"`
+	EnvProfiles    []EnvProfile    `yaml:"env_profiles,omitempty"`
+	Discovery      DiscoveryConfig `yaml:"discovery,omitempty"`
+	Backend        BackendConfig   `yaml:"backend"`
+	Defaults       DefaultsConfig  `yaml:"defaults"`
+	Commands       CommandsConfig  `yaml:"commands"`
+}
+
+// ContextConfig represents a named terraform root with its own command templates.
+// The active context is mirrored into Config.TerraformRoot and Config.Commands.
+type ContextConfig struct {
+	Name          string         `yaml:"name"`
+	TerraformRoot string         `yaml:"terraform_root"`
+	Commands      CommandsConfig `yaml:"commands"`
+	HistoryDir    string         `yaml:"history_dir,omitempty"` // directory holding .t9s/history.db, defaults to terraform_root
+}
+
+// EnvProfile represents environment variables applied to terraform runs
+// whose tfvars name or directory matches one of the Match patterns
+type EnvProfile struct {
+	Name   string            `yaml:"name"`
+	Match  []string          `yaml:"match"`            // e.g. "prod", "prod-*", "*/production/*"
+	Set    map[string]string `yaml:"set,omitempty"`    // e.g. AWS_PROFILE: prod-admin
+	Unset  []string          `yaml:"unset,omitempty"`  // e.g. "AWS_PROFILE", "TF_VAR_*"
+	DotEnv bool              `yaml:"dotenv,omitempty"` // load <env>.env or .env next to the tfvars file
+}
+
+// DiscoveryConfig controls how stacks are found below the terraform root
+type DiscoveryConfig struct {
+	MaxDepth int      `yaml:"max_depth,omitempty"` // directory levels below the root, defaults to DefaultMaxDepth
+	Ignore   []string `yaml:"ignore,omitempty"`    // globs relative to the root, e.g. "modules/**", "**/examples"
+}
+
+// DefaultMaxDepth is the discovery depth used when max_depth is not set
+const DefaultMaxDepth = 6
+
+// Depth returns the maximum discovery depth
+func (d DiscoveryConfig) Depth() int {
+	if d.MaxDepth <= 0 {
+		return DefaultMaxDepth
+	}
+	return d.MaxDepth
 }
 
 // BackendConfig represents the Terraform backend configuration
@@ -25,20 +66,157 @@ type BackendConfig struct {
 
 // DefaultsConfig represents default application settings
 type DefaultsConfig struct {
-	AutoRefresh     bool `yaml:"auto_refresh"`
-	RefreshInterval int  `yaml:"refresh_interval"` // in seconds
+	AutoRefresh      bool `yaml:"auto_refresh"`
+	RefreshInterval  int  `yaml:"refresh_interval"`            // in seconds
+	BatchParallelism int  `yaml:"batch_parallelism,omitempty"` // stacks run at once by batch init/plan/validate
+	JSONProgress     bool `yaml:"json_progress,omitempty"`     // run auto-approved apply/destroy/refresh with -json and show live progress
+}
+
+// DefaultBatchParallelism is used when batch_parallelism is not set
+const DefaultBatchParallelism = 4
+
+// Parallelism returns the number of stacks a batch runs at once
+func (d DefaultsConfig) Parallelism() int {
+	if d.BatchParallelism <= 0 {
+		return DefaultBatchParallelism
+	}
+	return d.BatchParallelism
 }
 
 // CommandsConfig represents terraform command templates
 type CommandsConfig struct {
-	InitTemplate    string `yaml:"init_template"`    // e.g. "terraform init -backend-config={initconf}"
-	PlanTemplate    string `yaml:"plan_template"`    // e.g. "terraform plan -var-file={varfile}"
-	ApplyTemplate   string `yaml:"apply_template"`   // e.g. "terraform apply -var-file={varfile}"
-	DestroyTemplate string `yaml:"destroy_template"` // e.g. "terraform destroy -var-file={varfile}"
-	TfvarsFile      string `yaml:"tfvars_file"`      // e.g. "config/env.tfvars"
-	InitConfFile    string `yaml:"init_conf_file"`   // e.g. "config/env.conf"
+	InitTemplate        string      `yaml:"init_template"`         // e.g. "terraform init -backend-config={initconf}"
+	PlanTemplate        string      `yaml:"plan_template"`         // e.g. "terraform plan -var-file={varfile}"
+	ApplyTemplate       string      `yaml:"apply_template"`        // e.g. "terraform apply -var-file={varfile}"
+	DestroyTemplate     string      `yaml:"destroy_template"`      // e.g. "terraform destroy -var-file={varfile}"
+	RefreshTemplate     string      `yaml:"refresh_template"`      // e.g. "terraform apply -refresh-only -var-file={varfile}"
+	RefreshPlanTemplate string      `yaml:"refresh_plan_template"` // e.g. "terraform plan -refresh-only -var-file={varfile}"
+	ValidateTemplate    string      `yaml:"validate_template"`     // e.g. "terraform validate"
+	TfvarsFile          string      `yaml:"tfvars_file"`           // e.g. "config/env.tfvars"
+	InitConfFile        string      `yaml:"init_conf_file"`        // e.g. "config/env.conf"
+	Hooks               HooksConfig `yaml:"hooks,omitempty"`
+}
+
+// HooksConfig represents commands run before and after terraform actions.
+// Each entry is a command template, rendered like the terraform templates.
+type HooksConfig struct {
+	PreInit     []string `yaml:"pre_init,omitempty"` // e.g. "aws sso login --profile {env}"
+	PostInit    []string `yaml:"post_init,omitempty"`
+	PrePlan     []string `yaml:"pre_plan,omitempty"` // e.g. "tflint", "terraform fmt -check"
+	PostPlan    []string `yaml:"post_plan,omitempty"`
+	PreApply    []string `yaml:"pre_apply,omitempty"`
+	PostApply   []string `yaml:"post_apply,omitempty"` // e.g. "./scripts/notify.sh"
+	PreDestroy  []string `yaml:"pre_destroy,omitempty"`
+	PostDestroy []string `yaml:"post_destroy,omitempty"`
+	PreRefresh  []string `yaml:"pre_refresh,omitempty"`
+	PostRefresh []string `yaml:"post_refresh,omitempty"`
+	OnFailure   []string `yaml:"on_failure,omitempty"` // run when a hook or the command fails
+}
+
+// Pre returns the hooks to run before an action ("init", "plan", "apply", "destroy", "refresh").
+// A refresh-only plan runs the plan hooks.
+func (h HooksConfig) Pre(action string) []string {
+	switch strings.ToLower(action) {
+	case "init":
+		return h.PreInit
+	case "plan", "refresh plan":
+		return h.PrePlan
+	case "apply":
+		return h.PreApply
+	case "destroy":
+		return h.PreDestroy
+	case "refresh":
+		return h.PreRefresh
+	}
+	return nil
 }
 
+// Post returns the hooks to run after an action succeeded
+func (h HooksConfig) Post(action string) []string {
+	switch strings.ToLower(action) {
+	case "init":
+		return h.PostInit
+	case "plan", "refresh plan":
+		return h.PostPlan
+	case "apply":
+		return h.PostApply
+	case "destroy":
+		return h.PostDestroy
+	case "refresh":
+		return h.PostRefresh
+	}
+	return nil
+}
+
+// Template returns the command template of an action ("init", "plan", "apply", "destroy", "refresh",
+// "refresh plan", "validate")
+func (cc CommandsConfig) Template(action string) string {
+	switch strings.ToLower(action) {
+	case "init":
+		return cc.InitTemplate
+	case "plan":
+		return cc.PlanTemplate
+	case "apply":
+		return cc.ApplyTemplate
+	case "destroy":
+		return cc.DestroyTemplate
+	case "refresh":
+		return cc.RefreshTemplate
+	case "refresh plan":
+		return cc.RefreshPlanTemplate
+	case "validate":
+		return cc.ValidateTemplate
+	}
+	return ""
+}
+
+// DefaultCommands returns the default terraform command templates
+func DefaultCommands() CommandsConfig {
+	return CommandsConfig{
+		InitTemplate:        "terraform init -backend-config={initconf}",
+		PlanTemplate:        "terraform plan -var-file={varfile}",
+		ApplyTemplate:       "terraform apply -var-file={varfile}",
+		DestroyTemplate:     "terraform destroy -var-file={varfile}",
+		RefreshTemplate:     "terraform apply -refresh-only -var-file={varfile}",
+		RefreshPlanTemplate: "terraform plan -refresh-only -var-file={varfile}",
+		ValidateTemplate:    "terraform validate",
+		TfvarsFile:          "config/env.tfvars",
+		InitConfFile:        "config/env.conf",
+	}
+}
+
+// withDefaults fills empty command templates with the defaults
+func (cc CommandsConfig) withDefaults() CommandsConfig {
+	def := DefaultCommands()
+	if cc.InitTemplate == "" {
+		cc.InitTemplate = def.InitTemplate
+	}
+	if cc.PlanTemplate == "" {
+		cc.PlanTemplate = def.PlanTemplate
+	}
+	if cc.ApplyTemplate == "" {
+		cc.ApplyTemplate = def.ApplyTemplate
+	}
+	if cc.DestroyTemplate == "" {
+		cc.DestroyTemplate = def.DestroyTemplate
+	}
+	if cc.RefreshTemplate == "" {
+		cc.RefreshTemplate = def.RefreshTemplate
+	}
+	if cc.RefreshPlanTemplate == "" {
+		cc.RefreshPlanTemplate = def.RefreshPlanTemplate
+	}
+	if cc.ValidateTemplate == "" {
+		cc.ValidateTemplate = def.ValidateTemplate
+	}
+	if cc.TfvarsFile == "" {
+		cc.TfvarsFile = def.TfvarsFile
+	}
+	if cc.InitConfFile == "" {
+		cc.InitConfFile = def.InitConfFile
+	}
+	return cc
+}
 
 // Load loads configuration from file
 func Load() (*Config, error) {
@@ -59,6 +237,11 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("failed to parse config: %w", err)
 	}
 
+	// The current context is the source of truth for root and commands
+	if ctx := config.GetContext(config.CurrentContext); ctx != nil {
+		config.applyContext(ctx)
+	}
+
 	return &config, nil
 }
 
@@ -66,6 +249,12 @@ func Load() (*Config, error) {
 func (c *Config) Save() error {
 	configPath := getConfigPath()
 
+	// Keep the current context in sync with edits made through settings
+	if ctx := c.GetContext(c.CurrentContext); ctx != nil {
+		ctx.TerraformRoot = c.TerraformRoot
+		ctx.Commands = c.Commands
+	}
+
 	data, err := yaml.Marshal(c)
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
@@ -84,6 +273,63 @@ func (c *Config) Save() error {
 	return nil
 }
 
+// GetContext returns the context with the given name, or nil if it doesn't exist
+func (c *Config) GetContext(name string) *ContextConfig {
+	if name == "" {
+		return nil
+	}
+	for i := range c.Contexts {
+		if c.Contexts[i].Name == name {
+			return &c.Contexts[i]
+		}
+	}
+	return nil
+}
+
+// UseContext makes the named context the active one
+func (c *Config) UseContext(name string) error {
+	ctx := c.GetContext(name)
+	if ctx == nil {
+		return fmt.Errorf("context %q not found", name)
+	}
+
+	// Persist pending edits of the previous context before switching away
+	if prev := c.GetContext(c.CurrentContext); prev != nil && prev != ctx {
+		prev.TerraformRoot = c.TerraformRoot
+		prev.Commands = c.Commands
+	}
+
+	c.CurrentContext = name
+	c.applyContext(ctx)
+	return nil
+}
+
+// HistoryDir returns the directory in which the history DB of the active context lives
+func (c *Config) HistoryDir() string {
+	if ctx := c.GetContext(c.CurrentContext); ctx != nil && ctx.HistoryDir != "" {
+		return ctx.HistoryDir
+	}
+	return c.TerraformRoot
+}
+
+// BackupDir returns the directory where state backups are written
+func (c *Config) BackupDir() string {
+	return filepath.Join(c.HistoryDir(), ".t9s", "backups")
+}
+
+// IndexPath returns the file caching the discovered stacks of the active root
+func (c *Config) IndexPath() string {
+	return filepath.Join(c.HistoryDir(), ".t9s", "index.json")
+}
+
+// applyContext mirrors a context into the active root and commands
+func (c *Config) applyContext(ctx *ContextConfig) {
+	if ctx.TerraformRoot != "" {
+		c.TerraformRoot = ctx.TerraformRoot
+	}
+	c.Commands = ctx.Commands.withDefaults()
+}
+
 // getConfigPath returns the path to the config file
 func getConfigPath() string {
 	home, err := os.UserHomeDir()
@@ -111,14 +357,7 @@ func createDefaultConfig(path string) (*Config, error) {
 			AutoRefresh:     true,
 			RefreshInterval: 60,
 		},
-		Commands: CommandsConfig{
-			InitTemplate:    "terraform init -backend-config={initconf}",
-			PlanTemplate:    "terraform plan -var-file={varfile}",
-			ApplyTemplate:   "terraform apply -var-file={varfile}",
-			DestroyTemplate: "terraform destroy -var-file={varfile}",
-			TfvarsFile:      "config/env.tfvars",
-			InitConfFile:    "config/env.conf",
-		},
+		Commands: DefaultCommands(),
 	}
 
 	// Save default config
@@ -0,0 +1,464 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Scopes of a key binding: where the focus must be for the binding to apply
+const (
+	ScopeGlobal  = "global"
+	ScopeTree    = "tree"
+	ScopeContent = "content"
+)
+
+// Scopes of the sub-views. Their keys only apply while the view is open,
+// so they only conflict with keys of the same view.
+const (
+	ScopeState       = "state"
+	ScopeStacks      = "stacks"
+	ScopeOutputs     = "outputs"
+	ScopeGraph       = "graph"
+	ScopeHistory     = "history"
+	ScopeDiagnostics = "diagnostics"
+)
+
+// Actions of the main screen, the names used in keys.yaml
+const (
+	ActionInit            = "init"
+	ActionPlan            = "plan"
+	ActionApply           = "apply"
+	ActionDestroy         = "destroy"
+	ActionRefresh         = "refresh"
+	ActionTarget          = "target"
+	ActionMoved           = "moved"
+	ActionHistory         = "history"
+	ActionDiagnostics     = "diagnostics"
+	ActionProgress        = "progress"
+	ActionJobs            = "jobs"
+	ActionMark            = "mark"
+	ActionBatch           = "batch"
+	ActionStacksOnly      = "stacks_only"
+	ActionDashboard       = "dashboard"
+	ActionGraph           = "graph"
+	ActionState           = "state"
+	ActionOutputs         = "outputs"
+	ActionWorkspaces      = "workspaces"
+	ActionContext         = "context"
+	ActionBranch          = "branch"
+	ActionEdit            = "edit"
+	ActionSearch          = "search"
+	ActionSearchNext      = "search_next"
+	ActionSearchPrev      = "search_prev"
+	ActionClearSearch     = "clear_search"
+	ActionDefinition      = "definition"
+	ActionWrap            = "wrap"
+	ActionFoldAll         = "fold_all"
+	ActionFoldDestructive = "fold_destructive"
+	ActionPrevBlock       = "prev_block"
+	ActionNextBlock       = "next_block"
+	ActionUnchanged       = "toggle_unchanged"
+	ActionScrollUp        = "scroll_up"
+	ActionScrollDown      = "scroll_down"
+	ActionFocus           = "focus"
+	ActionCommand         = "command"
+	ActionFinder          = "finder"
+	ActionHome            = "home"
+	ActionSettings        = "settings"
+	ActionHelp            = "help"
+	ActionQuit            = "quit"
+)
+
+// Actions of the sub-views
+const (
+	ActionStateFilter      = "state_filter"
+	ActionStateMove        = "state_move"
+	ActionStateRemove      = "state_remove"
+	ActionStateImport      = "state_import"
+	ActionStateReplace     = "state_replace"
+	ActionStateTarget      = "state_target"
+	ActionStacksMark       = "stacks_mark"
+	ActionStacksMarkDeps   = "stacks_mark_deps"
+	ActionStacksPlan       = "stacks_plan"
+	ActionStacksApply      = "stacks_apply"
+	ActionStacksBatch      = "stacks_batch"
+	ActionOutputsReveal    = "outputs_reveal"
+	ActionOutputsRevealAll = "outputs_reveal_all"
+	ActionOutputsCopy      = "outputs_copy"
+	ActionGraphFilter      = "graph_filter"
+	ActionGraphExport      = "graph_export"
+	ActionHistoryDetails   = "history_details"
+	ActionHistoryMore      = "history_more"
+	ActionHistoryLess      = "history_less"
+	ActionDiagnosticsView  = "diagnostics_view"
+)
+
+// KeyAction is an action of the main screen or of a sub-view that can be bound to keys
+type KeyAction struct {
+	Name        string
+	Description string
+	Section     string   // section of the help screen
+	Scope       string   // ScopeGlobal, ScopeTree, ScopeContent or the scope of a sub-view
+	Destructive bool     // must not share a key with an action of any other scope
+	Header      string   // label in the header, "" when not listed there
+	Keys        []string // default keys
+}
+
+// KeyActions lists every action in the order of the help screen
+var KeyActions = []KeyAction{
+	{Name: ActionInit, Description: "Init", Section: "TERRAFORM", Scope: ScopeGlobal, Header: "Init", Keys: []string{"i", "I"}},
+	{Name: ActionPlan, Description: "Plan", Section: "TERRAFORM", Scope: ScopeGlobal, Header: "Plan", Keys: []string{"p"}},
+	{Name: ActionApply, Description: "Apply", Section: "TERRAFORM", Scope: ScopeGlobal, Destructive: true, Header: "Apply", Keys: []string{"a"}},
+	{Name: ActionDestroy, Description: "Destroy", Section: "TERRAFORM", Scope: ScopeTree, Destructive: true, Header: "Destroy", Keys: []string{"D"}},
+	{Name: ActionRefresh, Description: "Refresh-Only", Section: "TERRAFORM", Scope: ScopeGlobal, Header: "Refresh", Keys: []string{"r"}},
+	{Name: ActionTarget, Description: "Targeted Plan/Apply", Section: "TERRAFORM", Scope: ScopeGlobal, Keys: []string{"T"}},
+	{Name: ActionMoved, Description: "Suggest Moved Blocks", Section: "TERRAFORM", Scope: ScopeGlobal, Keys: []string{"M"}},
+	{Name: ActionHistory, Description: "History", Section: "TERRAFORM", Scope: ScopeGlobal, Header: "History", Keys: []string{"h"}},
+	{Name: ActionDiagnostics, Description: "Diagnostics", Section: "TERRAFORM", Scope: ScopeGlobal, Keys: []string{"x"}},
+	{Name: ActionProgress, Description: "Apply Progress", Section: "TERRAFORM", Scope: ScopeGlobal, Keys: []string{"P"}},
+	{Name: ActionJobs, Description: "Finished Jobs", Section: "TERRAFORM", Scope: ScopeGlobal, Keys: []string{"O"}},
+
+	{Name: ActionMark, Description: "Mark Stack", Section: "STACKS", Scope: ScopeTree, Keys: []string{"Space"}},
+	{Name: ActionBatch, Description: "Batch Init/Plan/Validate", Section: "STACKS", Scope: ScopeGlobal, Header: "Batch", Keys: []string{"b"}},
+	{Name: ActionStacksOnly, Description: "Stacks Only", Section: "STACKS", Scope: ScopeGlobal, Keys: []string{"f"}},
+	{Name: ActionDashboard, Description: "Stack Dashboard", Section: "STACKS", Scope: ScopeGlobal, Header: "Stacks", Keys: []string{"g"}},
+	{Name: ActionGraph, Description: "Resource Graph", Section: "STACKS", Scope: ScopeGlobal, Header: "Graph", Keys: []string{"G"}},
+	{Name: ActionState, Description: "State Browser", Section: "STACKS", Scope: ScopeGlobal, Header: "State", Keys: []string{"t"}},
+	{Name: ActionOutputs, Description: "Outputs", Section: "STACKS", Scope: ScopeGlobal, Header: "Outputs", Keys: []string{"o"}},
+	{Name: ActionWorkspaces, Description: "Workspaces", Section: "STACKS", Scope: ScopeGlobal, Header: "Workspace", Keys: []string{"w"}},
+	{Name: ActionContext, Description: "Context Switch", Section: "STACKS", Scope: ScopeGlobal, Header: "Context", Keys: []string{"c"}},
+	{Name: ActionBranch, Description: "Branch Switch", Section: "STACKS", Scope: ScopeGlobal, Header: "Branch", Keys: []string{"B"}},
+
+	{Name: ActionEdit, Description: "Edit File", Section: "CONTENT", Scope: ScopeGlobal, Header: "Edit", Keys: []string{"e", "E"}},
+	{Name: ActionSearch, Description: "Search Content", Section: "CONTENT", Scope: ScopeContent, Keys: []string{"/"}},
+	{Name: ActionSearchNext, Description: "Next Match", Section: "CONTENT", Scope: ScopeContent, Keys: []string{"n"}},
+	{Name: ActionSearchPrev, Description: "Previous Match", Section: "CONTENT", Scope: ScopeContent, Keys: []string{"N"}},
+	{Name: ActionClearSearch, Description: "Clear Search", Section: "CONTENT", Scope: ScopeContent, Keys: []string{"Esc"}},
+	{Name: ActionDefinition, Description: "Go to Definition/Fold Block", Section: "CONTENT", Scope: ScopeContent, Keys: []string{"Enter"}},
+	{Name: ActionWrap, Description: "Cycle Wrap Mode", Section: "CONTENT", Scope: ScopeGlobal, Keys: []string{"W"}},
+	{Name: ActionFoldAll, Description: "Fold All", Section: "CONTENT", Scope: ScopeContent, Keys: []string{"z"}},
+	{Name: ActionFoldDestructive, Description: "Fold All but Destructive", Section: "CONTENT", Scope: ScopeContent, Keys: []string{"Z"}},
+	{Name: ActionPrevBlock, Description: "Previous Resource Block", Section: "CONTENT", Scope: ScopeContent, Keys: []string{"["}},
+	{Name: ActionNextBlock, Description: "Next Resource Block", Section: "CONTENT", Scope: ScopeContent, Keys: []string{"]"}},
+	{Name: ActionUnchanged, Description: "Unchanged Attributes", Section: "CONTENT", Scope: ScopeContent, Keys: []string{"~"}},
+	{Name: ActionScrollUp, Description: "Fast Scroll Up", Section: "CONTENT", Scope: ScopeContent, Keys: []string{"u", "U"}},
+	{Name: ActionScrollDown, Description: "Fast Scroll Down", Section: "CONTENT", Scope: ScopeContent, Keys: []string{"d"}},
+
+	{Name: ActionFocus, Description: "Switch Focus", Section: "GENERAL", Scope: ScopeGlobal, Keys: []string{"Tab"}},
+	{Name: ActionCommand, Description: "Command Mode", Section: "GENERAL", Scope: ScopeTree, Header: "Command", Keys: []string{"/"}},
+	{Name: ActionFinder, Description: "Find Stack/File", Section: "GENERAL", Scope: ScopeGlobal, Keys: []string{"Ctrl+P"}},
+	{Name: ActionHome, Description: "Home (Commands)", Section: "GENERAL", Scope: ScopeGlobal, Header: "Home", Keys: []string{"C"}},
+	{Name: ActionSettings, Description: "Settings", Section: "GENERAL", Scope: ScopeGlobal, Header: "Settings", Keys: []string{"s", "S"}},
+	{Name: ActionHelp, Description: "Help", Section: "GENERAL", Scope: ScopeGlobal, Header: "Help", Keys: []string{"?", "H"}},
+	{Name: ActionQuit, Description: "Quit", Section: "GENERAL", Scope: ScopeGlobal, Header: "Quit", Keys: []string{"q", "Q", "Ctrl+C"}},
+
+	{Name: ActionStateFilter, Description: "Filter", Section: "STATE VIEW", Scope: ScopeState, Keys: []string{"/"}},
+	{Name: ActionStateMove, Description: "Move", Section: "STATE VIEW", Scope: ScopeState, Keys: []string{"m"}},
+	{Name: ActionStateRemove, Description: "Remove", Section: "STATE VIEW", Scope: ScopeState, Keys: []string{"D"}},
+	{Name: ActionStateImport, Description: "Import", Section: "STATE VIEW", Scope: ScopeState, Keys: []string{"i"}},
+	{Name: ActionStateReplace, Description: "Replace", Section: "STATE VIEW", Scope: ScopeState, Keys: []string{"r"}},
+	{Name: ActionStateTarget, Description: "Target", Section: "STATE VIEW", Scope: ScopeState, Keys: []string{"T"}},
+
+	{Name: ActionStacksMark, Description: "Mark", Section: "STACK DASHBOARD", Scope: ScopeStacks, Keys: []string{"Space"}},
+	{Name: ActionStacksMarkDeps, Description: "Mark With Dependencies", Section: "STACK DASHBOARD", Scope: ScopeStacks, Keys: []string{"m"}},
+	{Name: ActionStacksPlan, Description: "Plan Marked", Section: "STACK DASHBOARD", Scope: ScopeStacks, Keys: []string{"p"}},
+	{Name: ActionStacksApply, Description: "Apply Marked", Section: "STACK DASHBOARD", Scope: ScopeStacks, Keys: []string{"a"}},
+	{Name: ActionStacksBatch, Description: "Batch", Section: "STACK DASHBOARD", Scope: ScopeStacks, Keys: []string{"b"}},
+
+	{Name: ActionOutputsReveal, Description: "Reveal/Hide", Section: "OUTPUTS VIEW", Scope: ScopeOutputs, Keys: []string{"v"}},
+	{Name: ActionOutputsRevealAll, Description: "Reveal/Hide All", Section: "OUTPUTS VIEW", Scope: ScopeOutputs, Keys: []string{"V"}},
+	{Name: ActionOutputsCopy, Description: "Copy Value", Section: "OUTPUTS VIEW", Scope: ScopeOutputs, Keys: []string{"y"}},
+
+	{Name: ActionGraphFilter, Description: "Filter", Section: "GRAPH VIEW", Scope: ScopeGraph, Keys: []string{"/"}},
+	{Name: ActionGraphExport, Description: "Export (.dot/.svg/.png)", Section: "GRAPH VIEW", Scope: ScopeGraph, Keys: []string{"x"}},
+
+	{Name: ActionHistoryDetails, Description: "Toggle Details", Section: "HISTORY VIEW", Scope: ScopeHistory, Keys: []string{"M"}},
+	{Name: ActionHistoryMore, Description: "Load More", Section: "HISTORY VIEW", Scope: ScopeHistory, Keys: []string{"d", "D"}},
+	{Name: ActionHistoryLess, Description: "Load Less", Section: "HISTORY VIEW", Scope: ScopeHistory, Keys: []string{"u", "U"}},
+
+	{Name: ActionDiagnosticsView, Description: "View in Content", Section: "DIAGNOSTICS", Scope: ScopeDiagnostics, Keys: []string{"v"}},
+}
+
+// viewKeys are the fixed keys of each sub-view, such as Esc to go back,
+// which its actions cannot be bound to
+var viewKeys = map[string][]string{
+	ScopeState:       {"Esc", "Tab", "Enter", "q"},
+	ScopeStacks:      {"Esc", "Tab", "q"},
+	ScopeOutputs:     {"Esc", "Tab", "q"},
+	ScopeGraph:       {"Esc", "Tab", "Enter", "q"},
+	ScopeHistory:     {"Esc"},
+	ScopeDiagnostics: {"Esc", "Tab", "Enter", "q"},
+}
+
+// namedKeys maps the lower-case names accepted in keys.yaml to their canonical form
+var namedKeys = map[string]string{
+	"space": "Space", "tab": "Tab", "backtab": "Backtab", "shift+tab": "Backtab",
+	"enter": "Enter", "return": "Enter", "esc": "Esc", "escape": "Esc",
+	"backspace": "Backspace", "delete": "Delete", "insert": "Insert",
+	"up": "Up", "down": "Down", "left": "Left", "right": "Right",
+	"home": "Home", "end": "End", "pgup": "PgUp", "pgdn": "PgDn",
+	"f1": "F1", "f2": "F2", "f3": "F3", "f4": "F4", "f5": "F5", "f6": "F6",
+	"f7": "F7", "f8": "F8", "f9": "F9", "f10": "F10", "f11": "F11", "f12": "F12",
+}
+
+// ctrlAliases are the Ctrl+letter keys that share their code with a named key
+var ctrlAliases = map[byte]string{'i': "Tab", 'm': "Enter", 'h': "Backspace"}
+
+// Keymap binds keys to the actions of the main screen and the sub-views
+type Keymap struct {
+	keys   map[string][]string          // keys of each action
+	lookup map[string]map[string]string // action of each key, per scope
+}
+
+// keyList is a key or a list of keys in keys.yaml
+type keyList []string
+
+// UnmarshalYAML accepts a single key as well as a list
+func (k *keyList) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*k = keyList{value.Value}
+		return nil
+	}
+	var keys []string
+	if err := value.Decode(&keys); err != nil {
+		return err
+	}
+	*k = keys
+	return nil
+}
+
+// DefaultKeymap returns the built-in key bindings
+func DefaultKeymap() *Keymap {
+	km := &Keymap{keys: make(map[string][]string)}
+	for _, action := range KeyActions {
+		km.keys[action.Name] = action.Keys
+	}
+	km.index()
+	return km
+}
+
+// LoadKeymap loads the key bindings of ~/.t9s/keys.yaml over the defaults. A missing file
+// means the defaults. When the file is invalid or has conflicting keys, the defaults are
+// returned with the error.
+func LoadKeymap() (*Keymap, error) {
+	path := getKeymapPath()
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return DefaultKeymap(), nil
+	}
+	if err != nil {
+		return DefaultKeymap(), fmt.Errorf("failed to read keymap: %w", err)
+	}
+
+	km, err := ParseKeymap(data)
+	if err != nil {
+		return DefaultKeymap(), fmt.Errorf("%s: %w", path, err)
+	}
+	return km, nil
+}
+
+// ParseKeymap parses key bindings in the format of keys.yaml, where each action maps to a
+// key or a list of keys, e.g. `destroy: Ctrl+D` or `help: ["?", F1]`. Actions that are not
+// listed keep their default keys; an empty list unbinds an action.
+func ParseKeymap(data []byte) (*Keymap, error) {
+	var bindings map[string]keyList
+	if err := yaml.Unmarshal(data, &bindings); err != nil {
+		return nil, fmt.Errorf("failed to parse keymap: %w", err)
+	}
+
+	km := DefaultKeymap()
+	var problems []string
+	for name, keys := range bindings {
+		if findKeyAction(name) == nil {
+			problems = append(problems, fmt.Sprintf("unknown action %q", name))
+			continue
+		}
+		normalized := make([]string, 0, len(keys))
+		for _, key := range keys {
+			canonical, err := NormalizeKey(key)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %s", name, err))
+				continue
+			}
+			normalized = append(normalized, canonical)
+		}
+		km.keys[name] = normalized
+	}
+	problems = append(problems, km.Conflicts()...)
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return nil, fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+
+	km.index()
+	return km, nil
+}
+
+// Conflicts describes each key bound to two actions that can apply at the same time,
+// and each sub-view action bound to a fixed key of its view. Actions of the tree and
+// the content view may share keys, unless one is destructive.
+func (km *Keymap) Conflicts() []string {
+	var conflicts []string
+	for i, a := range KeyActions {
+		for _, key := range km.keys[a.Name] {
+			if containsKey(viewKeys[a.Scope], key) {
+				conflicts = append(conflicts, fmt.Sprintf("%q is a fixed key of the %s view and cannot be bound to %s", key, a.Scope, a.Name))
+			}
+		}
+		for _, b := range KeyActions[i+1:] {
+			if !a.overlaps(b) {
+				continue
+			}
+			for _, key := range km.keys[a.Name] {
+				if containsKey(km.keys[b.Name], key) {
+					conflicts = append(conflicts, fmt.Sprintf("%q is bound to both %s and %s", key, a.Name, b.Name))
+				}
+			}
+		}
+	}
+	return conflicts
+}
+
+// Keys returns the keys bound to an action
+func (km *Keymap) Keys(action string) []string {
+	return km.keys[action]
+}
+
+// Lookup returns the action bound to a key for the focus scope, ScopeTree, ScopeContent or
+// the scope of a sub-view, or "" if there is none. Global actions do not apply in sub-views.
+func (km *Keymap) Lookup(key, scope string) string {
+	if action, ok := km.lookup[scope][key]; ok {
+		return action
+	}
+	if isViewScope(scope) {
+		return ""
+	}
+	return km.lookup[ScopeGlobal][key]
+}
+
+// Label formats the keys of an action for help texts, e.g. "<q>,<ctrl-c>". The upper-case
+// variant of a letter that is also bound in lower case is left out. It is "" for an
+// unbound action.
+func (km *Keymap) Label(action string) string {
+	keys := km.keys[action]
+	var labels []string
+	for _, key := range keys {
+		if lower := strings.ToLower(key); lower != key && utf8.RuneCountInString(key) == 1 && containsKey(keys, lower) {
+			continue
+		}
+		labels = append(labels, "<"+KeyLabel(key)+">")
+	}
+	return strings.Join(labels, ",")
+}
+
+// index builds the lookup tables
+func (km *Keymap) index() {
+	km.lookup = make(map[string]map[string]string)
+	for _, action := range KeyActions {
+		if km.lookup[action.Scope] == nil {
+			km.lookup[action.Scope] = make(map[string]string)
+		}
+		for _, key := range km.keys[action.Name] {
+			if _, ok := km.lookup[action.Scope][key]; !ok {
+				km.lookup[action.Scope][key] = action.Name
+			}
+		}
+	}
+}
+
+// overlaps reports whether two actions can be triggered with the same focus
+func (a KeyAction) overlaps(b KeyAction) bool {
+	if isViewScope(a.Scope) || isViewScope(b.Scope) {
+		return a.Scope == b.Scope
+	}
+	if a.Scope == ScopeGlobal || b.Scope == ScopeGlobal || a.Scope == b.Scope {
+		return true
+	}
+	// A key that destroys in one pane must not do anything in the other,
+	// so that pressing it with the wrong pane focused cannot go wrong
+	return a.Destructive || b.Destructive
+}
+
+// isViewScope reports whether a scope is the scope of a sub-view
+func isViewScope(scope string) bool {
+	return scope != ScopeGlobal && scope != ScopeTree && scope != ScopeContent
+}
+
+// NormalizeKey returns the canonical form of a key of keys.yaml: a single character such as
+// "p", "G" or "?", "Ctrl+X", or a named key such as "Enter", "Space", "Esc" or "F1".
+// "Shift+x" is the upper-case letter. Ctrl+I, Ctrl+M and Ctrl+H are rejected, since
+// terminals send them as Tab, Enter and Backspace.
+func NormalizeKey(key string) (string, error) {
+	if key == " " {
+		return "Space", nil
+	}
+	key = strings.TrimSpace(key)
+	if utf8.RuneCountInString(key) == 1 {
+		return key, nil
+	}
+
+	lower := strings.ToLower(key)
+	if named, ok := namedKeys[lower]; ok {
+		return named, nil
+	}
+	if strings.HasPrefix(lower, "ctrl+") || strings.HasPrefix(lower, "ctrl-") {
+		if letter := lower[5:]; len(letter) == 1 && letter[0] >= 'a' && letter[0] <= 'z' {
+			if named, ok := ctrlAliases[letter[0]]; ok {
+				return "", fmt.Errorf("invalid key %q: terminals send it as %s", key, named)
+			}
+			return "Ctrl+" + strings.ToUpper(letter), nil
+		}
+	}
+	if strings.HasPrefix(lower, "shift+") || strings.HasPrefix(lower, "shift-") {
+		if letter := lower[6:]; len(letter) == 1 && letter[0] >= 'a' && letter[0] <= 'z' {
+			return strings.ToUpper(letter), nil
+		}
+	}
+	return "", fmt.Errorf("invalid key %q", key)
+}
+
+// KeyLabel formats a canonical key for help texts, e.g. "p", "shift-g", "ctrl-p" or "enter"
+func KeyLabel(key string) string {
+	if utf8.RuneCountInString(key) == 1 {
+		if lower := strings.ToLower(key); lower != key {
+			return "shift-" + lower
+		}
+		return key
+	}
+	return strings.ToLower(strings.Replace(key, "+", "-", 1))
+}
+
+// findKeyAction returns the action with the given name, or nil
+func findKeyAction(name string) *KeyAction {
+	for i := range KeyActions {
+		if KeyActions[i].Name == name {
+			return &KeyActions[i]
+		}
+	}
+	return nil
+}
+
+// containsKey reports whether keys contains key
+func containsKey(keys []string, key string) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// getKeymapPath returns the path to the keymap file
+func getKeymapPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ".t9s/keys.yaml"
+	}
+	return filepath.Join(home, ".t9s", "keys.yaml")
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseKeymap(t *testing.T) {
+	type lookup struct {
+		key, scope, action string
+	}
+
+	tests := []struct {
+		name    string
+		yaml    string
+		err     string   // substring of the error, "" if the keymap is valid
+		lookups []lookup // expected actions of the parsed keymap
+	}{
+		{
+			name: "remapped action loses its default key",
+			yaml: "destroy: ctrl+d\nhelp: [\"?\", F1]",
+			lookups: []lookup{
+				{"Ctrl+D", ScopeTree, ActionDestroy},
+				{"D", ScopeTree, ""},
+				{"F1", ScopeContent, ActionHelp},
+				{"?", ScopeTree, ActionHelp},
+			},
+		},
+		{
+			name: "shift and space are normalized",
+			yaml: "graph: shift+k\nfold_all: \" \"",
+			lookups: []lookup{
+				{"K", ScopeTree, ActionGraph},
+				{"Space", ScopeContent, ActionFoldAll},
+				{"Space", ScopeTree, ActionMark},
+			},
+		},
+		{
+			name: "empty list unbinds",
+			yaml: "history: []",
+			lookups: []lookup{
+				{"h", ScopeTree, ""},
+			},
+		},
+		{
+			name: "same key in different views",
+			yaml: "state_move: v\nstacks_plan: x",
+			lookups: []lookup{
+				{"v", ScopeState, ActionStateMove},
+				{"v", ScopeOutputs, ActionOutputsReveal},
+				{"x", ScopeStacks, ActionStacksPlan},
+				{"x", ScopeTree, ActionDiagnostics},
+			},
+		},
+		{
+			name: "global keys do not apply in views",
+			yaml: "state_move: p",
+			lookups: []lookup{
+				{"p", ScopeState, ActionStateMove},
+				{"a", ScopeState, ""},
+				{"p", ScopeTree, ActionPlan},
+			},
+		},
+		{
+			name: "unknown action",
+			yaml: "deploy: x",
+			err:  `unknown action "deploy"`,
+		},
+		{
+			name: "invalid key",
+			yaml: "plan: ctrl+pp",
+			err:  `plan: invalid key "ctrl+pp"`,
+		},
+		{
+			name: "ctrl+i is tab",
+			yaml: "help: ctrl+i",
+			err:  "terminals send it as Tab",
+		},
+		{
+			name: "ctrl+m is enter",
+			yaml: "help: Ctrl+M",
+			err:  "terminals send it as Enter",
+		},
+		{
+			name: "ctrl+h is backspace",
+			yaml: "help: ctrl-h",
+			err:  "terminals send it as Backspace",
+		},
+		{
+			name: "two global actions",
+			yaml: "plan: r",
+			err:  `"r" is bound to both plan and refresh`,
+		},
+		{
+			name: "destructive action shares a content key",
+			yaml: "destroy: n",
+			err:  `"n" is bound to both destroy and search_next`,
+		},
+		{
+			name: "fixed key of a view",
+			yaml: "state_move: q",
+			err:  `"q" is a fixed key of the state view`,
+		},
+		{
+			name: "two actions of one view",
+			yaml: "outputs_copy: v",
+			err:  `"v" is bound to both outputs_reveal and outputs_copy`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			km, err := ParseKeymap([]byte(tt.yaml))
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("ParseKeymap() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseKeymap() error = %v", err)
+			}
+			for _, l := range tt.lookups {
+				if got := km.Lookup(l.key, l.scope); got != l.action {
+					t.Errorf("Lookup(%q, %q) = %q, want %q", l.key, l.scope, got, l.action)
+				}
+			}
+		})
+	}
+}
+
+func TestDefaultKeymapHasNoConflicts(t *testing.T) {
+	if conflicts := DefaultKeymap().Conflicts(); len(conflicts) > 0 {
+		t.Errorf("default keymap has conflicts: %v", conflicts)
+	}
+}
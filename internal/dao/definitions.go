@@ -0,0 +1,49 @@
+package dao
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/idongju/t9s/internal/model"
+	"github.com/idongju/t9s/internal/tfconfig"
+)
+
+// References lists the var., local. and module. references of a configuration file,
+// resolved to where the stack declares them. For a tfvars file these are the variables
+// it assigns; a tfvars file in a subdirectory such as config/ belongs to the parent stack.
+func (d *TerraformDAO) References(file string) ([]*model.SymbolReference, error) {
+	var refs []tfconfig.Reference
+	var err error
+	if strings.HasSuffix(file, ".tfvars") {
+		refs, err = tfconfig.Assignments(file)
+	} else {
+		refs, err = tfconfig.References(file)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	module, err := tfconfig.ParseDir(definingStack(file))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*model.SymbolReference, 0, len(refs))
+	for _, ref := range refs {
+		sr := &model.SymbolReference{Address: ref.Address, Line: ref.Line}
+		if def := module.Definition(ref.Address); def != nil {
+			sr.DefFile, sr.DefLine = def.File, def.Line
+		}
+		result = append(result, sr)
+	}
+	return result, nil
+}
+
+// definingStack returns the directory whose .tf files declare the symbols used by file
+func definingStack(file string) string {
+	dir := filepath.Dir(file)
+	if matches, _ := filepath.Glob(filepath.Join(dir, "*.tf")); len(matches) > 0 {
+		return dir
+	}
+	return filepath.Dir(dir)
+}
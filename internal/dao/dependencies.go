@@ -0,0 +1,104 @@
+package dao
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/idongju/t9s/internal/model"
+	"github.com/idongju/t9s/internal/tfconfig"
+	"gopkg.in/yaml.v3"
+)
+
+// StackConfigFile is the optional per-stack settings file
+const StackConfigFile = ".t9s.yaml"
+
+// stackConfig is the content of a stack's .t9s.yaml
+type stackConfig struct {
+	DependsOn []string `yaml:"depends_on"` // stack paths, relative to the stack or the root
+}
+
+// StackGraph infers the dependencies between all stacks under the root from
+// terraform_remote_state data sources and the depends_on lists of .t9s.yaml files
+func (d *TerraformDAO) StackGraph() (*model.StackGraph, error) {
+	stacks, err := d.FindStacks()
+	if err != nil {
+		return nil, err
+	}
+	graph := model.NewStackGraph(stacks)
+
+	keys := make(map[string][]string)
+	for _, stack := range stacks {
+		keys[stack] = d.StateKeys(stack)
+	}
+
+	for _, stack := range stacks {
+		if module, err := tfconfig.ParseDir(stack); err == nil {
+			for _, rs := range module.RemoteStates() {
+				for _, other := range stacks {
+					if other != stack && d.remoteStateMatches(stack, rs, other, keys[other]) {
+						graph.AddDependency(stack, model.StackDependency{
+							Stack:  other,
+							Source: "data.terraform_remote_state." + rs.Name,
+							File:   rs.File,
+							Line:   rs.Line,
+						})
+					}
+				}
+			}
+		}
+
+		cfg, err := readStackConfig(stack)
+		if err != nil {
+			graph.Warnings = append(graph.Warnings, err.Error())
+			continue
+		}
+		for _, dep := range cfg.DependsOn {
+			target := d.resolveStack(stack, dep, stacks)
+			if target == "" {
+				graph.Warnings = append(graph.Warnings,
+					fmt.Sprintf("%s: depends_on %q matches no stack", filepath.Join(stack, StackConfigFile), dep))
+				continue
+			}
+			graph.AddDependency(stack, model.StackDependency{
+				Stack:  target,
+				Source: StackConfigFile,
+				File:   filepath.Join(stack, StackConfigFile),
+			})
+		}
+	}
+	return graph, nil
+}
+
+// readStackConfig reads the .t9s.yaml of a stack, returning an empty config if there is none
+func readStackConfig(stack string) (*stackConfig, error) {
+	cfg := &stackConfig{}
+	data, err := os.ReadFile(filepath.Join(stack, StackConfigFile))
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read %s: %w", filepath.Join(stack, StackConfigFile), err)
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse %s: %w", filepath.Join(stack, StackConfigFile), err)
+	}
+	return cfg, nil
+}
+
+// resolveStack resolves a depends_on entry against the stack directory, then the root
+func (d *TerraformDAO) resolveStack(stack, dep string, stacks []string) string {
+	candidates := []string{dep}
+	if !filepath.IsAbs(dep) {
+		candidates = []string{filepath.Join(stack, dep), filepath.Join(d.RootPath, dep)}
+	}
+	for _, c := range candidates {
+		c = filepath.Clean(c)
+		for _, s := range stacks {
+			if s == c {
+				return s
+			}
+		}
+	}
+	return ""
+}
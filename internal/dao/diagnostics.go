@@ -0,0 +1,197 @@
+package dao
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/idongju/t9s/internal/model"
+)
+
+// diagnosticJSON is a diagnostic of `terraform validate -json` and of the -json event stream
+type diagnosticJSON struct {
+	Severity string `json:"severity"`
+	Summary  string `json:"summary"`
+	Detail   string `json:"detail"`
+	Address  string `json:"address"`
+	Range    *struct {
+		Filename string `json:"filename"`
+		Start    struct {
+			Line   int `json:"line"`
+			Column int `json:"column"`
+		} `json:"start"`
+	} `json:"range"`
+}
+
+var (
+	ansiPattern           = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+	diagnosticPattern     = regexp.MustCompile(`^(Error|Warning): (.+)$`)
+	diagnosticLocPattern  = regexp.MustCompile(`^\s+on (.+?) line (\d+)(?:, in .+)?:$`)
+	diagnosticWithPattern = regexp.MustCompile(`^\s+with (.+),$`)
+	diagnosticCodePattern = regexp.MustCompile(`^\s+(\d+:|├|└|│)`)
+)
+
+// Validate runs `terraform validate -json` in dir and returns its diagnostics
+func (d *TerraformDAO) Validate(dir string) ([]*model.Diagnostic, error) {
+	cmd := exec.Command("terraform", "validate", "-json", "-no-color")
+	cmd.Dir = dir
+
+	// validate exits non-zero for an invalid configuration but still prints the report
+	output, err := cmd.Output()
+	if len(output) == 0 && err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("terraform validate failed: %w: %s", err, string(exitErr.Stderr))
+		}
+		return nil, fmt.Errorf("terraform validate failed: %w", err)
+	}
+
+	var report struct {
+		Diagnostics []diagnosticJSON `json:"diagnostics"`
+	}
+	if err := json.Unmarshal(output, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse validate output: %w", err)
+	}
+	diags := make([]*model.Diagnostic, 0, len(report.Diagnostics))
+	for _, raw := range report.Diagnostics {
+		diags = append(diags, convertDiagnostic(raw, dir))
+	}
+	return diags, nil
+}
+
+// ParseDiagnostics extracts the errors and warnings of terraform output run in workDir.
+// It reads the diagnostic events of -json output and the human-readable
+// "Error: ... on main.tf line 42" blocks of regular output.
+func ParseDiagnostics(output, workDir string) []*model.Diagnostic {
+	var diags []*model.Diagnostic
+	var current *model.Diagnostic
+	var detail []string
+	boxed := false
+
+	finish := func() {
+		if current != nil {
+			current.Detail = strings.TrimSpace(strings.Join(detail, "\n"))
+			diags = append(diags, current)
+		}
+		current, detail = nil, nil
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := ansiPattern.ReplaceAllString(scanner.Text(), "")
+
+		// -json event stream
+		if strings.HasPrefix(line, "{") {
+			var event struct {
+				Type       string          `json:"type"`
+				Diagnostic *diagnosticJSON `json:"diagnostic"`
+			}
+			if json.Unmarshal([]byte(line), &event) == nil && event.Type == "diagnostic" && event.Diagnostic != nil {
+				finish()
+				diags = append(diags, convertDiagnostic(*event.Diagnostic, workDir))
+			}
+			continue
+		}
+
+		// Diagnostics are framed by ╷ and ╵, with │ in front of every line
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "╷":
+			finish()
+			boxed = true
+			continue
+		case trimmed == "╵":
+			finish()
+			boxed = false
+			continue
+		}
+		text := line
+		if strings.HasPrefix(trimmed, "│") {
+			text = strings.TrimPrefix(strings.TrimPrefix(trimmed, "│"), " ")
+		}
+
+		if m := diagnosticPattern.FindStringSubmatch(text); m != nil {
+			finish()
+			current = &model.Diagnostic{Severity: strings.ToLower(m[1]), Summary: strings.TrimSpace(m[2])}
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		if m := diagnosticWithPattern.FindStringSubmatch(text); m != nil && current.Address == "" && len(detail) == 0 {
+			// Resource the diagnostic is about, above its location
+			current.Address = m[1]
+			continue
+		}
+		if m := diagnosticLocPattern.FindStringSubmatch(text); m != nil && current.File == "" {
+			current.File = diagnosticPath(m[1], workDir)
+			current.Line, _ = strconv.Atoi(m[2])
+			continue
+		}
+		if current.File != "" && len(detail) == 0 && diagnosticCodePattern.MatchString(text) {
+			// Source snippet below the location
+			continue
+		}
+		if strings.TrimSpace(text) == "" {
+			if !boxed && len(detail) > 0 {
+				// Without a frame the detail ends at the first blank line
+				finish()
+				continue
+			}
+			if len(detail) > 0 {
+				detail = append(detail, "")
+			}
+			continue
+		}
+		detail = append(detail, text)
+	}
+	finish()
+	return diags
+}
+
+// MergeDiagnostics appends the diagnostics of extra that are not already in diags
+func MergeDiagnostics(diags, extra []*model.Diagnostic) []*model.Diagnostic {
+	key := func(d *model.Diagnostic) string {
+		return fmt.Sprintf("%s|%s|%s|%d", d.Severity, d.Summary, d.File, d.Line)
+	}
+	seen := make(map[string]bool, len(diags))
+	for _, d := range diags {
+		seen[key(d)] = true
+	}
+	for _, d := range extra {
+		if !seen[key(d)] {
+			seen[key(d)] = true
+			diags = append(diags, d)
+		}
+	}
+	return diags
+}
+
+// convertDiagnostic converts a JSON diagnostic, resolving its file against workDir
+func convertDiagnostic(raw diagnosticJSON, workDir string) *model.Diagnostic {
+	d := &model.Diagnostic{
+		Severity: raw.Severity,
+		Summary:  raw.Summary,
+		Detail:   raw.Detail,
+		Address:  raw.Address,
+	}
+	if raw.Range != nil && raw.Range.Filename != "" {
+		d.File = diagnosticPath(raw.Range.Filename, workDir)
+		d.Line = raw.Range.Start.Line
+		d.Column = raw.Range.Start.Column
+	}
+	return d
+}
+
+// diagnosticPath resolves a file name reported by terraform, which is relative to workDir
+func diagnosticPath(name, workDir string) string {
+	if filepath.IsAbs(name) {
+		return filepath.Clean(name)
+	}
+	return filepath.Join(workDir, name)
+}
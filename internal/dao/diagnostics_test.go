@@ -0,0 +1,189 @@
+package dao
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/idongju/t9s/internal/model"
+)
+
+func TestParseDiagnostics(t *testing.T) {
+	const workDir = "/infra/web"
+
+	tests := []struct {
+		name   string
+		output string
+		sample string // file under testdata read instead of output
+		want   []model.Diagnostic
+	}{
+		{
+			name: "framed error and warning",
+			output: `aws_s3_bucket.logs: Refreshing state... [id=acme-logs]
+╷
+│ Error: Unsupported argument
+│
+│   on main.tf line 12, in resource "aws_instance" "web":
+│   12:   amii = "ami-0c55b159cbfafe1f0"
+│
+│ An argument named "amii" is not expected here. Did you mean "ami"?
+╵
+╷
+│ Warning: Argument is deprecated
+│
+│   with module.s3.aws_s3_bucket.logs,
+│   on modules/s3/main.tf line 3, in resource "aws_s3_bucket" "logs":
+│    3:   acl    = "private"
+│
+│ Use the aws_s3_bucket_acl resource instead
+│
+│ (and one more similar warning elsewhere)
+╵
+`,
+			want: []model.Diagnostic{
+				{
+					Severity: "error",
+					Summary:  "Unsupported argument",
+					Detail:   `An argument named "amii" is not expected here. Did you mean "ami"?`,
+					File:     "/infra/web/main.tf",
+					Line:     12,
+				},
+				{
+					Severity: "warning",
+					Summary:  "Argument is deprecated",
+					Detail:   "Use the aws_s3_bucket_acl resource instead\n\n(and one more similar warning elsewhere)",
+					Address:  "module.s3.aws_s3_bucket.logs",
+					File:     "/infra/web/modules/s3/main.tf",
+					Line:     3,
+				},
+			},
+		},
+		{
+			name: "colored error with a multi-line snippet",
+			output: "\x1b[31m╷\x1b[0m\x1b[0m\n" +
+				"\x1b[31m│\x1b[0m \x1b[0m\x1b[1m\x1b[31mError: \x1b[0m\x1b[0m\x1b[1mInvalid function argument\x1b[0m\n" +
+				"\x1b[31m│\x1b[0m \x1b[0m\n" +
+				"\x1b[31m│\x1b[0m \x1b[0m\x1b[0m  on locals.tf line 4, in locals:\n" +
+				"\x1b[31m│\x1b[0m \x1b[0m   4:   config = jsondecode(\x1b[4mfile(\"config.json\")\x1b[0m)\n" +
+				"\x1b[31m│\x1b[0m \x1b[0m    \x1b[90m├────────────────\x1b[0m\n" +
+				"\x1b[31m│\x1b[0m \x1b[0m\x1b[0m    \x1b[90m│\x1b[0m \x1b[1mpath.module\x1b[0m is \"/infra/web\"\n" +
+				"\x1b[31m│\x1b[0m \x1b[0m\n" +
+				"\x1b[31m│\x1b[0m \x1b[0mInvalid value for \"path\" parameter: no file exists at \"config.json\".\n" +
+				"\x1b[31m╵\x1b[0m\x1b[0m\n",
+			want: []model.Diagnostic{
+				{
+					Severity: "error",
+					Summary:  "Invalid function argument",
+					Detail:   `Invalid value for "path" parameter: no file exists at "config.json".`,
+					File:     "/infra/web/locals.tf",
+					Line:     4,
+				},
+			},
+		},
+		{
+			name: "unframed error ends at the first blank line",
+			output: `Error: Invalid reference
+
+  on outputs.tf line 2, in output "ip":
+   2:   value = aws_instance.webb.public_ip
+
+A reference to a resource type must be followed by at least one attribute
+access, specifying the resource name.
+
+Releasing state lock. This may take a few moments...
+`,
+			want: []model.Diagnostic{
+				{
+					Severity: "error",
+					Summary:  "Invalid reference",
+					Detail:   "A reference to a resource type must be followed by at least one attribute\naccess, specifying the resource name.",
+					File:     "/infra/web/outputs.tf",
+					Line:     2,
+				},
+			},
+		},
+		{
+			name: "error without a source location",
+			output: `╷
+│ Error: No valid credential sources found
+│
+│ Please see https://registry.terraform.io/providers/hashicorp/aws
+│ for more information about providing credentials.
+╵
+`,
+			want: []model.Diagnostic{
+				{
+					Severity: "error",
+					Summary:  "No valid credential sources found",
+					Detail:   "Please see https://registry.terraform.io/providers/hashicorp/aws\nfor more information about providing credentials.",
+				},
+			},
+		},
+		{
+			name:   "json event stream",
+			sample: "apply.jsonl",
+			want: []model.Diagnostic{
+				{
+					Severity: "error",
+					Summary:  "creating EC2 Instance: InvalidAMIID.NotFound: The image id '[ami-0a8b4cd432b1c3063]' does not exist",
+					Address:  "aws_instance.web",
+					File:     "/infra/web/main.tf",
+					Line:     12,
+					Column:   1,
+				},
+			},
+		},
+		{
+			name: "json diagnostic with an absolute file",
+			output: `{"@level":"warning","@message":"Warning: Deprecated attribute","diagnostic":{"severity":"warning","summary":"Deprecated attribute","detail":"The attribute \"name\" is deprecated.","range":{"filename":"/infra/modules/vpc/main.tf","start":{"line":7,"column":10,"byte":100},"end":{"line":7,"column":20,"byte":110}}},"type":"diagnostic"}
+{"@level":"info","@message":"Apply complete! Resources: 0 added, 0 changed, 0 destroyed.","changes":{"add":0,"change":0,"import":0,"remove":0,"operation":"apply"},"type":"change_summary"}`,
+			want: []model.Diagnostic{
+				{
+					Severity: "warning",
+					Summary:  "Deprecated attribute",
+					Detail:   `The attribute "name" is deprecated.`,
+					File:     "/infra/modules/vpc/main.tf",
+					Line:     7,
+					Column:   10,
+				},
+			},
+		},
+		{
+			name:   "clean output",
+			sample: "apply_success.jsonl",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := tt.output
+			if tt.sample != "" {
+				data, err := os.ReadFile(filepath.Join("testdata", tt.sample))
+				if err != nil {
+					t.Fatal(err)
+				}
+				output = string(data)
+			}
+
+			var got []model.Diagnostic
+			for _, d := range ParseDiagnostics(output, workDir) {
+				got = append(got, *d)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseDiagnostics() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeDiagnostics(t *testing.T) {
+	a := &model.Diagnostic{Severity: "error", Summary: "Invalid reference", File: "/w/main.tf", Line: 3}
+	b := &model.Diagnostic{Severity: "error", Summary: "Invalid reference", File: "/w/main.tf", Line: 3, Detail: "from validate"}
+	c := &model.Diagnostic{Severity: "warning", Summary: "Deprecated", File: "/w/main.tf", Line: 3}
+
+	got := MergeDiagnostics([]*model.Diagnostic{a}, []*model.Diagnostic{b, c, c})
+	if len(got) != 2 || got[0] != a || got[1] != c {
+		t.Errorf("MergeDiagnostics() = %+v, want the first diagnostic and the warning", got)
+	}
+}
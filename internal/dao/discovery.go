@@ -0,0 +1,309 @@
+package dao
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/idongju/t9s/internal/config"
+	"github.com/idongju/t9s/internal/model"
+	"github.com/idongju/t9s/internal/tfconfig"
+)
+
+// DiscoveryOptions controls how terraform directories are found below the root
+type DiscoveryOptions struct {
+	MaxDepth  int      // directory levels below the root, 0 for the root only
+	Ignore    []string // globs relative to the root; "**" matches any number of directories
+	IndexPath string   // file caching the index between scans, "" to disable caching
+}
+
+// defaultDiscovery is used by DAOs created without WithDiscovery
+var defaultDiscovery = DiscoveryOptions{MaxDepth: config.DefaultMaxDepth}
+
+// ConfigDiscovery returns the discovery options set in the t9s config
+func ConfigDiscovery(cfg *config.Config) DiscoveryOptions {
+	return DiscoveryOptions{
+		MaxDepth:  cfg.Discovery.Depth(),
+		Ignore:    cfg.Discovery.Ignore,
+		IndexPath: cfg.IndexPath(),
+	}
+}
+
+// WithDiscovery sets the discovery options of the DAO
+func (d *TerraformDAO) WithDiscovery(opts DiscoveryOptions) *TerraformDAO {
+	d.discovery = opts
+	return d
+}
+
+// Discover walks the root up to the maximum depth and classifies every directory with .tf
+// files as a stack or a module. Directories whose files did not change since the cached
+// index are not parsed again.
+func (d *TerraformDAO) Discover() (*model.StackIndex, error) {
+	opts := d.discovery
+	cached := d.loadIndex()
+
+	index := &model.StackIndex{
+		Root:      d.RootPath,
+		MaxDepth:  opts.MaxDepth,
+		Ignore:    opts.Ignore,
+		Generated: time.Now(),
+		Entries:   make(map[string]*model.IndexEntry),
+	}
+
+	err := d.walkDirs(func(dir string, entries []os.DirEntry) {
+		if sig := signature(dir, entries); sig != "" {
+			if prev, ok := cached[dir]; ok && prev.Signature == sig {
+				index.Entries[dir] = prev
+			} else {
+				index.Entries[dir] = d.inspect(dir, entries, sig)
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// A backend, tfvars or state make a stack; a directory used as a local module
+	// source is a module, unless it has a backend of its own
+	used := make(map[string]bool)
+	for _, e := range index.Entries {
+		for _, ref := range e.ModuleRefs {
+			used[ref] = true
+		}
+	}
+	for path, e := range index.Entries {
+		e.Kind = model.KindModule
+		if e.Backend != "" || (!used[path] && (len(e.Tfvars) > 0 || e.HasState)) {
+			e.Kind = model.KindStack
+		}
+	}
+
+	d.saveIndex(index)
+	return index, nil
+}
+
+// TerraformFiles returns the .tf and .tfvars files of the directories visited by Discover
+func (d *TerraformDAO) TerraformFiles() ([]string, error) {
+	var files []string
+	err := d.walkDirs(func(dir string, entries []os.DirEntry) {
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.IsDir() || !(strings.HasSuffix(name, ".tf") || strings.HasSuffix(name, ".tfvars")) {
+				continue
+			}
+			files = append(files, filepath.Join(dir, name))
+		}
+	})
+	return files, err
+}
+
+// walkDirs calls fn for the root and every directory below it up to the maximum depth,
+// skipping hidden and ignored directories
+func (d *TerraformDAO) walkDirs(fn func(dir string, entries []os.DirEntry)) error {
+	var walk func(dir string, depth int) error
+	walk = func(dir string, depth int) error {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if dir == d.RootPath {
+				return fmt.Errorf("failed to read root directory: %w", err)
+			}
+			return nil
+		}
+		fn(dir, entries)
+
+		if depth >= d.discovery.MaxDepth {
+			return nil
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+			child := filepath.Join(dir, entry.Name())
+			if d.ignored(child) {
+				continue
+			}
+			if err := walk(child, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return walk(d.RootPath, 0)
+}
+
+// inspect parses a terraform directory for the signals used to classify it
+func (d *TerraformDAO) inspect(dir string, entries []os.DirEntry, sig string) *model.IndexEntry {
+	e := &model.IndexEntry{
+		Path:      dir,
+		Signature: sig,
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".tfvars") {
+			e.Tfvars = append(e.Tfvars, entry.Name())
+		}
+	}
+	for _, name := range d.findTfvarsFiles(filepath.Join(dir, "config")) {
+		e.Tfvars = append(e.Tfvars, filepath.Join("config", name))
+	}
+
+	if module, err := tfconfig.ParseDir(dir); err == nil {
+		e.Backend, _ = module.Backend()
+		for _, b := range module.Find("module") {
+			source := b.Attr("source")
+			if strings.HasPrefix(source, "./") || strings.HasPrefix(source, "../") {
+				e.ModuleRefs = append(e.ModuleRefs, filepath.Clean(filepath.Join(dir, source)))
+			}
+		}
+	}
+
+	e.HasState = exists(filepath.Join(dir, ".terraform")) || exists(filepath.Join(dir, "terraform.tfstate"))
+	return e
+}
+
+// signature summarizes the terraform files of a directory, "" if it has no .tf files
+func signature(dir string, entries []os.DirEntry) string {
+	var parts []string
+	hasTF := false
+	add := func(name string, info os.FileInfo) {
+		parts = append(parts, fmt.Sprintf("%s:%d:%d", name, info.Size(), info.ModTime().UnixNano()))
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() {
+			// Presence of these changes the classification
+			if name == ".terraform" || name == "config" {
+				if info, err := entry.Info(); err == nil {
+					add(name+"/", info)
+				}
+			}
+			continue
+		}
+		if strings.HasSuffix(name, ".tf") {
+			hasTF = true
+		}
+		if strings.HasSuffix(name, ".tf") || strings.HasSuffix(name, ".tfvars") || name == "terraform.tfstate" {
+			if info, err := entry.Info(); err == nil {
+				add(name, info)
+			}
+		}
+	}
+	if !hasTF {
+		return ""
+	}
+
+	if configEntries, err := os.ReadDir(filepath.Join(dir, "config")); err == nil {
+		for _, entry := range configEntries {
+			if info, err := entry.Info(); err == nil && !entry.IsDir() {
+				add("config/"+entry.Name(), info)
+			}
+		}
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, "|")
+}
+
+// ignored reports whether a directory matches one of the ignore globs
+func (d *TerraformDAO) ignored(dir string) bool {
+	rel, err := filepath.Rel(d.RootPath, dir)
+	if err != nil {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+	for _, pattern := range d.discovery.Ignore {
+		pattern = strings.Trim(filepath.ToSlash(pattern), "/")
+		if pattern == "" {
+			continue
+		}
+		// Patterns without a slash match a directory name at any depth, like .gitignore
+		if !strings.Contains(pattern, "/") {
+			if ok, _ := path.Match(pattern, path.Base(rel)); ok {
+				return true
+			}
+			continue
+		}
+		if matchGlob(strings.Split(pattern, "/"), strings.Split(rel, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob matches path segments against pattern segments where "**" matches zero or more segments.
+// A pattern also matches everything below a matched directory.
+func matchGlob(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return true
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchGlob(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+		return false
+	}
+	return matchGlob(pattern[1:], segments[1:])
+}
+
+// loadIndex reads the cached index entries, nil if there is no usable cache
+func (d *TerraformDAO) loadIndex() map[string]*model.IndexEntry {
+	if d.discovery.IndexPath == "" {
+		return nil
+	}
+	data, err := os.ReadFile(d.discovery.IndexPath)
+	if err != nil {
+		return nil
+	}
+	var index model.StackIndex
+	if err := json.Unmarshal(data, &index); err != nil || index.Root != d.RootPath {
+		return nil
+	}
+	return index.Entries
+}
+
+// saveIndex writes the index cache; failures only cost a full scan next time
+func (d *TerraformDAO) saveIndex(index *model.StackIndex) {
+	if d.discovery.IndexPath == "" {
+		return
+	}
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(d.discovery.IndexPath), 0755); err != nil {
+		return
+	}
+	// Write to a temporary file first so concurrent scans never read a partial index
+	tmp, err := os.CreateTemp(filepath.Dir(d.discovery.IndexPath), "index-*.json")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	tmp.Close()
+	if err != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+	if err := os.Rename(tmp.Name(), d.discovery.IndexPath); err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+// exists reports whether a path exists
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
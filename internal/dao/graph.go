@@ -0,0 +1,108 @@
+package dao
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/idongju/t9s/internal/model"
+)
+
+var (
+	// "a" -> "b" with optional attributes
+	dotEdgePattern = regexp.MustCompile(`^\s*"((?:[^"\\]|\\.)*)"\s*->\s*"((?:[^"\\]|\\.)*)"`)
+	// "a" [label = "..."]
+	dotNodePattern = regexp.MustCompile(`^\s*"((?:[^"\\]|\\.)*)"\s*(\[.*\])?\s*;?\s*$`)
+)
+
+// GetGraph runs `terraform graph` in a directory and parses its DOT output
+func (d *TerraformDAO) GetGraph(path string) (*model.ResourceGraph, error) {
+	cmd := exec.Command("terraform", "graph")
+	cmd.Dir = path
+
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("terraform graph failed: %w: %s", err, string(exitErr.Stderr))
+		}
+		return nil, fmt.Errorf("terraform graph failed: %w", err)
+	}
+	return ParseDot(string(output)), nil
+}
+
+// ParseDot parses the DOT output of `terraform graph`. Node names are normalized to
+// addresses ("[root] aws_vpc.main (expand)" becomes "aws_vpc.main") and graph
+// internals such as the root, meta and close nodes are dropped.
+func ParseDot(dot string) *model.ResourceGraph {
+	var nodes []string
+	var edges [][2]string
+
+	for _, line := range strings.Split(dot, "\n") {
+		if m := dotEdgePattern.FindStringSubmatch(line); m != nil {
+			from, to := normalizeDotNode(m[1]), normalizeDotNode(m[2])
+			if from != "" && to != "" {
+				edges = append(edges, [2]string{from, to})
+			}
+			continue
+		}
+		if m := dotNodePattern.FindStringSubmatch(line); m != nil {
+			if n := normalizeDotNode(m[1]); n != "" {
+				nodes = append(nodes, n)
+			}
+		}
+	}
+	return model.NewResourceGraph(nodes, edges, dot)
+}
+
+// normalizeDotNode turns a graph node name into an address, "" for internal nodes
+func normalizeDotNode(name string) string {
+	name = strings.ReplaceAll(name, `\"`, `"`)
+	name = strings.TrimPrefix(name, "[root] ")
+	// Close nodes run after everything they wrap, so their edges point the wrong way
+	if strings.HasSuffix(name, " (close)") {
+		return ""
+	}
+	for _, suffix := range []string{" (expand)", " (prepare state)", " (orphan)"} {
+		name = strings.TrimSuffix(name, suffix)
+	}
+	name = strings.TrimSpace(name)
+
+	switch {
+	case name == "", name == "root":
+		return ""
+	case strings.HasPrefix(name, "meta."):
+		return ""
+	}
+	return name
+}
+
+// ExportGraph writes a graph to a file: the DOT text as is for .dot files,
+// or rendered by Graphviz `dot` for .svg and .png files
+func ExportGraph(graph *model.ResourceGraph, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	switch format {
+	case "dot", "gv":
+		if err := os.WriteFile(path, []byte(graph.Dot), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		return nil
+	case "svg", "png":
+		if _, err := exec.LookPath("dot"); err != nil {
+			return fmt.Errorf("exporting %s needs Graphviz (dot) in PATH", format)
+		}
+		cmd := exec.Command("dot", "-T"+format, "-o", path)
+		cmd.Stdin = strings.NewReader(graph.Dot)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("dot failed: %w: %s", err, strings.TrimSpace(string(output)))
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported export format %q, use .dot, .svg or .png", filepath.Ext(path))
+}
@@ -0,0 +1,94 @@
+package dao
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDot(t *testing.T) {
+	tests := []struct {
+		name  string
+		dot   string
+		nodes []string
+		deps  map[string][]string
+	}{
+		{
+			name: "terraform 1.7 graph",
+			dot: `digraph G {
+  rankdir = "RL";
+  node [shape = rect, fontname = "sans-serif"];
+  "aws_instance.web" [label="aws_instance.web"];
+  "aws_subnet.public" [label="aws_subnet.public"];
+  "aws_vpc.main" [label="aws_vpc.main"];
+  "aws_instance.web" -> "aws_subnet.public";
+  "aws_subnet.public" -> "aws_vpc.main";
+}
+`,
+			nodes: []string{"aws_instance.web", "aws_subnet.public", "aws_vpc.main"},
+			deps: map[string][]string{
+				"aws_instance.web":  {"aws_subnet.public"},
+				"aws_subnet.public": {"aws_vpc.main"},
+			},
+		},
+		{
+			name: "legacy graph with root, meta and close nodes",
+			dot: `digraph {
+	compound = "true"
+	newrank = "true"
+	subgraph "root" {
+		"[root] aws_vpc.main (expand)" [label = "aws_vpc.main", shape = "box"]
+		"[root] module.db.aws_db_instance.this (expand)" [label = "module.db.aws_db_instance.this", shape = "box"]
+		"[root] provider[\"registry.terraform.io/hashicorp/aws\"]" [label = "provider[\"registry.terraform.io/hashicorp/aws\"]", shape = "diamond"]
+		"[root] aws_vpc.main (expand)" -> "[root] provider[\"registry.terraform.io/hashicorp/aws\"]"
+		"[root] module.db.aws_db_instance.this (expand)" -> "[root] aws_vpc.main (expand)"
+		"[root] meta.count-boundary (EachMode fixup)" -> "[root] module.db.aws_db_instance.this (expand)"
+		"[root] provider[\"registry.terraform.io/hashicorp/aws\"] (close)" -> "[root] aws_vpc.main (expand)"
+		"[root] root" -> "[root] meta.count-boundary (EachMode fixup)"
+	}
+}
+`,
+			nodes: []string{
+				"aws_vpc.main",
+				"module.db.aws_db_instance.this",
+				`provider["registry.terraform.io/hashicorp/aws"]`,
+			},
+			deps: map[string][]string{
+				"aws_vpc.main":                   {`provider["registry.terraform.io/hashicorp/aws"]`},
+				"module.db.aws_db_instance.this": {"aws_vpc.main"},
+			},
+		},
+		{
+			name: "duplicate and self edges",
+			dot: `digraph {
+	"[root] aws_a.x (expand)" -> "[root] aws_b.y"
+	"[root] aws_a.x" -> "[root] aws_b.y (expand)"
+	"[root] aws_a.x (expand)" -> "[root] aws_a.x"
+}
+`,
+			nodes: []string{"aws_a.x", "aws_b.y"},
+			deps: map[string][]string{
+				"aws_a.x": {"aws_b.y"},
+			},
+		},
+		{
+			name: "empty graph",
+			dot:  "digraph {\n}\n",
+			deps: map[string][]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ParseDot(tt.dot)
+			if !reflect.DeepEqual(g.Nodes, tt.nodes) {
+				t.Errorf("Nodes = %q, want %q", g.Nodes, tt.nodes)
+			}
+			if !reflect.DeepEqual(g.Deps, tt.deps) {
+				t.Errorf("Deps = %q, want %q", g.Deps, tt.deps)
+			}
+			if g.Dot != tt.dot {
+				t.Errorf("Dot is not the original text")
+			}
+		})
+	}
+}
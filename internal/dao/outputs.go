@@ -0,0 +1,176 @@
+package dao
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/idongju/t9s/internal/model"
+	"github.com/idongju/t9s/internal/tfconfig"
+)
+
+// GetOutputs reads the outputs of a directory with `terraform output -json`.
+// Sensitive values are returned unmasked; callers decide when to show them.
+func (d *TerraformDAO) GetOutputs(path string) ([]*model.StateOutput, error) {
+	cmd := exec.Command("terraform", "output", "-json")
+	cmd.Dir = path
+
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("terraform output failed: %w: %s", err, string(exitErr.Stderr))
+		}
+		return nil, fmt.Errorf("terraform output failed: %w", err)
+	}
+
+	var raw map[string]struct {
+		Sensitive bool        `json:"sensitive"`
+		Type      interface{} `json:"type"`
+		Value     interface{} `json:"value"`
+	}
+	if err := json.Unmarshal(output, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse outputs: %w", err)
+	}
+
+	var outputs []*model.StateOutput
+	for name, out := range raw {
+		outputs = append(outputs, &model.StateOutput{
+			Name:      name,
+			Type:      out.Type,
+			Value:     out.Value,
+			Sensitive: out.Sensitive,
+		})
+	}
+	sort.Slice(outputs, func(i, j int) bool { return outputs[i].Name < outputs[j].Name })
+	return outputs, nil
+}
+
+// FindStacks returns the stacks discovered under the root, sorted
+func (d *TerraformDAO) FindStacks() ([]string, error) {
+	index, err := d.Discover()
+	if err != nil {
+		return nil, err
+	}
+	stacks := index.Stacks()
+	sort.Strings(stacks)
+	return stacks, nil
+}
+
+// OutputConsumers finds the stacks that read outputs of stackDir through terraform_remote_state
+func (d *TerraformDAO) OutputConsumers(stackDir string) ([]*model.OutputConsumer, error) {
+	stacks, err := d.FindStacks()
+	if err != nil {
+		return nil, err
+	}
+	keys := d.StateKeys(stackDir)
+
+	var consumers []*model.OutputConsumer
+	for _, dir := range stacks {
+		if dir == stackDir {
+			continue
+		}
+		module, err := tfconfig.ParseDir(dir)
+		if err != nil {
+			continue
+		}
+
+		// Remote states of this stack that point at stackDir
+		pointing := make(map[string]bool)
+		for _, rs := range module.RemoteStates() {
+			if d.remoteStateMatches(dir, rs, stackDir, keys) {
+				pointing[rs.Name] = true
+			}
+		}
+		if len(pointing) == 0 {
+			continue
+		}
+
+		for _, ref := range module.OutputRefs() {
+			if pointing[ref.RemoteState] {
+				consumers = append(consumers, &model.OutputConsumer{
+					Stack:  dir,
+					Output: ref.Output,
+					File:   ref.File,
+					Line:   ref.Line,
+				})
+			}
+		}
+	}
+	return consumers, nil
+}
+
+// StateKeys returns the backend state keys a stack may be stored under:
+// the key of its backend block and the keys of its backend config files
+func (d *TerraformDAO) StateKeys(stackDir string) []string {
+	var keys []string
+	if module, err := tfconfig.ParseDir(stackDir); err == nil {
+		if _, config := module.Backend(); config["key"] != "" {
+			keys = append(keys, config["key"])
+		}
+	}
+
+	confs, _ := filepath.Glob(filepath.Join(stackDir, "config", "*.conf"))
+	for _, conf := range confs {
+		if key := readConfKey(conf); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// remoteStateMatches reports whether a remote state of consumerDir reads the state of stackDir
+func (d *TerraformDAO) remoteStateMatches(consumerDir string, rs tfconfig.RemoteState, stackDir string, keys []string) bool {
+	if rs.Backend == "local" {
+		path := rs.Config["path"]
+		if path == "" {
+			return false
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(consumerDir, path)
+		}
+		return filepath.Clean(path) == filepath.Join(stackDir, "terraform.tfstate")
+	}
+
+	key := rs.Config["key"]
+	if key == "" {
+		return false
+	}
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	// Without a known key, fall back to keys that embed the stack path
+	if len(keys) > 0 {
+		return false
+	}
+	rel, err := filepath.Rel(d.RootPath, stackDir)
+	if err != nil || rel == "." {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+	return strings.HasPrefix(key, rel+"/") || strings.Contains(key, "/"+rel+"/")
+}
+
+// readConfKey reads the key setting of a backend config file (key = "...")
+func readConfKey(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		name, value, ok := strings.Cut(scanner.Text(), "=")
+		if ok && strings.TrimSpace(name) == "key" {
+			return strings.Trim(strings.TrimSpace(value), `"`)
+		}
+	}
+	return ""
+}
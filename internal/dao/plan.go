@@ -0,0 +1,200 @@
+package dao
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/idongju/t9s/internal/model"
+)
+
+// planJSON is the subset of `terraform show -json <planfile>` used by t9s
+type planJSON struct {
+	ResourceChanges []struct {
+		Address       string `json:"address"`
+		ModuleAddress string `json:"module_address"`
+		Mode          string `json:"mode"`
+		Type          string `json:"type"`
+		Name          string `json:"name"`
+		Change        struct {
+			Actions      []string               `json:"actions"`
+			Before       map[string]interface{} `json:"before"`
+			After        map[string]interface{} `json:"after"`
+			AfterUnknown map[string]interface{} `json:"after_unknown"`
+		} `json:"change"`
+	} `json:"resource_changes"`
+}
+
+// ParsePlan parses the output of `terraform show -json` for a saved plan
+func ParsePlan(data []byte) (*model.Plan, error) {
+	var raw planJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse plan: %w", err)
+	}
+
+	plan := &model.Plan{}
+	for _, rc := range raw.ResourceChanges {
+		plan.ResourceChanges = append(plan.ResourceChanges, &model.ResourceChange{
+			Address:      rc.Address,
+			ModuleAddr:   rc.ModuleAddress,
+			Mode:         rc.Mode,
+			Type:         rc.Type,
+			Name:         rc.Name,
+			Actions:      rc.Change.Actions,
+			Before:       rc.Change.Before,
+			After:        rc.Change.After,
+			AfterUnknown: rc.Change.AfterUnknown,
+		})
+	}
+	return plan, nil
+}
+
+// GetPlan parses the newest saved plan in dir, a file named tfplan or *.tfplan.
+// It returns nil without an error when dir has no saved plan.
+func (d *TerraformDAO) GetPlan(dir string) (*model.Plan, error) {
+	file := latestPlanFile(dir)
+	if file == "" {
+		return nil, nil
+	}
+
+	cmd := exec.Command("terraform", "show", "-json", filepath.Base(file))
+	cmd.Dir = dir
+
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("terraform show %s failed: %w: %s", filepath.Base(file), err, string(exitErr.Stderr))
+		}
+		return nil, fmt.Errorf("terraform show %s failed: %w", filepath.Base(file), err)
+	}
+
+	return ParsePlan(output)
+}
+
+// latestPlanFile returns the most recently modified saved plan in dir, or "" if there is none
+func latestPlanFile(dir string) string {
+	matches, _ := filepath.Glob(filepath.Join(dir, "*.tfplan"))
+	matches = append(matches, filepath.Join(dir, "tfplan"))
+
+	latest := ""
+	var latestTime int64
+	for _, path := range matches {
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		if mod := info.ModTime().UnixNano(); latest == "" || mod > latestTime {
+			latest, latestTime = path, mod
+		}
+	}
+	return latest
+}
+
+// SuggestMoves pairs resources the plan deletes with identical resources it creates
+// at another address. Each pair becomes a `moved` block; ambiguous matches are skipped.
+func SuggestMoves(plan *model.Plan) []model.MovedBlock {
+	var deleted, created []*model.ResourceChange
+	for _, rc := range plan.ResourceChanges {
+		if rc.Mode != "managed" {
+			continue
+		}
+		if rc.IsDelete() {
+			deleted = append(deleted, rc)
+		} else if rc.IsCreate() {
+			created = append(created, rc)
+		}
+	}
+
+	var moves []model.MovedBlock
+	used := make(map[string]bool)
+	for _, from := range deleted {
+		var match *model.ResourceChange
+		ambiguous := false
+		for _, to := range created {
+			if used[to.Address] || to.Type != from.Type || !sameAttributes(from, to) {
+				continue
+			}
+			if match != nil {
+				ambiguous = true
+				break
+			}
+			match = to
+		}
+		if match == nil || ambiguous {
+			continue
+		}
+		used[match.Address] = true
+		moves = append(moves, model.MovedBlock{From: from.Address, To: match.Address})
+	}
+	return moves
+}
+
+// sameAttributes reports whether every known attribute of the created resource
+// equals the attribute of the deleted one
+func sameAttributes(from, to *model.ResourceChange) bool {
+	compared := 0
+	for key, after := range to.After {
+		if unknown, _ := to.AfterUnknown[key].(bool); unknown || after == nil {
+			continue
+		}
+		if !reflect.DeepEqual(from.Before[key], after) {
+			return false
+		}
+		compared++
+	}
+	return compared > 0
+}
+
+// WriteMovedBlocks appends moved blocks to moved.tf in dir, skipping blocks already present.
+// It returns the file path and the number of blocks written.
+func WriteMovedBlocks(dir string, moves []model.MovedBlock) (string, int, error) {
+	path := filepath.Join(dir, "moved.tf")
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return path, 0, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var b strings.Builder
+	written := 0
+	for _, move := range moves {
+		block := fmt.Sprintf("moved {\n  from = %s\n  to   = %s\n}\n", move.From, move.To)
+		if strings.Contains(string(existing), block) {
+			continue
+		}
+		b.WriteString("\n" + block)
+		written++
+	}
+	if written == 0 {
+		return path, 0, nil
+	}
+
+	return path, written, appendFile(path, b.String())
+}
+
+// WriteImportBlock appends an import block for address and id to imports.tf in dir
+func WriteImportBlock(dir, address, id string) (string, error) {
+	path := filepath.Join(dir, "imports.tf")
+	quoted, _ := json.Marshal(id)
+	// Keep template sequences in the ID literal
+	literal := strings.NewReplacer("${", "$${", "%{", "%%{").Replace(string(quoted))
+	block := fmt.Sprintf("\nimport {\n  to = %s\n  id = %s\n}\n", address, literal)
+	return path, appendFile(path, block)
+}
+
+// appendFile appends text to a file, creating it if needed
+func appendFile(path, text string) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(text); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package dao
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/idongju/t9s/internal/model"
+)
+
+func TestSuggestMoves(t *testing.T) {
+	bucket := func(address string, actions []string, attrs map[string]interface{}) *model.ResourceChange {
+		rc := &model.ResourceChange{Address: address, Mode: "managed", Type: "aws_s3_bucket", Actions: actions}
+		if actions[0] == "delete" {
+			rc.Before = attrs
+		} else {
+			rc.After = attrs
+		}
+		return rc
+	}
+	deleted := func(address, name string) *model.ResourceChange {
+		return bucket(address, []string{"delete"}, map[string]interface{}{"bucket": name, "force_destroy": false})
+	}
+	created := func(address, name string) *model.ResourceChange {
+		rc := bucket(address, []string{"create"}, map[string]interface{}{"bucket": name, "force_destroy": false, "arn": nil})
+		rc.AfterUnknown = map[string]interface{}{"arn": true}
+		return rc
+	}
+
+	tests := []struct {
+		name    string
+		changes []*model.ResourceChange
+		want    []model.MovedBlock
+	}{
+		{
+			name:    "renamed resource",
+			changes: []*model.ResourceChange{deleted("aws_s3_bucket.logs", "acme-logs"), created("aws_s3_bucket.access_logs", "acme-logs")},
+			want:    []model.MovedBlock{{From: "aws_s3_bucket.logs", To: "aws_s3_bucket.access_logs"}},
+		},
+		{
+			name: "resource moved into a module",
+			changes: []*model.ResourceChange{
+				deleted("aws_s3_bucket.logs", "acme-logs"),
+				deleted("aws_s3_bucket.data", "acme-data"),
+				created("module.s3.aws_s3_bucket.data", "acme-data"),
+				created("module.s3.aws_s3_bucket.logs", "acme-logs"),
+			},
+			want: []model.MovedBlock{
+				{From: "aws_s3_bucket.logs", To: "module.s3.aws_s3_bucket.logs"},
+				{From: "aws_s3_bucket.data", To: "module.s3.aws_s3_bucket.data"},
+			},
+		},
+		{
+			name:    "different attributes",
+			changes: []*model.ResourceChange{deleted("aws_s3_bucket.logs", "acme-logs"), created("aws_s3_bucket.access_logs", "acme-access-logs")},
+		},
+		{
+			name: "different type",
+			changes: []*model.ResourceChange{
+				deleted("aws_s3_bucket.logs", "acme-logs"),
+				{Address: "aws_s3_directory_bucket.logs", Mode: "managed", Type: "aws_s3_directory_bucket", Actions: []string{"create"}, After: map[string]interface{}{"bucket": "acme-logs", "force_destroy": false}},
+			},
+		},
+		{
+			name: "ambiguous match",
+			changes: []*model.ResourceChange{
+				deleted("aws_s3_bucket.logs", "acme-logs"),
+				created("aws_s3_bucket.a", "acme-logs"),
+				created("aws_s3_bucket.b", "acme-logs"),
+			},
+		},
+		{
+			name: "only unknown attributes",
+			changes: []*model.ResourceChange{
+				deleted("aws_s3_bucket.logs", "acme-logs"),
+				{Address: "aws_s3_bucket.new", Mode: "managed", Type: "aws_s3_bucket", Actions: []string{"create"}, After: map[string]interface{}{"bucket": nil}, AfterUnknown: map[string]interface{}{"bucket": true}},
+			},
+		},
+		{
+			name: "replacements and data sources are ignored",
+			changes: []*model.ResourceChange{
+				bucket("aws_s3_bucket.logs", []string{"delete", "create"}, map[string]interface{}{"bucket": "acme-logs"}),
+				created("aws_s3_bucket.access_logs", "acme-logs"),
+				{Address: "data.aws_s3_bucket.old", Mode: "data", Type: "aws_s3_bucket", Actions: []string{"delete"}, Before: map[string]interface{}{"bucket": "acme-logs", "force_destroy": false}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SuggestMoves(&model.Plan{ResourceChanges: tt.changes})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SuggestMoves() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
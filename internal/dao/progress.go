@@ -0,0 +1,84 @@
+package dao
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/idongju/t9s/internal/model"
+)
+
+// applyEventJSON is the subset of a `terraform apply -json` UI event used for progress
+type applyEventJSON struct {
+	Message string `json:"@message"`
+	Type    string `json:"type"`
+	Hook    struct {
+		Resource struct {
+			Addr string `json:"addr"`
+		} `json:"resource"`
+		Action  string  `json:"action"`
+		Elapsed float64 `json:"elapsed_seconds"`
+	} `json:"hook"`
+	Change struct {
+		Resource struct {
+			Addr string `json:"addr"`
+		} `json:"resource"`
+		Action string `json:"action"`
+	} `json:"change"`
+	Changes struct {
+		Add       int    `json:"add"`
+		Change    int    `json:"change"`
+		Remove    int    `json:"remove"`
+		Import    int    `json:"import"`
+		Operation string `json:"operation"`
+	} `json:"changes"`
+}
+
+// ConsumeApplyEvent updates progress with a line of -json output received at now.
+// It returns the human-readable message of the event, or false if the line is not an event.
+func ConsumeApplyEvent(progress *model.ApplyProgress, line string, now time.Time) (string, bool) {
+	if !strings.HasPrefix(strings.TrimSpace(line), "{") {
+		return "", false
+	}
+	var ev applyEventJSON
+	if err := json.Unmarshal([]byte(line), &ev); err != nil {
+		return "", false
+	}
+
+	switch ev.Type {
+	case "planned_change":
+		if ev.Change.Action != "noop" && ev.Change.Action != "read" {
+			progress.Resource(ev.Change.Resource.Addr, ev.Change.Action)
+		}
+	case "apply_start":
+		r := progress.Resource(ev.Hook.Resource.Addr, ev.Hook.Action)
+		// A replacement starts twice, for the delete and the create
+		r.State = model.ResourceRunning
+		r.Started = now
+		r.Elapsed = 0
+	case "apply_progress":
+		r := progress.Resource(ev.Hook.Resource.Addr, ev.Hook.Action)
+		r.State = model.ResourceRunning
+		r.Elapsed = seconds(ev.Hook.Elapsed)
+	case "apply_complete", "apply_errored":
+		r := progress.Resource(ev.Hook.Resource.Addr, ev.Hook.Action)
+		r.State = model.ResourceComplete
+		if ev.Type == "apply_errored" {
+			r.State = model.ResourceErrored
+		}
+		r.Elapsed = seconds(ev.Hook.Elapsed)
+	case "change_summary":
+		if ev.Changes.Operation != "plan" {
+			progress.Finished = true
+			progress.Added = ev.Changes.Add
+			progress.Changed = ev.Changes.Change
+			progress.Destroyed = ev.Changes.Remove
+			progress.Imported = ev.Changes.Import
+		}
+	}
+	return ev.Message, true
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
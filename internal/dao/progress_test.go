@@ -0,0 +1,154 @@
+package dao
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/idongju/t9s/internal/model"
+)
+
+func TestConsumeApplyEventStream(t *testing.T) {
+	base := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	type resource struct {
+		address string
+		action  string
+		state   string
+		elapsed time.Duration
+	}
+
+	tests := []struct {
+		name      string
+		sample    string
+		resources []resource
+		finished  bool
+		added     int
+		changed   int
+		destroyed int
+		messages  []string // a few of the returned messages, in order
+	}{
+		{
+			name:   "replacement that fails to create",
+			sample: "apply.jsonl",
+			resources: []resource{
+				{"data.aws_caller_identity.current", "read", model.ResourceComplete, 0},
+				{"aws_instance.web", "replace", model.ResourceErrored, 5 * time.Second},
+				{"aws_s3_bucket.logs", "create", model.ResourceComplete, 2 * time.Second},
+				{`module.dns.aws_route53_record.www["a"]`, "update", model.ResourceComplete, 40 * time.Second},
+			},
+			messages: []string{
+				"aws_instance.web: Plan to replace",
+				"aws_instance.web: Destroying... [id=i-0123456789abcdef0]",
+				"aws_instance.web: Creation errored after 5s",
+			},
+		},
+		{
+			name:   "successful update",
+			sample: "apply_success.jsonl",
+			resources: []resource{
+				{"aws_s3_bucket.logs", "update", model.ResourceComplete, time.Second},
+			},
+			finished: true,
+			changed:  1,
+			messages: []string{
+				"Plan: 0 to add, 1 to change, 0 to destroy.",
+				"Apply complete! Resources: 0 added, 1 changed, 0 destroyed.",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", tt.sample))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			progress := model.NewApplyProgress()
+			var messages []string
+			for i, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+				msg, ok := ConsumeApplyEvent(progress, line, base.Add(time.Duration(i)*time.Second))
+				if !ok {
+					t.Fatalf("line %d not recognized as an event", i+1)
+				}
+				messages = append(messages, msg)
+			}
+
+			var got []resource
+			for _, r := range progress.Resources {
+				got = append(got, resource{r.Address, r.Action, r.State, r.Elapsed})
+			}
+			if !reflect.DeepEqual(got, tt.resources) {
+				t.Errorf("resources = %+v, want %+v", got, tt.resources)
+			}
+			if progress.Finished != tt.finished || progress.Added != tt.added || progress.Changed != tt.changed || progress.Destroyed != tt.destroyed {
+				t.Errorf("summary = finished %v +%d ~%d -%d, want finished %v +%d ~%d -%d",
+					progress.Finished, progress.Added, progress.Changed, progress.Destroyed,
+					tt.finished, tt.added, tt.changed, tt.destroyed)
+			}
+
+			next := 0
+			for _, msg := range messages {
+				if next < len(tt.messages) && msg == tt.messages[next] {
+					next++
+				}
+			}
+			if next < len(tt.messages) {
+				t.Errorf("message %q not returned in order", tt.messages[next])
+			}
+		})
+	}
+}
+
+func TestConsumeApplyEventRestart(t *testing.T) {
+	base := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	progress := model.NewApplyProgress()
+	lines := []string{
+		`{"@message":"aws_instance.web: Destroying... [id=i-1]","hook":{"resource":{"addr":"aws_instance.web"},"action":"delete"},"type":"apply_start"}`,
+		`{"@message":"aws_instance.web: Destruction complete after 31s","hook":{"resource":{"addr":"aws_instance.web"},"action":"delete","elapsed_seconds":31},"type":"apply_complete"}`,
+		`{"@message":"aws_instance.web: Creating...","hook":{"resource":{"addr":"aws_instance.web"},"action":"create"},"type":"apply_start"}`,
+	}
+	for i, line := range lines {
+		ConsumeApplyEvent(progress, line, base.Add(time.Duration(i)*time.Minute))
+	}
+
+	r := progress.Resources[0]
+	if r.State != model.ResourceRunning || r.Elapsed != 0 || !r.Started.Equal(base.Add(2*time.Minute)) {
+		t.Errorf("second start: state %s, elapsed %s, started %s", r.State, r.Elapsed, r.Started)
+	}
+	if r.Action != "delete" {
+		t.Errorf("action = %q, want the first reported action", r.Action)
+	}
+}
+
+func TestConsumeApplyEventLines(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		message string
+		ok      bool
+	}{
+		{"human-readable output", "aws_instance.web: Creating...", "", false},
+		{"blank line", "", "", false},
+		{"truncated event", `{"@level":"info","@message":"aws_instance.web: Crea`, "", false},
+		{"indented event", `  {"@message":"Terraform 1.5.7","type":"version"}`, "Terraform 1.5.7", true},
+		{"event without resource", `{"@message":"Outputs: 0","outputs":{},"type":"outputs"}`, "Outputs: 0", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			progress := model.NewApplyProgress()
+			message, ok := ConsumeApplyEvent(progress, tt.line, time.Now())
+			if message != tt.message || ok != tt.ok {
+				t.Errorf("ConsumeApplyEvent() = %q, %v, want %q, %v", message, ok, tt.message, tt.ok)
+			}
+			if len(progress.Resources) != 0 {
+				t.Errorf("resources = %d, want none", len(progress.Resources))
+			}
+		})
+	}
+}
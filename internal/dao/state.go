@@ -0,0 +1,162 @@
+package dao
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+
+	"github.com/idongju/t9s/internal/model"
+)
+
+// showJSON is the subset of `terraform show -json` used by t9s
+type showJSON struct {
+	TerraformVersion string `json:"terraform_version"`
+	Values           *struct {
+		Outputs map[string]struct {
+			Sensitive bool        `json:"sensitive"`
+			Value     interface{} `json:"value"`
+			Type      interface{} `json:"type"`
+		} `json:"outputs"`
+		RootModule moduleJSON `json:"root_module"`
+	} `json:"values"`
+}
+
+type moduleJSON struct {
+	Address      string         `json:"address"`
+	Resources    []resourceJSON `json:"resources"`
+	ChildModules []moduleJSON   `json:"child_modules"`
+}
+
+type resourceJSON struct {
+	Address         string                 `json:"address"`
+	Mode            string                 `json:"mode"`
+	Type            string                 `json:"type"`
+	Name            string                 `json:"name"`
+	Index           interface{}            `json:"index"`
+	ProviderName    string                 `json:"provider_name"`
+	Values          map[string]interface{} `json:"values"`
+	SensitiveValues interface{}            `json:"sensitive_values"`
+	DependsOn       []string               `json:"depends_on"`
+	Tainted         bool                   `json:"tainted"`
+}
+
+// GetState reads the state of a directory with `terraform show -json`
+func (d *TerraformDAO) GetState(path string) (*model.State, error) {
+	cmd := exec.Command("terraform", "show", "-json")
+	cmd.Dir = path
+
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("terraform show failed: %w: %s", err, string(exitErr.Stderr))
+		}
+		return nil, fmt.Errorf("terraform show failed: %w", err)
+	}
+
+	return ParseState(output)
+}
+
+// ParseState parses the output of `terraform show -json`, masking sensitive values
+func ParseState(data []byte) (*model.State, error) {
+	var raw showJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse state: %w", err)
+	}
+
+	state := &model.State{
+		TerraformVersion: raw.TerraformVersion,
+		Root:             &model.StateModule{},
+	}
+	// An empty state has no values at all
+	if raw.Values == nil {
+		return state, nil
+	}
+
+	state.Root = convertModule(raw.Values.RootModule)
+
+	for name, out := range raw.Values.Outputs {
+		output := &model.StateOutput{
+			Name:      name,
+			Type:      out.Type,
+			Value:     out.Value,
+			Sensitive: out.Sensitive,
+		}
+		if out.Sensitive {
+			output.Value = model.SensitiveMask
+		}
+		state.Outputs = append(state.Outputs, output)
+	}
+	sort.Slice(state.Outputs, func(i, j int) bool { return state.Outputs[i].Name < state.Outputs[j].Name })
+
+	return state, nil
+}
+
+// LoadState reads the state of a directory and updates its resource and output counts
+func (d *TerraformDAO) LoadState(dir *model.TerraformDirectory) (*model.State, error) {
+	state, err := d.GetState(dir.Path)
+	if err != nil {
+		return nil, err
+	}
+	dir.Resources = 0
+	for _, res := range state.Resources() {
+		if res.Mode == "managed" {
+			dir.Resources++
+		}
+	}
+	dir.Outputs = len(state.Outputs)
+	return state, nil
+}
+
+// convertModule converts a module and its children
+func convertModule(m moduleJSON) *model.StateModule {
+	module := &model.StateModule{Address: m.Address}
+	for _, r := range m.Resources {
+		values, _ := maskSensitive(r.Values, r.SensitiveValues).(map[string]interface{})
+		module.Resources = append(module.Resources, &model.StateResource{
+			Address:      r.Address,
+			Mode:         r.Mode,
+			Type:         r.Type,
+			Name:         r.Name,
+			Index:        r.Index,
+			ProviderName: r.ProviderName,
+			Values:       values,
+			DependsOn:    r.DependsOn,
+			Tainted:      r.Tainted,
+		})
+	}
+	for _, child := range m.ChildModules {
+		module.Children = append(module.Children, convertModule(child))
+	}
+	return module
+}
+
+// maskSensitive replaces values marked in the parallel sensitive structure.
+// Terraform marks a sensitive value with true at the same position.
+func maskSensitive(value, sensitive interface{}) interface{} {
+	if s, ok := sensitive.(bool); ok && s {
+		return model.SensitiveMask
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		marks, _ := sensitive.(map[string]interface{})
+		masked := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			masked[key] = maskSensitive(item, marks[key])
+		}
+		return masked
+	case []interface{}:
+		marks, _ := sensitive.([]interface{})
+		masked := make([]interface{}, len(v))
+		for i, item := range v {
+			var mark interface{}
+			if i < len(marks) {
+				mark = marks[i]
+			}
+			masked[i] = maskSensitive(item, mark)
+		}
+		return masked
+	}
+	return value
+}
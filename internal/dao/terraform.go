@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -14,48 +15,49 @@ import (
 
 // TerraformDAO handles Terraform data access operations
 type TerraformDAO struct {
-	RootPath string
+	RootPath  string
+	discovery DiscoveryOptions
 }
 
 // NewTerraformDAO creates a new Terraform DAO
 func NewTerraformDAO(rootPath string) *TerraformDAO {
 	return &TerraformDAO{
-		RootPath: rootPath,
+		RootPath:  rootPath,
+		discovery: defaultDiscovery,
 	}
 }
 
-// ListDirectories scans for Terraform directories
+// ListDirectories returns the stacks found by a recursive discovery below the root.
+// Reusable modules are left out.
 func (d *TerraformDAO) ListDirectories() ([]*model.TerraformDirectory, error) {
 	var directories []*model.TerraformDirectory
 
-	entries, err := os.ReadDir(d.RootPath)
+	index, err := d.Discover()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read root directory: %w", err)
+		return nil, err
 	}
-
-	for _, entry := range entries {
-		if !entry.IsDir() {
-			continue
-		}
-
-		dirPath := filepath.Join(d.RootPath, entry.Name())
-		
-		// Check if it's a Terraform directory (has .tf files)
-		if !d.isTerraformDir(dirPath) {
-			continue
+	stacks := index.Stacks()
+	sort.Strings(stacks)
+
+	for _, dirPath := range stacks {
+		entry := index.Entries[dirPath]
+		name, err := filepath.Rel(d.RootPath, dirPath)
+		if err != nil {
+			name = filepath.Base(dirPath)
 		}
 
 		dir := &model.TerraformDirectory{
-			Name:   entry.Name(),
-			Path:   dirPath,
-			Status: model.StatusUnknown,
+			Name:        name,
+			Path:        dirPath,
+			Status:      model.StatusUnknown,
+			TfvarsFiles: entry.Tfvars,
+			BackendType: entry.Backend,
 		}
 
-		// Find config directory and tfvars files
+		// Find config directory
 		configPath := filepath.Join(dirPath, "config")
 		if stat, err := os.Stat(configPath); err == nil && stat.IsDir() {
 			dir.ConfigPath = configPath
-			dir.TfvarsFiles = d.findTfvarsFiles(configPath)
 		}
 
 		// Get backend configuration
@@ -67,21 +69,6 @@ func (d *TerraformDAO) ListDirectories() ([]*model.TerraformDirectory, error) {
 	return directories, nil
 }
 
-// isTerraformDir checks if a directory contains Terraform files
-func (d *TerraformDAO) isTerraformDir(path string) bool {
-	entries, err := os.ReadDir(path)
-	if err != nil {
-		return false
-	}
-
-	for _, entry := range entries {
-		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".tf") {
-			return true
-		}
-	}
-	return false
-}
-
 // findTfvarsFiles finds all .tfvars files in a directory
 func (d *TerraformDAO) findTfvarsFiles(path string) []string {
 	var tfvarsFiles []string
@@ -102,6 +89,13 @@ func (d *TerraformDAO) findTfvarsFiles(path string) []string {
 
 // parseBackendConfig parses the backend configuration from Terraform files
 func (d *TerraformDAO) parseBackendConfig(dir *model.TerraformDirectory) {
+	if keys := d.StateKeys(dir.Path); len(keys) > 0 {
+		dir.BackendKey = keys[0]
+	}
+	if dir.BackendType != "" {
+		return
+	}
+
 	terraformDir := filepath.Join(dir.Path, ".terraform")
 	if stat, err := os.Stat(terraformDir); err == nil && stat.IsDir() {
 		dir.BackendType = "s3"
@@ -109,6 +103,88 @@ func (d *TerraformDAO) parseBackendConfig(dir *model.TerraformDirectory) {
 	}
 }
 
+// CurrentWorkspace returns the selected workspace of a directory without running terraform
+func (d *TerraformDAO) CurrentWorkspace(path string) string {
+	if ws := os.Getenv("TF_WORKSPACE"); ws != "" {
+		return ws
+	}
+	data, err := os.ReadFile(filepath.Join(path, ".terraform", "environment"))
+	if err != nil {
+		return "default"
+	}
+	if ws := strings.TrimSpace(string(data)); ws != "" {
+		return ws
+	}
+	return "default"
+}
+
+// ShowWorkspace asks terraform for the selected workspace of a directory
+func (d *TerraformDAO) ShowWorkspace(path string) (string, error) {
+	cmd := exec.Command("terraform", "workspace", "show")
+	cmd.Dir = path
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("terraform workspace show failed: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// ListWorkspaces lists the workspaces of a directory and returns the selected one
+func (d *TerraformDAO) ListWorkspaces(path string) ([]string, string, error) {
+	cmd := exec.Command("terraform", "workspace", "list")
+	cmd.Dir = path
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, "", fmt.Errorf("terraform workspace list failed: %w\nOutput: %s", err, string(output))
+	}
+
+	var workspaces []string
+	var current string
+	for _, line := range strings.Split(string(output), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		// Selected workspace is marked with *
+		if strings.HasPrefix(line, "*") {
+			line = strings.TrimSpace(line[1:])
+			current = line
+		}
+		workspaces = append(workspaces, line)
+	}
+
+	return workspaces, current, nil
+}
+
+// SelectWorkspace switches the workspace of a directory
+func (d *TerraformDAO) SelectWorkspace(path, name string) error {
+	return d.runWorkspace(path, "select", name)
+}
+
+// NewWorkspace creates a workspace and selects it
+func (d *TerraformDAO) NewWorkspace(path, name string) error {
+	return d.runWorkspace(path, "new", name)
+}
+
+// DeleteWorkspace deletes a workspace; terraform refuses to delete the selected one
+func (d *TerraformDAO) DeleteWorkspace(path, name string) error {
+	return d.runWorkspace(path, "delete", name)
+}
+
+// runWorkspace runs a terraform workspace subcommand in a directory
+func (d *TerraformDAO) runWorkspace(path, subcommand, name string) error {
+	cmd := exec.Command("terraform", "workspace", subcommand, name)
+	cmd.Dir = path
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("terraform workspace %s failed: %w\nOutput: %s", subcommand, err, string(output))
+	}
+	return nil
+}
+
 // CheckDrift checks if there is drift between state and actual infrastructure
 func (d *TerraformDAO) CheckDrift(dir *model.TerraformDirectory) error {
 	cmd := exec.Command("terraform", "plan", "-detailed-exitcode")
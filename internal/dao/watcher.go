@@ -0,0 +1,172 @@
+package dao
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultDebounce is how long the watcher waits for the file system to settle
+const DefaultDebounce = 300 * time.Millisecond
+
+// Watcher reports changes below the terraform root in debounced batches
+type Watcher struct {
+	dao      *TerraformDAO
+	fs       *fsnotify.Watcher
+	debounce time.Duration
+	onChange func(paths []string)
+	done     chan struct{}
+	once     sync.Once
+}
+
+// Watch watches the root and its directories up to the discovery depth, skipping hidden
+// and ignored directories, plus the .git directory of the enclosing repository.
+// onChange is called from a background goroutine with the sorted paths that changed.
+func (d *TerraformDAO) Watch(debounce time.Duration, onChange func(paths []string)) (*Watcher, error) {
+	fs, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Watcher{
+		dao:      d,
+		fs:       fs,
+		debounce: debounce,
+		onChange: onChange,
+		done:     make(chan struct{}),
+	}
+	if err := w.addTree(d.RootPath); err != nil {
+		fs.Close()
+		return nil, err
+	}
+	if gitDir := findGitDir(d.RootPath); gitDir != "" {
+		fs.Add(gitDir)
+	}
+
+	go w.loop()
+	return w, nil
+}
+
+// Close stops the watcher
+func (w *Watcher) Close() error {
+	var err error
+	w.once.Do(func() {
+		close(w.done)
+		err = w.fs.Close()
+	})
+	return err
+}
+
+// loop collects events until nothing changed for the debounce interval
+func (w *Watcher) loop() {
+	pending := make(map[string]bool)
+	timer := time.NewTimer(w.debounce)
+	timer.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			timer.Stop()
+			return
+		case event, ok := <-w.fs.Events:
+			if !ok {
+				return
+			}
+			if strings.HasSuffix(event.Name, ".lock") {
+				// git index.lock and friends come and go on every git command
+				continue
+			}
+			if event.Op&fsnotify.Create != 0 {
+				// New directories are skipped like addTree skips existing ones
+				hidden := strings.HasPrefix(filepath.Base(event.Name), ".")
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() && !hidden && !w.dao.ignored(event.Name) {
+					w.addTree(event.Name)
+				}
+			}
+			pending[event.Name] = true
+			timer.Reset(w.debounce)
+		case <-w.fs.Errors:
+			// Overflows and transient errors are dropped; the next event resyncs
+		case <-timer.C:
+			if len(pending) == 0 {
+				continue
+			}
+			paths := make([]string, 0, len(pending))
+			for path := range pending {
+				paths = append(paths, path)
+			}
+			sort.Strings(paths)
+			pending = make(map[string]bool)
+			w.onChange(paths)
+		}
+	}
+}
+
+// addTree adds dir and its subdirectories within the discovery depth
+func (w *Watcher) addTree(dir string) error {
+	depth := w.depth(dir)
+	if depth < 0 || depth > w.dao.discovery.MaxDepth {
+		return nil
+	}
+	if err := w.fs.Add(dir); err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		child := filepath.Join(dir, entry.Name())
+		if w.dao.ignored(child) {
+			continue
+		}
+		// Directories that vanish or exceed the watch limit are skipped
+		w.addTree(child)
+	}
+	return nil
+}
+
+// depth returns the number of directory levels of dir below the root, -1 outside of it
+func (w *Watcher) depth(dir string) int {
+	rel, err := filepath.Rel(w.dao.RootPath, dir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return -1
+	}
+	if rel == "." {
+		return 0
+	}
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		if strings.HasPrefix(part, ".") {
+			return -1
+		}
+	}
+	return strings.Count(rel, string(filepath.Separator)) + 1
+}
+
+// findGitDir returns the .git directory of the repository containing dir
+func findGitDir(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		gitDir := filepath.Join(dir, ".git")
+		if info, err := os.Stat(gitDir); err == nil && info.IsDir() {
+			return gitDir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
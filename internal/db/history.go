@@ -18,8 +18,11 @@ type HistoryEntry struct {
 	Timestamp  time.Time
 	User       string // user who executed the command
 	Branch     string // git branch at the time of execution
+	Workspace  string // terraform workspace at the time of execution
 	ConfigFile string // tfvars file used
 	ConfigData string // content of tfvars at that time
+	Addresses  string // resource addresses involved, one per line
+	Targets    string // -target addresses, one per line
 	Success    bool
 	ErrorMsg   string
 }
@@ -63,8 +66,11 @@ func (h *HistoryDB) init() error {
 		timestamp DATETIME NOT NULL,
 		user TEXT,
 		branch TEXT,
+		workspace TEXT,
 		config_file TEXT,
 		config_data TEXT,
+		addresses TEXT,
+		targets TEXT,
 		success INTEGER NOT NULL,
 		error_msg TEXT
 	);
@@ -76,6 +82,9 @@ func (h *HistoryDB) init() error {
 	// Add new columns if they don't exist (migration)
 	h.db.Exec(`ALTER TABLE history ADD COLUMN user TEXT`)
 	h.db.Exec(`ALTER TABLE history ADD COLUMN branch TEXT`)
+	h.db.Exec(`ALTER TABLE history ADD COLUMN workspace TEXT`)
+	h.db.Exec(`ALTER TABLE history ADD COLUMN addresses TEXT`)
+	h.db.Exec(`ALTER TABLE history ADD COLUMN targets TEXT`)
 
 	// Create indexes separately
 	indexes := []string{
@@ -95,8 +104,8 @@ func (h *HistoryDB) init() error {
 // AddEntry adds a new history entry
 func (h *HistoryDB) AddEntry(entry *HistoryEntry) error {
 	query := `
-	INSERT INTO history (directory, action, timestamp, user, branch, config_file, config_data, success, error_msg)
-	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	INSERT INTO history (directory, action, timestamp, user, branch, workspace, config_file, config_data, addresses, targets, success, error_msg)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 	result, err := h.db.Exec(query,
 		entry.Directory,
@@ -104,8 +113,11 @@ func (h *HistoryDB) AddEntry(entry *HistoryEntry) error {
 		entry.Timestamp.Format(time.RFC3339),
 		entry.User,
 		entry.Branch,
+		entry.Workspace,
 		entry.ConfigFile,
 		entry.ConfigData,
+		entry.Addresses,
+		entry.Targets,
 		entry.Success,
 		entry.ErrorMsg,
 	)
@@ -127,7 +139,11 @@ func (h *HistoryDB) GetByDirectory(directory string, limit int) ([]*HistoryEntry
 	SELECT id, directory, action, timestamp, 
 	       COALESCE(user, '') as user, 
 	       COALESCE(branch, '') as branch,
-	       config_file, config_data, success, error_msg
+	       COALESCE(workspace, '') as workspace,
+	       config_file, config_data,
+	       COALESCE(addresses, '') as addresses,
+	       COALESCE(targets, '') as targets,
+	       success, error_msg
 	FROM history
 	WHERE directory = ?
 	ORDER BY timestamp DESC
@@ -150,8 +166,11 @@ func (h *HistoryDB) GetByDirectory(directory string, limit int) ([]*HistoryEntry
 			&timestamp,
 			&entry.User,
 			&entry.Branch,
+			&entry.Workspace,
 			&entry.ConfigFile,
 			&entry.ConfigData,
+			&entry.Addresses,
+			&entry.Targets,
 			&entry.Success,
 			&entry.ErrorMsg,
 		)
@@ -180,7 +199,11 @@ func (h *HistoryDB) GetRecent(limit int) ([]*HistoryEntry, error) {
 	SELECT id, directory, action, timestamp,
 	       COALESCE(user, '') as user,
 	       COALESCE(branch, '') as branch,
-	       config_file, config_data, success, error_msg
+	       COALESCE(workspace, '') as workspace,
+	       config_file, config_data,
+	       COALESCE(addresses, '') as addresses,
+	       COALESCE(targets, '') as targets,
+	       success, error_msg
 	FROM history
 	ORDER BY timestamp DESC
 	LIMIT ?
@@ -202,8 +225,11 @@ func (h *HistoryDB) GetRecent(limit int) ([]*HistoryEntry, error) {
 			&timestamp,
 			&entry.User,
 			&entry.Branch,
+			&entry.Workspace,
 			&entry.ConfigFile,
 			&entry.ConfigData,
+			&entry.Addresses,
+			&entry.Targets,
 			&entry.Success,
 			&entry.ErrorMsg,
 		)
@@ -226,6 +252,48 @@ func (h *HistoryDB) GetRecent(limit int) ([]*HistoryEntry, error) {
 	return entries, nil
 }
 
+// LastApplied returns the latest successful apply of every directory, keyed by directory.
+// Only the directory, timestamp, user and branch of the entries are set.
+func (h *HistoryDB) LastApplied() (map[string]*HistoryEntry, error) {
+	query := `
+	SELECT directory, MAX(timestamp),
+	       COALESCE(user, '') as user,
+	       COALESCE(branch, '') as branch
+	FROM history
+	WHERE action = 'apply' AND success = 1
+	GROUP BY directory
+	`
+	rows, err := h.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[string]*HistoryEntry)
+	for rows.Next() {
+		entry := &HistoryEntry{Action: "apply", Success: true}
+		var timestamp string
+		if err := rows.Scan(&entry.Directory, &timestamp, &entry.User, &entry.Branch); err != nil {
+			return nil, err
+		}
+		entry.Timestamp = parseTimestamp(timestamp)
+		applied[entry.Directory] = entry
+	}
+
+	return applied, rows.Err()
+}
+
+// parseTimestamp parses a stored timestamp, trying the formats written by the sqlite driver.
+// It returns the zero time if no format matches.
+func parseTimestamp(timestamp string) time.Time {
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02 15:04:05.999999999-07:00"} {
+		if t, err := time.Parse(layout, timestamp); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
 // Close closes the database connection
 func (h *HistoryDB) Close() error {
 	return h.db.Close()
@@ -3,6 +3,7 @@ package git
 import (
 	"fmt"
 	"os/exec"
+	"path/filepath"
 	"strings"
 )
 
@@ -52,6 +53,15 @@ func (m *Manager) GetStatus(path string) (*Status, error) {
 	return status, nil
 }
 
+// CurrentBranch returns the current Git branch, or an empty string outside a repository
+func (m *Manager) CurrentBranch(path string) string {
+	branch, err := m.getCurrentBranch(path)
+	if err != nil {
+		return ""
+	}
+	return branch
+}
+
 // getCurrentBranch gets the current Git branch
 func (m *Manager) getCurrentBranch(path string) (string, error) {
 	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
@@ -103,6 +113,59 @@ func (m *Manager) getModifiedFiles(path string) ([]string, error) {
 	return files, nil
 }
 
+// FileState is the git state of a changed file
+type FileState int
+
+const (
+	FileModified  FileState = iota + 1 // changed in the working tree
+	FileStaged                         // changes staged in the index only
+	FileUntracked                      // not tracked by git
+)
+
+// FileStates returns the state of every changed file of the repository containing path,
+// keyed by absolute path. Files with unstaged changes are reported as modified even when
+// other changes are staged.
+func (m *Manager) FileStates(path string) (map[string]FileState, error) {
+	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
+	cmd.Dir = path
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find repository root: %w", err)
+	}
+	top := strings.TrimSpace(string(output))
+
+	cmd = exec.Command("git", "status", "--porcelain", "-z", "--untracked-files=all")
+	cmd.Dir = path
+	output, err = cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check git status: %w", err)
+	}
+
+	states := make(map[string]FileState)
+	entries := strings.Split(string(output), "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		x, y, file := entry[0], entry[1], entry[3:]
+		if x == 'R' || x == 'C' {
+			// Renames and copies are followed by the original path
+			i++
+		}
+
+		state := FileStaged
+		switch {
+		case x == '?':
+			state = FileUntracked
+		case y != ' ':
+			state = FileModified
+		}
+		states[filepath.Join(top, filepath.FromSlash(file))] = state
+	}
+	return states, nil
+}
+
 // GetDiff gets the diff for a specific file or the entire directory
 func (m *Manager) GetDiff(path string, file string) (string, error) {
 	args := []string{"diff"}
@@ -0,0 +1,23 @@
+package model
+
+// Severities of a diagnostic
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Diagnostic is an error or warning reported by terraform, with its source location if any
+type Diagnostic struct {
+	Severity string // SeverityError or SeverityWarning
+	Summary  string
+	Detail   string
+	Address  string // resource address the diagnostic is about, if reported
+	File     string // absolute path, empty when there is no source location
+	Line     int
+	Column   int
+}
+
+// IsError reports whether the diagnostic is an error
+func (d *Diagnostic) IsError() bool {
+	return d.Severity == SeverityError
+}
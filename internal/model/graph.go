@@ -0,0 +1,164 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// StackGraph represents the dependencies between stacks
+type StackGraph struct {
+	Stacks   []string                     // stack directories, sorted
+	Deps     map[string][]StackDependency // stack -> stacks that must be applied before it
+	Warnings []string                     // e.g. depends_on entries that match no stack
+}
+
+// StackDependency is an edge of the stack graph
+type StackDependency struct {
+	Stack  string // directory of the stack depended on
+	Source string // why, e.g. "data.terraform_remote_state.vpc" or ".t9s.yaml"
+	File   string
+	Line   int
+}
+
+// NewStackGraph creates an empty graph of the given stacks
+func NewStackGraph(stacks []string) *StackGraph {
+	sorted := append([]string(nil), stacks...)
+	sort.Strings(sorted)
+	return &StackGraph{
+		Stacks: sorted,
+		Deps:   make(map[string][]StackDependency),
+	}
+}
+
+// AddDependency records that stack depends on dep.Stack; duplicate edges are ignored
+func (g *StackGraph) AddDependency(stack string, dep StackDependency) {
+	if stack == dep.Stack {
+		return
+	}
+	for _, d := range g.Deps[stack] {
+		if d.Stack == dep.Stack {
+			return
+		}
+	}
+	g.Deps[stack] = append(g.Deps[stack], dep)
+}
+
+// DependsOn returns the stacks a stack depends on directly
+func (g *StackGraph) DependsOn(stack string) []string {
+	var deps []string
+	for _, d := range g.Deps[stack] {
+		deps = append(deps, d.Stack)
+	}
+	sort.Strings(deps)
+	return deps
+}
+
+// Dependents returns the stacks that depend on a stack directly
+func (g *StackGraph) Dependents(stack string) []string {
+	var dependents []string
+	for _, s := range g.Stacks {
+		for _, d := range g.Deps[s] {
+			if d.Stack == stack {
+				dependents = append(dependents, s)
+				break
+			}
+		}
+	}
+	return dependents
+}
+
+// Roots returns the stacks that depend on nothing
+func (g *StackGraph) Roots() []string {
+	var roots []string
+	for _, s := range g.Stacks {
+		if len(g.Deps[s]) == 0 {
+			roots = append(roots, s)
+		}
+	}
+	return roots
+}
+
+// Order returns the given stacks in dependency order, dependencies first.
+// Dependencies through stacks that are not given are respected as well.
+func (g *StackGraph) Order(stacks []string) ([]string, error) {
+	full, err := g.sorted()
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]bool)
+	for _, s := range stacks {
+		wanted[s] = true
+	}
+	var ordered []string
+	for _, s := range full {
+		if wanted[s] {
+			ordered = append(ordered, s)
+			delete(wanted, s)
+		}
+	}
+
+	// Stacks outside of the graph keep their given order at the end
+	for _, s := range stacks {
+		if wanted[s] {
+			ordered = append(ordered, s)
+			delete(wanted, s)
+		}
+	}
+	return ordered, nil
+}
+
+// Levels returns the depth of each stack: 0 for roots, otherwise one more than its deepest dependency
+func (g *StackGraph) Levels() (map[string]int, error) {
+	order, err := g.sorted()
+	if err != nil {
+		return nil, err
+	}
+	levels := make(map[string]int)
+	for _, s := range order {
+		for _, d := range g.Deps[s] {
+			if levels[d.Stack]+1 > levels[s] {
+				levels[s] = levels[d.Stack] + 1
+			}
+		}
+	}
+	return levels, nil
+}
+
+// sorted returns all stacks in topological order, failing on a cycle
+func (g *StackGraph) sorted() ([]string, error) {
+	remaining := make(map[string]int)
+	for _, s := range g.Stacks {
+		remaining[s] = len(g.Deps[s])
+	}
+
+	var order []string
+	ready := g.Roots()
+	for len(ready) > 0 {
+		s := ready[0]
+		ready = ready[1:]
+		order = append(order, s)
+
+		var next []string
+		for _, dependent := range g.Dependents(s) {
+			remaining[dependent]--
+			if remaining[dependent] == 0 {
+				next = append(next, dependent)
+			}
+		}
+		ready = append(ready, next...)
+		sort.Strings(ready)
+	}
+
+	if len(order) < len(g.Stacks) {
+		var cycle []string
+		for _, s := range g.Stacks {
+			if remaining[s] > 0 {
+				cycle = append(cycle, s)
+			}
+		}
+		return order, fmt.Errorf("dependency cycle between: %s", strings.Join(cycle, ", "))
+	}
+	return order, nil
+}
@@ -0,0 +1,82 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStackGraphOrder(t *testing.T) {
+	tests := []struct {
+		name   string
+		stacks []string
+		deps   [][2]string // stack -> stack it depends on
+		order  []string    // stacks passed to Order
+		want   []string
+		levels map[string]int
+		err    string
+	}{
+		{
+			name:   "chain",
+			stacks: []string{"app", "db", "vpc"},
+			deps:   [][2]string{{"app", "db"}, {"db", "vpc"}},
+			order:  []string{"app", "vpc"},
+			want:   []string{"vpc", "app"},
+			levels: map[string]int{"vpc": 0, "db": 1, "app": 2},
+		},
+		{
+			name:   "diamond",
+			stacks: []string{"app", "cache", "db", "vpc"},
+			deps:   [][2]string{{"app", "cache"}, {"app", "db"}, {"cache", "vpc"}, {"db", "vpc"}},
+			order:  []string{"app", "db", "cache", "vpc"},
+			want:   []string{"vpc", "cache", "db", "app"},
+			levels: map[string]int{"vpc": 0, "cache": 1, "db": 1, "app": 2},
+		},
+		{
+			name:   "stacks outside of the graph come last",
+			stacks: []string{"app", "vpc"},
+			deps:   [][2]string{{"app", "vpc"}},
+			order:  []string{"other", "app", "vpc"},
+			want:   []string{"vpc", "app", "other"},
+			levels: map[string]int{"vpc": 0, "app": 1},
+		},
+		{
+			name:   "cycle",
+			stacks: []string{"a", "b", "c", "root"},
+			deps:   [][2]string{{"a", "b"}, {"b", "c"}, {"c", "a"}, {"a", "root"}},
+			order:  []string{"a"},
+			err:    "dependency cycle between: a, b, c",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewStackGraph(tt.stacks)
+			for _, d := range tt.deps {
+				g.AddDependency(d[0], StackDependency{Stack: d[1]})
+			}
+
+			order, err := g.Order(tt.order)
+			levels, levelsErr := g.Levels()
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("Order() error = %v, want %q", err, tt.err)
+				}
+				if levelsErr == nil || levelsErr.Error() != tt.err {
+					t.Errorf("Levels() error = %v, want %q", levelsErr, tt.err)
+				}
+				return
+			}
+			if err != nil || levelsErr != nil {
+				t.Fatalf("unexpected error: %v, %v", err, levelsErr)
+			}
+			if !reflect.DeepEqual(order, tt.want) {
+				t.Errorf("Order() = %v, want %v", order, tt.want)
+			}
+			for stack, want := range tt.levels {
+				if levels[stack] != want {
+					t.Errorf("Levels()[%q] = %d, want %d", stack, levels[stack], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+// Kinds of terraform directories
+const (
+	KindStack  = "stack"  // a root module that is planned and applied: has a backend, tfvars or state
+	KindModule = "module" // a reusable module, only used through module blocks
+)
+
+// StackIndex is the cached result of discovering terraform directories below a root
+type StackIndex struct {
+	Root      string                 `json:"root"`
+	MaxDepth  int                    `json:"max_depth"`
+	Ignore    []string               `json:"ignore"`
+	Generated time.Time              `json:"generated"`
+	Entries   map[string]*IndexEntry `json:"entries"` // keyed by directory path
+}
+
+// IndexEntry is a discovered terraform directory
+type IndexEntry struct {
+	Path       string   `json:"path"`
+	Kind       string   `json:"kind"`                  // KindStack or KindModule
+	Backend    string   `json:"backend,omitempty"`     // backend type of the terraform block
+	Tfvars     []string `json:"tfvars,omitempty"`      // tfvars files, relative to the directory
+	ModuleRefs []string `json:"module_refs,omitempty"` // local module sources, as absolute paths
+	HasState   bool     `json:"has_state,omitempty"`   // initialized (.terraform) or has a local state file
+	Signature  string   `json:"signature"`             // changes whenever a .tf or tfvars file changes
+}
+
+// Stacks returns the paths of the entries that are stacks
+func (idx *StackIndex) Stacks() []string {
+	var stacks []string
+	for path, e := range idx.Entries {
+		if e.Kind == KindStack {
+			stacks = append(stacks, path)
+		}
+	}
+	return stacks
+}
@@ -0,0 +1,74 @@
+package model
+
+// Plan represents the resource changes of a saved plan as reported by `terraform show -json`
+type Plan struct {
+	ResourceChanges []*ResourceChange
+}
+
+// ResourceChange represents a planned change of a resource instance
+type ResourceChange struct {
+	Address      string
+	ModuleAddr   string
+	Mode         string
+	Type         string
+	Name         string
+	Actions      []string // e.g. ["create"], ["delete"], ["delete", "create"]
+	Before       map[string]interface{}
+	After        map[string]interface{}
+	AfterUnknown map[string]interface{}
+}
+
+// MovedBlock represents a suggested `moved` block
+type MovedBlock struct {
+	From string
+	To   string
+}
+
+// IsCreate reports whether the change only creates the resource
+func (rc *ResourceChange) IsCreate() bool {
+	return len(rc.Actions) == 1 && rc.Actions[0] == "create"
+}
+
+// IsDelete reports whether the change only deletes the resource
+func (rc *ResourceChange) IsDelete() bool {
+	return len(rc.Actions) == 1 && rc.Actions[0] == "delete"
+}
+
+// IsNoOp reports whether the plan leaves the resource unchanged
+func (rc *ResourceChange) IsNoOp() bool {
+	return len(rc.Actions) == 0 || (len(rc.Actions) == 1 && (rc.Actions[0] == "no-op" || rc.Actions[0] == "read"))
+}
+
+// Action returns a short label for the change: create, update, delete or replace
+func (rc *ResourceChange) Action() string {
+	if len(rc.Actions) == 2 {
+		return "replace"
+	}
+	if len(rc.Actions) == 1 {
+		return rc.Actions[0]
+	}
+	return ""
+}
+
+// ChangedAddresses returns the modules and managed resources the plan changes, in plan order,
+// with the action planned for each resource
+func (p *Plan) ChangedAddresses() ([]string, map[string]string) {
+	var addresses []string
+	actions := make(map[string]string)
+	seen := make(map[string]bool)
+	for _, rc := range p.ResourceChanges {
+		if rc.Mode != "managed" || rc.IsNoOp() {
+			continue
+		}
+		if rc.ModuleAddr != "" && !seen[rc.ModuleAddr] {
+			seen[rc.ModuleAddr] = true
+			addresses = append(addresses, rc.ModuleAddr)
+		}
+		if !seen[rc.Address] {
+			seen[rc.Address] = true
+			addresses = append(addresses, rc.Address)
+			actions[rc.Address] = rc.Action()
+		}
+	}
+	return addresses, actions
+}
@@ -0,0 +1,79 @@
+package model
+
+import "time"
+
+// States of a resource during an apply
+const (
+	ResourcePending  = "pending"
+	ResourceRunning  = "running"
+	ResourceComplete = "complete"
+	ResourceErrored  = "errored"
+)
+
+// ResourceProgress is the apply status of a single resource instance
+type ResourceProgress struct {
+	Address string
+	Action  string // create, update, delete, replace, ...
+	State   string // ResourcePending, ResourceRunning, ResourceComplete or ResourceErrored
+	Started time.Time
+	Elapsed time.Duration // reported by terraform, or measured while running
+}
+
+// ApplyProgress tracks the resources of an apply, destroy or refresh run with -json
+type ApplyProgress struct {
+	Resources []*ResourceProgress // in the order terraform planned or started them
+	Finished  bool                // the final change summary has been reported
+	Added     int
+	Changed   int
+	Destroyed int
+	Imported  int
+	byAddress map[string]*ResourceProgress
+}
+
+// NewApplyProgress creates an empty apply progress
+func NewApplyProgress() *ApplyProgress {
+	return &ApplyProgress{byAddress: make(map[string]*ResourceProgress)}
+}
+
+// Resource returns the progress of addr, adding it as pending if it is new.
+// action is recorded unless the resource already has one, so replacements stay replacements.
+func (p *ApplyProgress) Resource(addr, action string) *ResourceProgress {
+	r, ok := p.byAddress[addr]
+	if !ok {
+		r = &ResourceProgress{Address: addr, State: ResourcePending}
+		p.byAddress[addr] = r
+		p.Resources = append(p.Resources, r)
+	}
+	if r.Action == "" {
+		r.Action = action
+	}
+	return r
+}
+
+// Tick updates the elapsed time of running resources
+func (p *ApplyProgress) Tick(now time.Time) {
+	for _, r := range p.Resources {
+		if r.State == ResourceRunning && !r.Started.IsZero() {
+			if elapsed := now.Sub(r.Started); elapsed > r.Elapsed {
+				r.Elapsed = elapsed
+			}
+		}
+	}
+}
+
+// Counts returns the number of resources in each state
+func (p *ApplyProgress) Counts() (pending, running, complete, errored int) {
+	for _, r := range p.Resources {
+		switch r.State {
+		case ResourceRunning:
+			running++
+		case ResourceComplete:
+			complete++
+		case ResourceErrored:
+			errored++
+		default:
+			pending++
+		}
+	}
+	return
+}
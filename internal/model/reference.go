@@ -0,0 +1,10 @@
+package model
+
+// SymbolReference is a use of an input variable, local value or module in a configuration
+// file, resolved to its declaration within the stack
+type SymbolReference struct {
+	Address string // e.g. var.region, local.name or module.vpc
+	Line    int    // line of the reference
+	DefFile string // file declaring the symbol, empty if it is not declared in the stack
+	DefLine int
+}
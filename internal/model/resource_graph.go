@@ -0,0 +1,78 @@
+package model
+
+import (
+	"sort"
+	"strings"
+)
+
+// ResourceGraph represents the resource dependency graph of a stack as reported by `terraform graph`
+type ResourceGraph struct {
+	Nodes []string            // resource, data source, module and provider addresses, sorted
+	Deps  map[string][]string // node -> nodes it depends on
+	Dot   string              // the original DOT text
+}
+
+// DependsOn returns the nodes a node depends on directly
+func (g *ResourceGraph) DependsOn(node string) []string {
+	return g.Deps[node]
+}
+
+// Dependents returns the nodes that depend on a node directly
+func (g *ResourceGraph) Dependents(node string) []string {
+	var dependents []string
+	for _, n := range g.Nodes {
+		for _, d := range g.Deps[n] {
+			if d == node {
+				dependents = append(dependents, n)
+				break
+			}
+		}
+	}
+	return dependents
+}
+
+// Matches reports whether a node matches a filter on its address
+func (g *ResourceGraph) Matches(node, filter string) bool {
+	return filter == "" || strings.Contains(strings.ToLower(node), strings.ToLower(filter))
+}
+
+// sortDeps sorts the dependencies of every node
+func (g *ResourceGraph) sortDeps() {
+	for _, deps := range g.Deps {
+		sort.Strings(deps)
+	}
+}
+
+// NewResourceGraph builds a graph from nodes and dependency edges, dropping duplicates and self edges
+func NewResourceGraph(nodes []string, edges [][2]string, dot string) *ResourceGraph {
+	g := &ResourceGraph{
+		Deps: make(map[string][]string),
+		Dot:  dot,
+	}
+
+	seen := make(map[string]bool)
+	add := func(n string) {
+		if !seen[n] {
+			seen[n] = true
+			g.Nodes = append(g.Nodes, n)
+		}
+	}
+	for _, n := range nodes {
+		add(n)
+	}
+
+	edgeSeen := make(map[[2]string]bool)
+	for _, e := range edges {
+		if e[0] == e[1] || edgeSeen[e] {
+			continue
+		}
+		edgeSeen[e] = true
+		add(e[0])
+		add(e[1])
+		g.Deps[e[0]] = append(g.Deps[e[0]], e[1])
+	}
+
+	sort.Strings(g.Nodes)
+	g.sortDeps()
+	return g
+}
@@ -0,0 +1,93 @@
+package model
+
+import "strings"
+
+// State represents the terraform state of a directory as reported by `terraform show -json`
+type State struct {
+	TerraformVersion string
+	Root             *StateModule
+	Outputs          []*StateOutput
+}
+
+// StateModule represents a module instance in the state
+type StateModule struct {
+	Address   string // "" for the root module
+	Resources []*StateResource
+	Children  []*StateModule
+}
+
+// StateResource represents a resource instance in the state
+type StateResource struct {
+	Address      string
+	Mode         string // "managed" or "data"
+	Type         string
+	Name         string
+	Index        interface{} // count index or for_each key, nil for single instances
+	ProviderName string
+	Values       map[string]interface{} // attributes with sensitive values masked
+	DependsOn    []string
+	Tainted      bool
+}
+
+// StateOutput represents a root module output
+type StateOutput struct {
+	Name      string
+	Type      interface{}
+	Value     interface{}
+	Sensitive bool
+}
+
+// SensitiveMask replaces sensitive values in the state
+const SensitiveMask = "(sensitive)"
+
+// Resources returns all resources of the state, depth first
+func (s *State) Resources() []*StateResource {
+	if s == nil || s.Root == nil {
+		return nil
+	}
+	var resources []*StateResource
+	var walk func(m *StateModule)
+	walk = func(m *StateModule) {
+		resources = append(resources, m.Resources...)
+		for _, child := range m.Children {
+			walk(child)
+		}
+	}
+	walk(s.Root)
+	return resources
+}
+
+// ModuleAddresses returns the addresses of all module instances in the state
+func (s *State) ModuleAddresses() []string {
+	if s == nil || s.Root == nil {
+		return nil
+	}
+	var addresses []string
+	var walk func(m *StateModule)
+	walk = func(m *StateModule) {
+		for _, child := range m.Children {
+			addresses = append(addresses, child.Address)
+			walk(child)
+		}
+	}
+	walk(s.Root)
+	return addresses
+}
+
+// Matches reports whether the resource matches a filter on its address or type
+func (r *StateResource) Matches(filter string) bool {
+	if filter == "" {
+		return true
+	}
+	filter = strings.ToLower(filter)
+	return strings.Contains(strings.ToLower(r.Address), filter) ||
+		strings.Contains(strings.ToLower(r.Type), filter)
+}
+
+// OutputConsumer is a reference to an output from another stack through terraform_remote_state
+type OutputConsumer struct {
+	Stack  string // directory of the consuming stack
+	Output string
+	File   string
+	Line   int
+}
@@ -3,11 +3,12 @@ package terraform
 import (
 	"encoding/json"
 	"fmt"
-	"os"
 	"os/exec"
 	"path/filepath"
-	"strings"
 	"time"
+
+	"github.com/idongju/t9s/internal/config"
+	"github.com/idongju/t9s/internal/dao"
 )
 
 // Directory represents a Terraform directory with its metadata
@@ -49,104 +50,42 @@ func (s Status) String() string {
 
 // Manager handles Terraform operations
 type Manager struct {
-	RootPath string
+	RootPath  string
+	Discovery dao.DiscoveryOptions
 }
 
-// NewManager creates a new Terraform manager
-func NewManager(rootPath string) *Manager {
+// NewManager creates a new Terraform manager that discovers stacks like the config says
+func NewManager(rootPath string, cfg *config.Config) *Manager {
 	return &Manager{
-		RootPath: rootPath,
+		RootPath:  rootPath,
+		Discovery: dao.ConfigDiscovery(cfg),
 	}
 }
 
-// ScanDirectories scans for Terraform directories
+// ScanDirectories finds the stacks below the root, recursively, leaving out reusable modules
 func (m *Manager) ScanDirectories() ([]*Directory, error) {
 	var directories []*Directory
 
-	entries, err := os.ReadDir(m.RootPath)
+	stacks, err := dao.NewTerraformDAO(m.RootPath).WithDiscovery(m.Discovery).ListDirectories()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read root directory: %w", err)
+		return nil, err
 	}
 
-	for _, entry := range entries {
-		if !entry.IsDir() {
-			continue
-		}
-
-		dirPath := filepath.Join(m.RootPath, entry.Name())
-		
-		// Check if it's a Terraform directory (has .tf files)
-		if !m.isTerraformDir(dirPath) {
-			continue
-		}
-
-		dir := &Directory{
-			Name:   entry.Name(),
-			Path:   dirPath,
-			Status: StatusUnknown,
-		}
-
-		// Find config directory and tfvars files
-		configPath := filepath.Join(dirPath, "config")
-		if stat, err := os.Stat(configPath); err == nil && stat.IsDir() {
-			dir.ConfigPath = configPath
-			dir.TfvarsFiles = m.findTfvarsFiles(configPath)
-		}
-
-		// Get backend configuration
-		m.parseBackendConfig(dir)
-
-		directories = append(directories, dir)
+	for _, stack := range stacks {
+		directories = append(directories, &Directory{
+			Name:        stack.Name,
+			Path:        stack.Path,
+			Status:      StatusUnknown,
+			ConfigPath:  stack.ConfigPath,
+			TfvarsFiles: stack.TfvarsFiles,
+			BackendType: stack.BackendType,
+			BackendKey:  stack.BackendKey,
+		})
 	}
 
 	return directories, nil
 }
 
-// isTerraformDir checks if a directory contains Terraform files
-func (m *Manager) isTerraformDir(path string) bool {
-	entries, err := os.ReadDir(path)
-	if err != nil {
-		return false
-	}
-
-	for _, entry := range entries {
-		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".tf") {
-			return true
-		}
-	}
-	return false
-}
-
-// findTfvarsFiles finds all .tfvars files in a directory
-func (m *Manager) findTfvarsFiles(path string) []string {
-	var tfvarsFiles []string
-
-	entries, err := os.ReadDir(path)
-	if err != nil {
-		return tfvarsFiles
-	}
-
-	for _, entry := range entries {
-		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".tfvars") {
-			tfvarsFiles = append(tfvarsFiles, entry.Name())
-		}
-	}
-
-	return tfvarsFiles
-}
-
-// parseBackendConfig parses the backend configuration from Terraform files
-func (m *Manager) parseBackendConfig(dir *Directory) {
-	// This is a simplified version - in production, you'd parse the actual .tf files
-	// For now, we'll check for a terraform.tfstate file or .terraform directory
-	
-	terraformDir := filepath.Join(dir.Path, ".terraform")
-	if stat, err := os.Stat(terraformDir); err == nil && stat.IsDir() {
-		dir.BackendType = "s3" // Assuming S3, should parse from backend.tf
-		dir.BackendKey = fmt.Sprintf("terraform-state/%s/terraform.tfstate", dir.Name)
-	}
-}
-
 // CheckDrift checks if there is drift between state and actual infrastructure
 func (m *Manager) CheckDrift(dir *Directory) error {
 	cmd := exec.Command("terraform", "plan", "-detailed-exitcode")
@@ -0,0 +1,163 @@
+package tfconfig
+
+import "strings"
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNewline
+	tokIdent
+	tokString // quoted string or heredoc, including delimiters
+	tokNumber
+	tokSymbol
+)
+
+type token struct {
+	kind   tokenKind
+	text   string
+	offset int
+	line   int
+}
+
+// lex splits configuration source into tokens. Comments are dropped;
+// newlines are kept because they terminate attribute expressions.
+func lex(src []byte) []token {
+	var tokens []token
+	line := 1
+	i := 0
+	emit := func(kind tokenKind, start, end int) {
+		tokens = append(tokens, token{kind: kind, text: string(src[start:end]), offset: start, line: line})
+	}
+
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == '\n':
+			emit(tokNewline, i, i+1)
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case c == '#' || (c == '/' && i+1 < len(src) && src[i+1] == '/'):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			end := strings.Index(string(src[i+2:]), "*/")
+			if end < 0 {
+				end = len(src) - i - 2
+			}
+			line += strings.Count(string(src[i:i+2+end]), "\n")
+			i += end + 4
+		case c == '"':
+			start, startLine := i, line
+			i = scanString(src, i+1, &line)
+			tokens = append(tokens, token{kind: tokString, text: string(src[start:i]), offset: start, line: startLine})
+		case c == '<' && i+2 < len(src) && src[i+1] == '<' && (isIdentStart(src[i+2]) || src[i+2] == '-'):
+			start, startLine := i, line
+			i = scanHeredoc(src, i, &line)
+			tokens = append(tokens, token{kind: tokString, text: string(src[start:i]), offset: start, line: startLine})
+		case isIdentStart(c):
+			start := i
+			for i < len(src) && isIdentPart(src[i]) {
+				i++
+			}
+			emit(tokIdent, start, i)
+		case c >= '0' && c <= '9':
+			start := i
+			for i < len(src) && (isIdentPart(src[i]) || src[i] == '.') {
+				i++
+			}
+			emit(tokNumber, start, i)
+		default:
+			// Multi-character operators are irrelevant for the structure; emit one symbol per byte
+			emit(tokSymbol, i, i+1)
+			i++
+		}
+	}
+	return tokens
+}
+
+// scanString returns the offset after the closing quote of a string starting at i,
+// skipping escapes and nested template interpolations
+func scanString(src []byte, i int, line *int) int {
+	for i < len(src) {
+		switch src[i] {
+		case '\\':
+			i += 2
+			continue
+		case '"':
+			return i + 1
+		case '\n':
+			*line++
+		case '$', '%':
+			if i+1 < len(src) && src[i+1] == '{' {
+				i = scanTemplate(src, i+2, line)
+				continue
+			}
+		}
+		i++
+	}
+	return len(src)
+}
+
+// scanTemplate returns the offset after the "}" closing a template interpolation
+func scanTemplate(src []byte, i int, line *int) int {
+	depth := 1
+	for i < len(src) {
+		switch src[i] {
+		case '"':
+			i = scanString(src, i+1, line)
+			continue
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		case '\n':
+			*line++
+		}
+		i++
+	}
+	return len(src)
+}
+
+// scanHeredoc returns the offset after the closing marker of a heredoc starting at i
+func scanHeredoc(src []byte, i int, line *int) int {
+	i += 2
+	if src[i] == '-' {
+		i++
+	}
+	start := i
+	for i < len(src) && isIdentPart(src[i]) {
+		i++
+	}
+	marker := string(src[start:i])
+	for i < len(src) {
+		if src[i] == '\n' {
+			*line++
+			i++
+			lineEnd := i
+			for lineEnd < len(src) && src[lineEnd] != '\n' {
+				lineEnd++
+			}
+			if strings.TrimSpace(string(src[i:lineEnd])) == marker {
+				return lineEnd
+			}
+			continue
+		}
+		i++
+	}
+	return len(src)
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || c == '-' || (c >= '0' && c <= '9')
+}
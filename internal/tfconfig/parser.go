@@ -0,0 +1,323 @@
+// Package tfconfig reads the structure of Terraform configuration files without
+// evaluating them: blocks, their labels and attribute expressions.
+package tfconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Block is a configuration block such as `resource "aws_s3_bucket" "logs" { ... }`
+type Block struct {
+	Type   string
+	Labels []string
+	Attrs  map[string]*Attribute
+	Blocks []*Block
+	File   string
+	Line   int // line of the block header, 1-based
+}
+
+// Attribute is an attribute assignment inside a block
+type Attribute struct {
+	Name string
+	Expr string // expression source text
+	Line int
+}
+
+// Module is the parsed configuration of a directory
+type Module struct {
+	Dir    string
+	Files  []string // parsed .tf files
+	Blocks []*Block // top-level blocks of all .tf files, in file order
+}
+
+// String returns the literal value of a string attribute, and false for any other expression
+func (a *Attribute) String() (string, bool) {
+	if a == nil {
+		return "", false
+	}
+	expr := strings.TrimSpace(a.Expr)
+	if len(expr) < 2 || expr[0] != '"' || expr[len(expr)-1] != '"' || strings.Contains(expr, "${") {
+		return "", false
+	}
+	return unquote(expr[1 : len(expr)-1]), true
+}
+
+// Object returns the literal string values of an object expression such as
+// `{ bucket = "state", key = "network.tfstate" }`; other values are left out
+func (a *Attribute) Object() map[string]string {
+	values := make(map[string]string)
+	if a == nil {
+		return values
+	}
+	tokens := lex([]byte(strings.TrimSpace(a.Expr)))
+	for i := 0; i+2 < len(tokens); i++ {
+		key, sep, value := tokens[i], tokens[i+1], tokens[i+2]
+		if key.kind != tokIdent && key.kind != tokString {
+			continue
+		}
+		if sep.kind != tokSymbol || (sep.text != "=" && sep.text != ":") {
+			continue
+		}
+		name := key.text
+		if key.kind == tokString {
+			name = unquote(name[1 : len(name)-1])
+		}
+		if str, ok := (&Attribute{Expr: value.text}).String(); ok && value.kind == tokString {
+			values[name] = str
+		}
+	}
+	return values
+}
+
+// Attr returns the literal string value of a block attribute, or "" if it is not a literal
+func (b *Block) Attr(name string) string {
+	value, _ := b.Attrs[name].String()
+	return value
+}
+
+// Child returns the first nested block of the given type
+func (b *Block) Child(blockType string) *Block {
+	for _, child := range b.Blocks {
+		if child.Type == blockType {
+			return child
+		}
+	}
+	return nil
+}
+
+// Address returns the reference address of a top-level block, e.g. "aws_s3_bucket.logs",
+// "data.aws_iam_policy.x", "module.vpc", "var.region", "local.name" or "output.id"
+func (b *Block) Address() string {
+	switch b.Type {
+	case "resource":
+		if len(b.Labels) == 2 {
+			return b.Labels[0] + "." + b.Labels[1]
+		}
+	case "data":
+		if len(b.Labels) == 2 {
+			return "data." + b.Labels[0] + "." + b.Labels[1]
+		}
+	case "module":
+		if len(b.Labels) == 1 {
+			return "module." + b.Labels[0]
+		}
+	case "variable":
+		if len(b.Labels) == 1 {
+			return "var." + b.Labels[0]
+		}
+	case "output":
+		if len(b.Labels) == 1 {
+			return "output." + b.Labels[0]
+		}
+	}
+	return ""
+}
+
+// Find returns the top-level blocks of a type with the given leading labels
+func (m *Module) Find(blockType string, labels ...string) []*Block {
+	var blocks []*Block
+	for _, b := range m.Blocks {
+		if b.Type != blockType || len(b.Labels) < len(labels) {
+			continue
+		}
+		match := true
+		for i, label := range labels {
+			if b.Labels[i] != label {
+				match = false
+				break
+			}
+		}
+		if match {
+			blocks = append(blocks, b)
+		}
+	}
+	return blocks
+}
+
+// ParseDir parses all .tf files of a directory (not recursively)
+func ParseDir(dir string) (*Module, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	module := &Module{Dir: dir}
+	for _, file := range files {
+		blocks, err := ParseFile(file)
+		if err != nil {
+			return nil, err
+		}
+		module.Files = append(module.Files, file)
+		module.Blocks = append(module.Blocks, blocks...)
+	}
+	return module, nil
+}
+
+// ParseFile parses the top-level blocks of a single file
+func ParseFile(path string) ([]*Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return Parse(path, data)
+}
+
+// Parse parses configuration source. Syntax errors are reported with file and line.
+func Parse(file string, src []byte) ([]*Block, error) {
+	p := &parser{file: file, tokens: lex(src), src: src}
+	body, err := p.body(false)
+	if err != nil {
+		return nil, err
+	}
+	return body.Blocks, nil
+}
+
+type parser struct {
+	file   string
+	src    []byte
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	if p.pos >= len(p.tokens) {
+		return token{kind: tokEOF}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.peek()
+	if p.pos < len(p.tokens) {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) errorf(tok token, format string, args ...interface{}) error {
+	return fmt.Errorf("%s:%d: %s", p.file, tok.line, fmt.Sprintf(format, args...))
+}
+
+// body parses attributes and blocks until EOF, or until "}" when nested
+func (p *parser) body(nested bool) (*Block, error) {
+	block := &Block{Attrs: make(map[string]*Attribute)}
+	for {
+		tok := p.next()
+		switch {
+		case tok.kind == tokNewline:
+			continue
+		case tok.kind == tokEOF:
+			if nested {
+				return nil, p.errorf(tok, "unexpected end of file, missing }")
+			}
+			return block, nil
+		case tok.kind == tokSymbol && tok.text == "}":
+			if !nested {
+				return nil, p.errorf(tok, "unexpected }")
+			}
+			return block, nil
+		case tok.kind != tokIdent:
+			return nil, p.errorf(tok, "expected attribute or block, found %q", tok.text)
+		}
+
+		name := tok
+		if after := p.peek(); after.kind == tokSymbol && after.text == "=" {
+			p.next()
+			expr, err := p.expression()
+			if err != nil {
+				return nil, err
+			}
+			block.Attrs[name.text] = &Attribute{Name: name.text, Expr: expr, Line: name.line}
+			continue
+		}
+
+		child := &Block{Type: name.text, File: p.file, Line: name.line}
+		for {
+			label := p.next()
+			if label.kind == tokString {
+				child.Labels = append(child.Labels, unquote(label.text[1:len(label.text)-1]))
+				continue
+			}
+			if label.kind == tokIdent {
+				child.Labels = append(child.Labels, label.text)
+				continue
+			}
+			if label.kind == tokSymbol && label.text == "{" {
+				break
+			}
+			return nil, p.errorf(label, "expected block label or {, found %q", label.text)
+		}
+		inner, err := p.body(true)
+		if err != nil {
+			return nil, err
+		}
+		child.Attrs = inner.Attrs
+		child.Blocks = inner.Blocks
+		block.Blocks = append(block.Blocks, child)
+	}
+}
+
+// expression consumes tokens up to the end of the line at bracket depth zero
+// and returns the source text in between
+func (p *parser) expression() (string, error) {
+	start := p.peek()
+	depth := 0
+	end := start.offset
+	for {
+		tok := p.peek()
+		if tok.kind == tokEOF {
+			if depth > 0 {
+				return "", p.errorf(start, "unclosed bracket in expression")
+			}
+			break
+		}
+		if depth == 0 && (tok.kind == tokNewline || (tok.kind == tokSymbol && tok.text == "}")) {
+			break
+		}
+		p.next()
+		if tok.kind == tokSymbol {
+			switch tok.text {
+			case "(", "[", "{":
+				depth++
+			case ")", "]", "}":
+				depth--
+			}
+		}
+		end = tok.offset + len(tok.text)
+	}
+	if end < start.offset {
+		return "", nil
+	}
+	return strings.TrimSpace(string(p.src[start.offset:end])), nil
+}
+
+// unquote resolves the escapes of a string literal body
+func unquote(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,101 @@
+package tfconfig
+
+import (
+	"bufio"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Reference is a use of an input variable, local value or module in a file,
+// e.g. var.region, local.name or module.vpc
+type Reference struct {
+	Address string
+	Line    int
+}
+
+// Definition is where a referenced variable, local value or module is declared
+type Definition struct {
+	Address string
+	File    string
+	Line    int
+}
+
+var referencePattern = regexp.MustCompile(`\b(var|local|module)\.([A-Za-z_][A-Za-z0-9_-]*)`)
+
+// References returns the var., local. and module. references of a configuration file in
+// order of appearance, once per line. Comment lines are skipped.
+func References(path string) ([]Reference, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var refs []Reference
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(text, "#") || strings.HasPrefix(text, "//") {
+			continue
+		}
+		seen := make(map[string]bool)
+		for _, match := range referencePattern.FindAllStringSubmatch(text, -1) {
+			address := match[1] + "." + match[2]
+			if !seen[address] {
+				seen[address] = true
+				refs = append(refs, Reference{Address: address, Line: line})
+			}
+		}
+	}
+	return refs, scanner.Err()
+}
+
+// Assignments returns the variables set by a tfvars file as var. references, in line order
+func Assignments(path string) ([]Reference, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{file: path, tokens: lex(data), src: data}
+	body, err := p.body(false)
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []Reference
+	for name, attr := range body.Attrs {
+		refs = append(refs, Reference{Address: "var." + name, Line: attr.Line})
+	}
+	sort.Slice(refs, func(i, j int) bool { return refs[i].Line < refs[j].Line })
+	return refs, nil
+}
+
+// Definition returns where a var., local. or module. address is declared in the module,
+// or nil. Longer traversals such as module.vpc.vpc_id resolve to their first two parts.
+func (m *Module) Definition(address string) *Definition {
+	parts := strings.SplitN(address, ".", 3)
+	if len(parts) < 2 {
+		return nil
+	}
+	name := parts[0] + "." + parts[1]
+
+	switch parts[0] {
+	case "var":
+		if blocks := m.Find("variable", parts[1]); len(blocks) > 0 {
+			return &Definition{Address: name, File: blocks[0].File, Line: blocks[0].Line}
+		}
+	case "module":
+		if blocks := m.Find("module", parts[1]); len(blocks) > 0 {
+			return &Definition{Address: name, File: blocks[0].File, Line: blocks[0].Line}
+		}
+	case "local":
+		for _, b := range m.Find("locals") {
+			if attr, ok := b.Attrs[parts[1]]; ok {
+				return &Definition{Address: name, File: b.File, Line: attr.Line}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package tfconfig
+
+import (
+	"bufio"
+	"os"
+	"regexp"
+)
+
+// RemoteState is a `data "terraform_remote_state"` block
+type RemoteState struct {
+	Name    string
+	Backend string
+	Config  map[string]string // literal string values of the config argument
+	File    string
+	Line    int
+}
+
+// OutputRef is a reference to an output of a remote state,
+// e.g. data.terraform_remote_state.vpc.outputs.subnet_id
+type OutputRef struct {
+	RemoteState string
+	Output      string
+	File        string
+	Line        int
+}
+
+var outputRefPattern = regexp.MustCompile(`data\.terraform_remote_state\.([A-Za-z0-9_-]+)\.outputs\.([A-Za-z0-9_-]+)`)
+
+// RemoteStates returns the terraform_remote_state data sources of the module
+func (m *Module) RemoteStates() []RemoteState {
+	var states []RemoteState
+	for _, b := range m.Find("data", "terraform_remote_state") {
+		if len(b.Labels) != 2 {
+			continue
+		}
+		states = append(states, RemoteState{
+			Name:    b.Labels[1],
+			Backend: b.Attr("backend"),
+			Config:  b.Attrs["config"].Object(),
+			File:    b.File,
+			Line:    b.Line,
+		})
+	}
+	return states
+}
+
+// OutputRefs returns every reference to a remote state output in the module files
+func (m *Module) OutputRefs() []OutputRef {
+	var refs []OutputRef
+	for _, file := range m.Files {
+		f, err := os.Open(file)
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(f)
+		for line := 1; scanner.Scan(); line++ {
+			for _, match := range outputRefPattern.FindAllStringSubmatch(scanner.Text(), -1) {
+				refs = append(refs, OutputRef{RemoteState: match[1], Output: match[2], File: file, Line: line})
+			}
+		}
+		f.Close()
+	}
+	return refs
+}
+
+// Backend returns the backend type and its literal settings from the terraform block
+func (m *Module) Backend() (string, map[string]string) {
+	for _, tf := range m.Find("terraform") {
+		backend := tf.Child("backend")
+		if backend == nil || len(backend.Labels) == 0 {
+			continue
+		}
+		config := make(map[string]string)
+		for name := range backend.Attrs {
+			if value := backend.Attr(name); value != "" {
+				config[name] = value
+			}
+		}
+		return backend.Labels[0], config
+	}
+	return "", nil
+}
@@ -8,13 +8,17 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/idongju/t9s/internal/config"
+	"github.com/idongju/t9s/internal/dao"
 	"github.com/idongju/t9s/internal/db"
 	"github.com/idongju/t9s/internal/git"
+	"github.com/idongju/t9s/internal/model"
 	"github.com/idongju/t9s/internal/ui/components"
 	"github.com/idongju/t9s/internal/ui/dialog"
 	"github.com/idongju/t9s/internal/view"
@@ -34,17 +38,50 @@ type AppNew struct {
 	helpView    *view.HelpView
 	commandView *view.CommandView
 	historyView *view.HistoryView
+	searchBar   *view.SearchBar
+	contentPane *tview.Flex // content view with the search bar below it
+	toastBar    *view.ToastBar
+	mainLayout  *tview.Flex // main page, to show and hide the toast bar
 
 	// Components
-	executor   *components.CommandExecutor
-	historyDB  *db.HistoryDB
-	gitManager *git.Manager
+	executor    *components.CommandExecutor
+	historyDB   *db.HistoryDB
+	historyJobs *sync.WaitGroup // background jobs using historyDB, waited for before it is closed
+	gitManager  *git.Manager
+	watcher     *dao.Watcher
 
 	// State
 	currentDir  string
 	currentFile string
 	config      *config.Config
 	focusOnTree bool // true if tree is focused, false if content is focused
+	keymap      *config.Keymap
+
+	// Workspace of the selected stack, fetched in the background
+	workspaceDir string
+	workspaces   map[string]string // cached workspace per stack directory
+
+	// Stacks whose state has been loaded, keyed by directory
+	stacks map[string]*model.TerraformDirectory
+
+	// Live progress of the last apply run with -json
+	progressView *view.ProgressView
+
+	// Errors and warnings of the last terraform run, shown in the diagnostics panel
+	diagnostics    []*model.Diagnostic
+	diagnosticsDir string
+
+	// Last terraform result per stack directory, shown in the status bar and header
+	results map[string]*components.RunResult
+
+	// Last plan computed by t9s per stack directory, offered in the target picker
+	plans map[string]*model.Plan
+
+	// Finished background jobs with their output, oldest first; the last unopened ones are toasted
+	jobs     []*components.RunResult
+	unopened int
+	toastJob *components.RunResult // job shown in the toast, opened by clicking it
+	toastSeq int                   // incremented for each toast, so that only the latest one hides itself
 }
 
 // NewAppNew creates a new T9s application with improved structure
@@ -67,14 +104,7 @@ func NewAppNew() *AppNew {
 		}
 		cfg = &config.Config{
 			TerraformRoot: currentDir,
-			Commands: config.CommandsConfig{
-				InitTemplate:    "terraform init -backend-config={initconf}",
-				PlanTemplate:    "terraform plan -var-file={varfile}",
-				ApplyTemplate:   "terraform apply -var-file={varfile}",
-				DestroyTemplate: "terraform destroy -var-file={varfile}",
-				TfvarsFile:      "config/env.tfvars",
-				InitConfFile:    "config/env.conf",
-			},
+			Commands:      config.DefaultCommands(),
 		}
 	}
 
@@ -88,7 +118,11 @@ func NewAppNew() *AppNew {
 	}
 
 	// Initialize history DB in terraform root directory (shared by all users)
-	historyDB, err := db.NewHistoryDB(currentDir)
+	historyDir := cfg.HistoryDir()
+	if historyDir == "" {
+		historyDir = currentDir
+	}
+	historyDB, err := db.NewHistoryDB(historyDir)
 	if err != nil {
 		// Fallback if DB fails - app still works
 		fmt.Fprintf(os.Stderr, "Warning: Failed to initialize history DB: %v\n", err)
@@ -97,6 +131,9 @@ func NewAppNew() *AppNew {
 	// Initialize git manager
 	gitManager := git.NewManager()
 
+	// Key bindings of ~/.t9s/keys.yaml; the defaults are used if it is invalid
+	keymap, keymapErr := config.LoadKeymap()
+
 	app := &AppNew{
 		tviewApp:    tview.NewApplication(),
 		currentDir:  currentDir,
@@ -104,11 +141,23 @@ func NewAppNew() *AppNew {
 		pages:       tview.NewPages(),
 		gitManager:  gitManager,
 		historyDB:   historyDB,
+		historyJobs: &sync.WaitGroup{},
 		focusOnTree: true, // Start with tree focused
+		keymap:      keymap,
+		workspaces:  make(map[string]string),
+		stacks:      make(map[string]*model.TerraformDirectory),
+		results:     make(map[string]*components.RunResult),
+		plans:       make(map[string]*model.Plan),
 	}
 
 	app.setupViews()
 	app.setupKeyBindings()
+	app.setupMouse()
+	if keymapErr != nil {
+		app.statusBar.ShowMessage(fmt.Sprintf("[red]Using the default keys: %s[white]", tview.Escape(keymapErr.Error())))
+	}
+	app.startWatcher()
+	app.refreshTreeBadges()
 
 	return app
 }
@@ -123,66 +172,89 @@ func (a *AppNew) setupViews() {
 		a.headerView.SetGitBranch(status.Branch, status.IsDirty)
 	}
 
-	a.treeView = view.NewTreeView(a.currentDir)
+	a.headerView.SetContext(a.config.CurrentContext)
+	a.headerView.SetKeymap(a.keymap)
+
+	a.treeView = a.newTreeView()
 	a.contentView = view.NewContentView()
+	a.contentView.SetKeymap(a.keymap)
+	a.contentView.ShowWelcome()
 	a.statusBar = view.NewStatusBar(a.currentDir)
 
 	// Create executor
 	a.executor = components.NewCommandExecutor(a.tviewApp, a.contentView, a.config, a.historyDB)
 
-	// Setup tree view handler
-	a.treeView.SetFileSelectHandler(func(path string) {
-		a.currentFile = path
-		a.contentView.DisplayFile(path)
+	// Content search bar, hidden until / is pressed in the content view
+	a.searchBar = view.NewSearchBar()
+	a.contentPane = tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(a.contentView, 0, 1, false).
+		AddItem(a.searchBar, 0, 0, false)
+	a.setupContentSearch()
+
+	// Notification of finished background jobs, hidden until one finishes
+	a.toastBar = view.NewToastBar()
+	a.toastBar.SetKeymap(a.keymap)
+	a.toastBar.SetClickedFunc(func() {
+		if a.toastJob != nil {
+			a.openJob(a.toastJob)
+		}
 	})
 
-	// Setup tree view change handler
-	a.treeView.SetChangedFunc(func(node *tview.TreeNode) {
-		reference := node.GetReference()
-		if reference != nil {
-			path := reference.(string)
-			a.statusBar.UpdatePath(path)
+	a.rebuildMainPage()
+	a.tviewApp.SetRoot(a.pages, true)
+}
 
-			// Check if it's a directory
-			info, err := os.Stat(path)
-			if err == nil && info.IsDir() {
-				// Show welcome screen when moving to a directory
-				a.contentView.ShowWelcome()
-			}
+// setupMouse enables the mouse and keeps the focus state in sync when the tree or
+// content view is clicked
+func (a *AppNew) setupMouse() {
+	a.tviewApp.EnableMouse(true)
+	a.tviewApp.SetMouseCapture(func(event *tcell.EventMouse, action tview.MouseAction) (*tcell.EventMouse, tview.MouseAction) {
+		if action != tview.MouseLeftClick {
+			return event, action
+		}
+		if currentPage, _ := a.pages.GetFrontPage(); currentPage != "main" {
+			return event, action
+		}
+		x, y := event.Position()
+		switch {
+		case a.treeView.InRect(x, y) && !a.focusOnTree:
+			a.focusOnTree = true
+			a.statusBar.SetFocusIndicator("File Tree")
+		case a.contentView.InRect(x, y) && a.focusOnTree:
+			a.focusOnTree = false
+			a.statusBar.SetFocusIndicator("Content View")
 		}
+		return event, action
 	})
-
-	// Main content layout
-	mainFlex := tview.NewFlex().
-		AddItem(a.treeView, 0, 1, true).
-		AddItem(a.contentView, 0, 2, false)
-	mainFlex.SetBackgroundColor(tcell.ColorBlack)
-
-	// Root layout
-	mainPage := tview.NewFlex().
-		SetDirection(tview.FlexRow).
-		AddItem(a.headerView, 7, 0, false).
-		AddItem(mainFlex, 0, 1, true).
-		AddItem(a.statusBar, 1, 0, false)
-	mainPage.SetBackgroundColor(tcell.ColorBlack)
-
-	a.pages.AddPage("main", mainPage, true, true)
-	a.tviewApp.SetRoot(a.pages, true)
 }
 
-// setupKeyBindings sets up global key bindings
+// setupKeyBindings sets up global key bindings from the keymap
 func (a *AppNew) setupKeyBindings() {
 	a.tviewApp.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		// Get current page name
 		currentPage, _ := a.pages.GetFrontPage()
 
-		// Only handle global shortcuts when on main page
-		if currentPage != "main" {
+		// Only handle global shortcuts when on main page, and not while typing a search
+		if currentPage != "main" || a.tviewApp.GetFocus() == a.searchBar.GetInput() {
 			return event
 		}
 
-		switch event.Key() {
-		case tcell.KeyTab:
+		scope := config.ScopeContent
+		if a.focusOnTree {
+			scope = config.ScopeTree
+		}
+
+		switch action := a.keymap.Lookup(view.KeyName(event), scope); action {
+		case config.ActionClearSearch:
+			// Clear the search highlights in the content view
+			if !a.contentView.Searching() {
+				return event
+			}
+			a.contentView.ClearSearch()
+			a.statusBar.ShowDefault()
+			return nil
+		case config.ActionFocus:
 			// Toggle focus between tree and content view
 			a.focusOnTree = !a.focusOnTree
 			if a.focusOnTree {
@@ -193,87 +265,310 @@ func (a *AppNew) setupKeyBindings() {
 				a.statusBar.SetFocusIndicator("Content View")
 			}
 			return nil
-		case tcell.KeyCtrlC:
+		case config.ActionQuit:
 			a.tviewApp.Stop()
 			return nil
-		case tcell.KeyRune:
-			switch event.Rune() {
-			case 'q', 'Q':
-				a.tviewApp.Stop()
-				return nil
-			case 's', 'S':
-				a.showSettings()
-				return nil
-			case 'h':
-				path := a.treeView.GetCurrentPath()
-				if path != "" {
-					a.showHistory(path)
-				}
-				return nil
-			case 'H':
-				// Shift+H: Show help
-				a.showHelp()
-				return nil
-			case '?':
-				// ?: Also show help
-				a.showHelp()
-				return nil
-			case '/':
-				// /: Command mode
-				a.showCommandInput()
-				return nil
-			case 'C':
-				// Shift+C: Show available commands
-				a.contentView.ShowWelcome()
-				return nil
-			case 'B':
-				// Shift+B: Branch switch
-				path := a.treeView.GetCurrentPath()
-				if path != "" {
-					a.showBranchSwitch(path)
-				}
-				return nil
-			case 'e', 'E':
-				if a.currentFile != "" {
-					a.executor.EditFile(a.currentFile)
-					a.contentView.DisplayFile(a.currentFile)
-				}
+		case config.ActionFinder:
+			// Fuzzy finder
+			a.showFinder()
+			return nil
+		case config.ActionDefinition:
+			// Jump to the definition of a reference in the file
+			if file := a.contentView.CurrentFile(); strings.HasSuffix(file, ".tf") || strings.HasSuffix(file, ".tfvars") {
+				a.showDefinitions(file)
 				return nil
-			case 'i', 'I':
-				// i: Terraform init
-				path := a.treeView.GetCurrentPath()
-				if path != "" {
-					a.showInitConfirmation(path)
-				}
+			}
+			// On plan output: fold or unfold the resource block on screen
+			if a.contentView.Folding() {
+				a.contentView.ToggleFold()
+				a.showFoldStatus()
 				return nil
-			case 'p':
-				// p: Terraform plan
-				path := a.treeView.GetCurrentPath()
-				if path != "" {
-					a.showPlanConfirmation(path)
+			}
+			return event
+		case config.ActionSettings:
+			a.showSettings()
+			return nil
+		case config.ActionContext:
+			// Context switch
+			a.showContextSwitch()
+			return nil
+		case config.ActionState:
+			// State browser
+			path := a.treeView.GetCurrentPath()
+			if path != "" {
+				a.showState(path)
+			}
+			return nil
+		case config.ActionMark:
+			// Mark the selected directory for batch operations
+			a.treeView.ToggleMark()
+			return nil
+		case config.ActionBatch:
+			// Batch init/plan/validate across marked stacks
+			stacks := a.treeView.Marked()
+			if len(stacks) == 0 {
+				if path := a.treeView.GetCurrentPath(); path != "" {
+					stacks = []string{path}
 				}
+			}
+			a.showBatchDialog(stacks)
+			return nil
+		case config.ActionStacksOnly:
+			// List only stacks and their tfvars
+			a.toggleStacksOnly()
+			return nil
+		case config.ActionDashboard:
+			// Stack dashboard with dependency graph
+			a.showStackDashboard()
+			return nil
+		case config.ActionGraph:
+			// Resource dependency graph
+			path := a.treeView.GetCurrentPath()
+			if path != "" {
+				a.showGraph(path)
+			}
+			return nil
+		case config.ActionOutputs:
+			// Outputs viewer
+			path := a.treeView.GetCurrentPath()
+			if path != "" {
+				a.showOutputs(path)
+			}
+			return nil
+		case config.ActionMoved:
+			// Suggest moved blocks from a plan
+			path := a.treeView.GetCurrentPath()
+			if path != "" {
+				a.showMovedSuggestions(path)
+			}
+			return nil
+		case config.ActionTarget:
+			// Targeted plan/apply
+			path := a.treeView.GetCurrentPath()
+			if path != "" {
+				a.showTargetPicker(path)
+			}
+			return nil
+		case config.ActionWorkspaces:
+			// Workspace panel
+			path := a.treeView.GetCurrentPath()
+			if path != "" {
+				a.showWorkspaces(path)
+			}
+			return nil
+		case config.ActionHistory:
+			path := a.treeView.GetCurrentPath()
+			if path != "" {
+				a.showHistory(path)
+			}
+			return nil
+		case config.ActionHelp:
+			a.showHelp()
+			return nil
+		case config.ActionSearch:
+			// Search the content view
+			a.showContentSearch()
+			return nil
+		case config.ActionCommand:
+			a.showCommandInput()
+			return nil
+		case config.ActionWrap:
+			// Cycle soft wrap, hard wrap and no wrap in the content view
+			mode := a.contentView.CycleWrap()
+			if !a.contentView.Searching() {
+				// Redisplay the file so its footer shows the new mode
+				a.contentView.ReloadFile()
+			}
+			a.statusBar.ShowMessage(fmt.Sprintf("[green]Content view: %s[white]", mode))
+			return nil
+		case config.ActionFoldAll, config.ActionFoldDestructive, config.ActionPrevBlock, config.ActionNextBlock, config.ActionUnchanged:
+			// Plan output folding in the content view
+			if a.contentView.FoldPlan() == 0 {
+				a.statusBar.ShowMessage("[yellow]No resource blocks to fold in the content view[white]")
 				return nil
-			case 'a':
-				// a: Terraform apply
-				a.showApplyConfirmation()
-				return nil
-			case 'd', 'D':
-				// If focus is on content view, let it handle 'd' (scroll down)
-				if !a.focusOnTree {
-					return event
-				}
-				// d: Terraform destroy
-				path := a.treeView.GetCurrentPath()
-				if path != "" {
-					a.showDestroyConfirmation(path)
-				}
+			}
+			switch action {
+			case config.ActionFoldAll:
+				a.contentView.FoldAll()
+			case config.ActionFoldDestructive:
+				a.contentView.FoldExceptDestructive()
+			case config.ActionPrevBlock:
+				a.contentView.PrevBlock()
+			case config.ActionNextBlock:
+				a.contentView.NextBlock()
+			case config.ActionUnchanged:
+				a.contentView.ToggleUnchanged()
+			}
+			a.showFoldStatus()
+			return nil
+		case config.ActionSearchNext, config.ActionSearchPrev:
+			// Next/previous search match in the content view
+			if !a.contentView.Searching() {
+				return event
+			}
+			if action == config.ActionSearchNext {
+				a.contentView.NextMatch()
+			} else {
+				a.contentView.PrevMatch()
+			}
+			a.showSearchStatus()
+			return nil
+		case config.ActionProgress:
+			// Progress of the last apply run with -json
+			if a.progressView == nil {
+				a.statusBar.ShowMessage("[yellow]No apply has run with -json progress yet (defaults.json_progress)[white]")
 				return nil
 			}
+			a.showProgress()
+			return nil
+		case config.ActionDiagnostics:
+			// Diagnostics of the last terraform run
+			a.showDiagnostics()
+			return nil
+		case config.ActionJobs:
+			// Output of finished background jobs
+			a.showJobs()
+			return nil
+		case config.ActionHome:
+			// Show available commands
+			a.contentView.ShowWelcome()
+			return nil
+		case config.ActionBranch:
+			// Branch switch
+			path := a.treeView.GetCurrentPath()
+			if path != "" {
+				a.showBranchSwitch(path)
+			}
+			return nil
+		case config.ActionEdit:
+			if a.currentFile != "" {
+				a.executor.EditFile(a.currentFile, 0)
+				a.contentView.DisplayFile(a.currentFile)
+			}
+			return nil
+		case config.ActionInit:
+			// Terraform init
+			path := a.treeView.GetCurrentPath()
+			if path != "" {
+				a.showInitConfirmation(path)
+			}
+			return nil
+		case config.ActionPlan:
+			// Terraform plan
+			path := a.treeView.GetCurrentPath()
+			if path != "" {
+				a.showPlanConfirmation(path)
+			}
+			return nil
+		case config.ActionApply:
+			// Terraform apply
+			a.showApplyConfirmation()
+			return nil
+		case config.ActionRefresh:
+			// Terraform apply -refresh-only
+			path := a.treeView.GetCurrentPath()
+			if path != "" {
+				a.showRefreshConfirmation(path)
+			}
+			return nil
+		case config.ActionDestroy:
+			// Terraform destroy, only bound while the tree is focused
+			path := a.treeView.GetCurrentPath()
+			if path != "" {
+				a.showDestroyConfirmation(path)
+			}
+			return nil
+		}
+		// Fast scrolling is handled by the content view itself
+		return event
+	})
+}
+
+// setupContentSearch wires the search bar to the content view: typing searches
+// incrementally, Enter keeps the highlights and Esc clears them
+func (a *AppNew) setupContentSearch() {
+	input := a.searchBar.GetInput()
+	search := func() {
+		caseSensitive, regex := a.searchBar.Options()
+		_, err := a.contentView.Search(input.GetText(), caseSensitive, regex)
+		current, total := a.contentView.SearchStatus()
+		a.searchBar.SetStatus(current, total, err)
+	}
+	input.SetChangedFunc(func(text string) {
+		search()
+	})
+
+	input.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			a.contentView.ClearSearch()
+			a.hideContentSearch()
+			a.statusBar.ShowDefault()
+			return nil
+		case tcell.KeyEnter:
+			a.hideContentSearch()
+			a.showSearchStatus()
+			return nil
+		case tcell.KeyDown, tcell.KeyCtrlN:
+			a.contentView.NextMatch()
+			current, total := a.contentView.SearchStatus()
+			a.searchBar.SetStatus(current, total, nil)
+			return nil
+		case tcell.KeyUp, tcell.KeyCtrlP:
+			a.contentView.PrevMatch()
+			current, total := a.contentView.SearchStatus()
+			a.searchBar.SetStatus(current, total, nil)
+			return nil
+		case tcell.KeyCtrlT:
+			a.searchBar.ToggleCase()
+			search()
+			return nil
+		case tcell.KeyCtrlR:
+			a.searchBar.ToggleRegex()
+			search()
+			return nil
 		}
 		return event
 	})
 }
 
+// showContentSearch opens the search bar below the content view
+func (a *AppNew) showContentSearch() {
+	a.contentPane.ResizeItem(a.searchBar, 1, 0)
+	input := a.searchBar.GetInput()
+	if input.GetText() != "" {
+		// Search the current content again with the previous query
+		input.SetText(input.GetText())
+	}
+	a.tviewApp.SetFocus(input)
+}
+
+// hideContentSearch closes the search bar and returns focus to the content view
+func (a *AppNew) hideContentSearch() {
+	a.contentPane.ResizeItem(a.searchBar, 0, 0)
+	a.focusOnTree = false
+	a.tviewApp.SetFocus(a.contentView)
+}
+
+// showSearchStatus shows the current match in the status bar
+func (a *AppNew) showSearchStatus() {
+	if !a.contentView.Searching() {
+		return
+	}
+	current, total := a.contentView.SearchStatus()
+	if total == 0 {
+		a.statusBar.ShowMessage("[red]No matches[white]  [yellow]Esc[white] Clear")
+		return
+	}
+	a.statusBar.ShowMessage(fmt.Sprintf("[green]Match %d/%d[white]  [yellow]n/N[white] Next/Previous  [yellow]/[white] Edit Search  [yellow]Esc[white] Clear", current, total))
+}
+
+// showFoldStatus shows the folding state of plan output with the folding keys
+func (a *AppNew) showFoldStatus() {
+	a.statusBar.ShowMessage(fmt.Sprintf("[green]%s[white]  [yellow]Enter[white] Fold  [yellow]z[white] All  [yellow]Z[white] Destructive  [yellow][[white]/[yellow]][white] Jump  [yellow]~[white] Unchanged",
+		tview.Escape(a.contentView.FoldStatus())))
+}
+
 // showSettings displays the settings dialog
 func (a *AppNew) showSettings() {
 	settingsDialog := dialog.NewSettingsDialog(
@@ -281,32 +576,7 @@ func (a *AppNew) showSettings() {
 		func() {
 			// Check if TerraformRoot changed
 			if a.config.TerraformRoot != a.currentDir {
-				// Rebuild tree view with new root directory
-				a.currentDir = a.config.TerraformRoot
-				a.treeView = view.NewTreeView(a.currentDir)
-				a.treeView.SetFileSelectHandler(func(path string) {
-					a.currentFile = path
-					a.contentView.DisplayFile(path)
-				})
-				a.treeView.SetChangedFunc(func(node *tview.TreeNode) {
-					reference := node.GetReference()
-					if reference != nil {
-						a.statusBar.UpdatePath(reference.(string))
-					}
-				})
-
-				// Rebuild header and status bar
-				a.headerView = view.NewHeaderView(a.currentDir)
-
-				// Update git branch info in header
-				if status, err := a.gitManager.GetStatus(a.currentDir); err == nil {
-					a.headerView.SetGitBranch(status.Branch, status.IsDirty)
-				}
-
-				a.statusBar = view.NewStatusBar(a.currentDir)
-
-				// Rebuild main layout
-				a.rebuildMainPage()
+				a.reloadRoot()
 			}
 			a.pages.SwitchToPage("main")
 			a.tviewApp.SetFocus(a.treeView)
@@ -326,264 +596,2104 @@ func (a *AppNew) showSettings() {
 	a.tviewApp.SetFocus(settingsDialog.GetForm())
 }
 
-// rebuildMainPage rebuilds the main page with updated views
-func (a *AppNew) rebuildMainPage() {
-	// Main content layout
-	mainFlex := tview.NewFlex().
-		AddItem(a.treeView, 0, 1, true).
-		AddItem(a.contentView, 0, 2, false)
-	mainFlex.SetBackgroundColor(tcell.ColorBlack)
+// newTreeView creates a tree view for the current root with the app handlers attached
+func (a *AppNew) newTreeView() *view.TreeView {
+	treeView := view.NewTreeView(a.currentDir)
 
-	// Root layout
-	mainPage := tview.NewFlex().
-		SetDirection(tview.FlexRow).
-		AddItem(a.headerView, 7, 0, false).
-		AddItem(mainFlex, 0, 1, true).
-		AddItem(a.statusBar, 1, 0, false)
-	mainPage.SetBackgroundColor(tcell.ColorBlack)
+	// Setup tree view handler
+	treeView.SetFileSelectHandler(func(path string) {
+		a.currentFile = path
+		a.contentView.DisplayFile(path)
+	})
 
-	a.pages.RemovePage("main")
-	a.pages.AddPage("main", mainPage, true, true)
+	// Setup tree view change handler
+	treeView.SetChangedFunc(func(node *tview.TreeNode) {
+		reference := node.GetReference()
+		if reference != nil {
+			path := reference.(string)
+			a.showLastResult(path)
+			a.statusBar.UpdatePath(path)
+			a.refreshWorkspace(path, false)
+
+			// Check if it's a directory
+			info, err := os.Stat(path)
+			if err == nil && info.IsDir() {
+				// Show welcome screen when moving to a directory
+				a.contentView.ShowWelcome()
+			}
+		}
+	})
+
+	return treeView
 }
 
-// showInitConfirmation shows file selection for init config
-func (a *AppNew) showInitConfirmation(path string) {
-	// Get directory path
-	info, err := os.Stat(path)
-	workDir := path
-	if err == nil && !info.IsDir() {
-		workDir = filepath.Dir(path)
+// reloadRoot rebuilds everything that depends on the terraform root:
+// history DB, tree, header, status bar and git state
+func (a *AppNew) reloadRoot() {
+	a.currentDir = a.config.TerraformRoot
+	a.currentFile = ""
+	a.commandView = nil
+	a.workspaceDir = ""
+	a.workspaces = make(map[string]string)
+	a.stacks = make(map[string]*model.TerraformDirectory)
+	a.results = make(map[string]*components.RunResult)
+	a.plans = make(map[string]*model.Plan)
+	a.jobs = nil
+	a.unopened = 0
+
+	// Reopen history DB for the new root. Jobs started in the old context keep
+	// writing to the old DB, which is closed once they have finished.
+	if a.historyDB != nil {
+		oldDB, oldJobs := a.historyDB, a.historyJobs
+		go func() {
+			oldJobs.Wait()
+			oldDB.Close()
+		}()
+		a.historyDB = nil
 	}
-
-	// Check if config directory exists
-	configDir := filepath.Join(workDir, "config")
-	if _, err := os.Stat(configDir); os.IsNotExist(err) {
-		// No config directory, use default
-		a.showInitConfirmationWithFile(path, "")
-		return
+	a.historyJobs = &sync.WaitGroup{}
+	historyDB, err := db.NewHistoryDB(a.config.HistoryDir())
+	if err != nil {
+		a.contentView.DisplayText("Error", fmt.Sprintf("[red]Failed to open history DB: %v[white]", err))
+	} else {
+		a.historyDB = historyDB
 	}
+	a.executor.SetHistoryDB(a.historyDB)
 
-	// Show file selection dialog
-	fileDialog := dialog.NewFileSelectionDialog(
-		configDir,
-		"*.conf",
-		"Select Init Config File",
-		func(filePath, content string) {
-			// File selected, show confirmation
-			a.pages.RemovePage("file_selection")
-			a.showInitConfirmationWithFile(path, filePath)
-		},
-		func() {
-			// Cancelled
-			a.pages.RemovePage("file_selection")
-			a.focusOnTree = true
-			a.tviewApp.SetFocus(a.treeView)
-		},
-	)
+	// Rebuild tree view with new root directory
+	a.treeView = a.newTreeView()
 
-	a.pages.AddPage("file_selection", fileDialog, true, true)
-	a.tviewApp.SetFocus(fileDialog.GetList())
-}
+	// Rebuild header and status bar
+	a.headerView = view.NewHeaderView(a.currentDir)
+	a.headerView.SetContext(a.config.CurrentContext)
+	a.headerView.SetKeymap(a.keymap)
 
-// showInitConfirmationWithFile shows confirmation dialog with selected file
-func (a *AppNew) showInitConfirmationWithFile(path, configFile string) {
-	info := components.GetTerraformCommandInfo(path, a.config.Commands.InitTemplate, configFile, a.config)
+	// Update git branch info in header
+	if status, err := a.gitManager.GetStatus(a.currentDir); err == nil {
+		a.headerView.SetGitBranch(status.Branch, status.IsDirty)
+	}
 
-	confirmDialog := dialog.NewTerraformConfirmDialog(
-		"terraform init",
-		info.WorkDir,
-		info.ConfigFile,
-		info.Content,
-		// Execute: normal execution (no auto-approve)
-		func() {
-			a.pages.RemovePage("confirm_tf")
-			a.executeTerraformCommand("Init", info.WorkDir, info.Command, info.ConfigFile, info.Content)
-		},
-		// Auto Approve: add -auto-approve flag
-		func() {
-			a.pages.RemovePage("confirm_tf")
-			cmdWithAutoApprove := info.Command + " -auto-approve"
-			a.executeTerraformCommand("Init", info.WorkDir, cmdWithAutoApprove, info.ConfigFile, info.Content)
-		},
-		// Cancel
-		func() {
-			a.pages.RemovePage("confirm_tf")
-			a.focusOnTree = true
-			a.tviewApp.SetFocus(a.treeView)
-		},
-	)
+	a.statusBar = view.NewStatusBar(a.currentDir)
 
-	a.pages.AddPage("confirm_tf", confirmDialog, true, true)
-	if form := confirmDialog.GetForm(); form != nil {
-		a.tviewApp.SetFocus(form)
-	}
+	// Rebuild main layout
+	a.rebuildMainPage()
+	a.focusOnTree = true
+
+	a.startWatcher()
+	a.refreshTreeBadges()
 }
 
-// showPlanConfirmation shows file selection for plan tfvars
-func (a *AppNew) showPlanConfirmation(path string) {
-	// Get directory path
-	info, err := os.Stat(path)
-	workDir := path
-	if err == nil && !info.IsDir() {
-		workDir = filepath.Dir(path)
+// startWatcher watches the terraform root for changes made outside of t9s,
+// replacing the watcher of a previous root. Disabled by auto_refresh: false.
+func (a *AppNew) startWatcher() {
+	a.stopWatcher()
+	if !a.config.Defaults.AutoRefresh {
+		return
 	}
 
-	// Check if config directory exists
-	configDir := filepath.Join(workDir, "config")
-	if _, err := os.Stat(configDir); os.IsNotExist(err) {
-		// No config directory, use default
-		a.showPlanConfirmationWithFile(path, "")
+	root := a.currentDir
+	watcher, err := a.terraformDAO().Watch(dao.DefaultDebounce, func(paths []string) {
+		// git status runs off the UI goroutine; the tree and content are updated on it
+		status, gitErr := a.gitManager.GetStatus(root)
+		a.tviewApp.QueueUpdateDraw(func() {
+			if a.currentDir != root {
+				return
+			}
+			if gitErr == nil {
+				a.headerView.SetGitBranch(status.Branch, status.IsDirty)
+			}
+			a.applyFileChanges(paths)
+			a.refreshTreeBadges()
+		})
+	})
+	if err != nil {
+		a.statusBar.ShowMessage(fmt.Sprintf("[yellow]File watcher disabled: %s[white]", tview.Escape(err.Error())))
 		return
 	}
+	a.watcher = watcher
+}
 
-	// Show file selection dialog
-	fileDialog := dialog.NewFileSelectionDialog(
-		configDir,
-		"*.tfvars",
-		"Select Terraform Variables File for Plan",
-		func(filePath, content string) {
-			// File selected, show confirmation
-			a.pages.RemovePage("file_selection")
-			a.showPlanConfirmationWithFile(path, filePath)
+// stopWatcher stops the file watcher, if any
+func (a *AppNew) stopWatcher() {
+	if a.watcher != nil {
+		a.watcher.Close()
+		a.watcher = nil
+	}
+}
+
+// applyFileChanges refreshes the tree, the displayed file and the status of stacks
+// after files changed on disk
+func (a *AppNew) applyFileChanges(paths []string) {
+	gitDir := string(filepath.Separator) + ".git"
+	var dirs []string
+	seen := make(map[string]bool)
+	changed := make(map[string]bool)
+	var pending []string
+	for _, path := range paths {
+		if strings.HasSuffix(path, gitDir) || strings.Contains(path, gitDir+string(filepath.Separator)) {
+			continue
+		}
+		dir := filepath.Dir(path)
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+
+		if path == a.contentView.CurrentFile() {
+			if err := a.contentView.ReloadFile(); err != nil {
+				a.currentFile = ""
+			}
+		}
+
+		// Editing the configuration or the variables of a stack invalidates its last plan
+		if ext := filepath.Ext(path); ext == ".tf" || ext == ".tfvars" {
+			st := stackDir(dir)
+			if st == "" {
+				// tfvars kept in a subdirectory such as config/ belong to the parent stack
+				st = stackDir(filepath.Dir(dir))
+			}
+			if st != "" && !changed[st] {
+				changed[st] = true
+				a.setStackStatus(st, model.StatusPending)
+				pending = append(pending, a.stackName(st))
+			}
+		}
+	}
+
+	a.treeView.Refresh(dirs)
+	if len(pending) > 0 {
+		a.statusBar.ShowMessage(fmt.Sprintf("[yellow]Changed on disk, plan pending: %s[white]", strings.Join(pending, ", ")))
+	}
+}
+
+// showContextSwitch shows the context selection dialog
+func (a *AppNew) showContextSwitch() {
+	if len(a.config.Contexts) == 0 {
+		a.contentView.DisplayText("Contexts", "[yellow]No contexts configured[white]\n\n"+
+			"Add named contexts to [cyan]~/.t9s/config.yaml[white]:\n\n"+
+			"[green]current_context: platform\n"+
+			"contexts:\n"+
+			"  - name: platform\n"+
+			"    terraform_root: /path/to/platform\n"+
+			"  - name: networking\n"+
+			"    terraform_root: /path/to/networking\n"+
+			"    commands:\n"+
+			"      plan_template: terraform plan -var-file={varfile}[white]\n")
+		return
+	}
+
+	contextDialog := dialog.NewContextDialog(
+		a.config.Contexts,
+		a.config.CurrentContext,
+		func(name string) {
+			a.pages.RemovePage("context_select")
+			a.switchContext(name)
 		},
 		func() {
-			// Cancelled
-			a.pages.RemovePage("file_selection")
-			a.focusOnTree = true
+			a.pages.RemovePage("context_select")
 			a.tviewApp.SetFocus(a.treeView)
 		},
 	)
 
-	a.pages.AddPage("file_selection", fileDialog, true, true)
-	a.tviewApp.SetFocus(fileDialog.GetList())
+	a.pages.AddPage("context_select", contextDialog, true, true)
+	a.tviewApp.SetFocus(contextDialog.GetList())
 }
 
-// showPlanConfirmationWithFile shows confirmation dialog with selected file
-func (a *AppNew) showPlanConfirmationWithFile(path, configFile string) {
-	info := components.GetTerraformCommandInfo(path, a.config.Commands.PlanTemplate, configFile, a.config)
+// switchContext activates the named context and reloads all views
+func (a *AppNew) switchContext(name string) {
+	if name == a.config.CurrentContext {
+		a.tviewApp.SetFocus(a.treeView)
+		return
+	}
 
-	confirmDialog := dialog.NewTerraformConfirmDialog(
-		"terraform plan",
-		info.WorkDir,
-		info.ConfigFile,
-		info.Content,
-		// Execute: normal execution (no auto-approve, plan doesn't need it anyway)
+	if err := a.config.UseContext(name); err != nil {
+		a.contentView.DisplayText("Error", fmt.Sprintf("[red]Failed to switch context: %v[white]", err))
+		a.tviewApp.SetFocus(a.treeView)
+		return
+	}
+	if err := a.config.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to save config: %v\n", err)
+	}
+
+	a.reloadRoot()
+	a.pages.SwitchToPage("main")
+	a.tviewApp.SetFocus(a.treeView)
+	a.contentView.ShowWelcome()
+}
+
+// stackDir returns the directory of path if it contains terraform files, otherwise ""
+func stackDir(path string) string {
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		path = filepath.Dir(path)
+	}
+	if matches, _ := filepath.Glob(filepath.Join(path, "*.tf")); len(matches) > 0 {
+		return path
+	}
+	return ""
+}
+
+// refreshWorkspace shows the workspace of the stack at path in the header.
+// The workspace is fetched in the background and cached per stack unless force is set.
+func (a *AppNew) refreshWorkspace(path string, force bool) {
+	dir := stackDir(path)
+	a.workspaceDir = dir
+	if dir == "" {
+		a.headerView.UpdateWorkspace("")
+		return
+	}
+	if ws, ok := a.workspaces[dir]; ok && !force {
+		a.headerView.UpdateWorkspace(ws)
+		return
+	}
+
+	a.headerView.UpdateWorkspace("...")
+	go func() {
+		tfDAO := a.terraformDAO()
+		ws, err := tfDAO.ShowWorkspace(dir)
+		if err != nil {
+			ws = tfDAO.CurrentWorkspace(dir)
+		}
+		a.tviewApp.QueueUpdateDraw(func() {
+			a.workspaces[dir] = ws
+			// Ignore results for a stack that is no longer selected
+			if a.workspaceDir == dir {
+				a.headerView.UpdateWorkspace(ws)
+			}
+		})
+	}()
+}
+
+// showWorkspaces shows the workspace panel of the stack at path
+func (a *AppNew) showWorkspaces(path string) {
+	dir := stackDir(path)
+	if dir == "" {
+		a.contentView.DisplayText("Workspaces", "[yellow]Select a directory containing .tf files to manage its workspaces[white]")
+		return
+	}
+
+	a.statusBar.ShowMessage(fmt.Sprintf("[yellow]Loading workspaces of %s...[white]", a.stackName(dir)))
+	go func() {
+		workspaces, current, err := a.terraformDAO().ListWorkspaces(dir)
+		a.tviewApp.QueueUpdateDraw(func() {
+			a.statusBar.UpdatePath(path)
+			if err != nil {
+				a.contentView.DisplayText("Error", fmt.Sprintf("[red]Failed to list workspaces:[white] %s", tview.Escape(err.Error())))
+				return
+			}
+			a.openWorkspaceDialog(dir, workspaces, current)
+		})
+	}()
+}
+
+// openWorkspaceDialog opens the workspace dialog with a loaded workspace list
+func (a *AppNew) openWorkspaceDialog(dir string, workspaces []string, current string) {
+	closeDialog := func() {
+		a.pages.RemovePage("workspace_select")
+		a.focusOnTree = true
+		a.tviewApp.SetFocus(a.treeView)
+	}
+
+	workspaceDialog := dialog.NewWorkspaceDialog(
+		a.stackName(dir),
+		workspaces,
+		current,
+		func(name string) {
+			closeDialog()
+			if name != current {
+				a.runWorkspaceCommand(dir, "Select", name, func(tfDAO *dao.TerraformDAO) error {
+					return tfDAO.SelectWorkspace(dir, name)
+				})
+			}
+		},
 		func() {
-			a.pages.RemovePage("confirm_tf")
-			a.executeTerraformCommand("Plan", info.WorkDir, info.Command, info.ConfigFile, info.Content)
+			a.pages.RemovePage("workspace_select")
+			a.showNewWorkspace(dir)
+		},
+		func(name string) {
+			if name == current {
+				a.contentView.DisplayText("Workspaces", fmt.Sprintf(
+					"[yellow]Cannot delete the selected workspace %q.[white]\nSelect another workspace first.", name))
+				return
+			}
+			a.pages.RemovePage("workspace_select")
+			a.confirmDeleteWorkspace(dir, name)
+		},
+		closeDialog,
+	)
+
+	a.pages.AddPage("workspace_select", workspaceDialog, true, true)
+	a.tviewApp.SetFocus(workspaceDialog.GetList())
+}
+
+// showNewWorkspace asks for the name of a new workspace
+func (a *AppNew) showNewWorkspace(dir string) {
+	inputDialog := dialog.NewInputDialog(
+		" ➕ New Workspace ",
+		"Workspace Name",
+		"",
+		"Create",
+		func(name string) {
+			a.pages.RemovePage("workspace_new")
+			a.tviewApp.SetFocus(a.treeView)
+			a.runWorkspaceCommand(dir, "New", name, func(tfDAO *dao.TerraformDAO) error {
+				return tfDAO.NewWorkspace(dir, name)
+			})
 		},
-		// Auto Approve: not applicable for plan, but still provide the option
 		func() {
-			a.pages.RemovePage("confirm_tf")
-			a.executeTerraformCommand("Plan", info.WorkDir, info.Command, info.ConfigFile, info.Content)
+			a.pages.RemovePage("workspace_new")
+			a.tviewApp.SetFocus(a.treeView)
 		},
-		// Cancel
+	)
+
+	a.pages.AddPage("workspace_new", inputDialog, true, true)
+	a.tviewApp.SetFocus(inputDialog.GetForm())
+}
+
+// confirmDeleteWorkspace asks before deleting a workspace
+func (a *AppNew) confirmDeleteWorkspace(dir, name string) {
+	confirmDialog := dialog.NewConfirmDialog(
+		fmt.Sprintf("Delete workspace %q of %s?\n\nTerraform refuses to delete workspaces that still manage resources.", name, a.stackName(dir)),
 		func() {
-			a.pages.RemovePage("confirm_tf")
-			a.focusOnTree = true
+			a.pages.RemovePage("workspace_delete")
+			a.tviewApp.SetFocus(a.treeView)
+			a.runWorkspaceCommand(dir, "Delete", name, func(tfDAO *dao.TerraformDAO) error {
+				return tfDAO.DeleteWorkspace(dir, name)
+			})
+		},
+		func() {
+			a.pages.RemovePage("workspace_delete")
 			a.tviewApp.SetFocus(a.treeView)
 		},
 	)
 
-	a.pages.AddPage("confirm_tf", confirmDialog, true, true)
-	if form := confirmDialog.GetForm(); form != nil {
-		a.tviewApp.SetFocus(form)
+	a.pages.AddPage("workspace_delete", confirmDialog, true, true)
+	a.tviewApp.SetFocus(confirmDialog)
+}
+
+// runWorkspaceCommand runs a workspace operation in the background and refreshes the header
+func (a *AppNew) runWorkspaceCommand(dir, action, name string, run func(tfDAO *dao.TerraformDAO) error) {
+	a.contentView.Clear()
+	a.contentView.SetTitle(" 🗂  Workspace ")
+	fmt.Fprintf(a.contentView, "[yellow]%s Workspace[white]\n", action)
+	fmt.Fprintf(a.contentView, "[cyan]Directory:[white] %s\n", dir)
+	fmt.Fprintf(a.contentView, "[cyan]Workspace:[white] %s\n", tview.Escape(name))
+	fmt.Fprintf(a.contentView, "[cyan]%s[white]\n\n", strings.Repeat("─", 60))
+
+	go func() {
+		err := run(a.terraformDAO())
+		a.tviewApp.QueueUpdateDraw(func() {
+			if err != nil {
+				fmt.Fprintf(a.contentView, "[red]Error:[white] %s\n", tview.Escape(err.Error()))
+				return
+			}
+			fmt.Fprintf(a.contentView, "[green]Done.[white]\n")
+			if a.workspaceDir == dir {
+				a.refreshWorkspace(dir, true)
+			} else {
+				delete(a.workspaces, dir)
+			}
+		})
+	}()
+}
+
+// terraformDAO returns a DAO for the current root using the configured discovery rules
+func (a *AppNew) terraformDAO() *dao.TerraformDAO {
+	return dao.NewTerraformDAO(a.currentDir).WithDiscovery(dao.ConfigDiscovery(a.config))
+}
+
+// stack returns the cached stack of a directory, creating it on first use
+func (a *AppNew) stack(dir string) *model.TerraformDirectory {
+	if st, ok := a.stacks[dir]; ok {
+		return st
 	}
+	st := &model.TerraformDirectory{
+		Name:   a.stackName(dir),
+		Path:   dir,
+		Status: model.StatusUnknown,
+	}
+	a.stacks[dir] = st
+	return st
 }
 
-// showApplyConfirmation shows file selection for apply tfvars
-func (a *AppNew) showApplyConfirmation() {
-	path := a.treeView.GetCurrentPath()
-	if path == "" {
+// setStackStatus sets the status of a stack and its badge in the tree
+func (a *AppNew) setStackStatus(dir string, status model.TerraformStatus) {
+	a.stack(dir).Status = status
+	a.treeView.SetStatus(dir, status)
+}
+
+const (
+	// toastDuration is how long a finished job is shown above the status bar
+	toastDuration = 8 * time.Second
+	// maxJobs is the number of finished jobs whose output is kept
+	maxJobs = 20
+	// ageInterval is how often the age of the last result is redrawn
+	ageInterval = 30 * time.Second
+)
+
+// recordResult updates the status of a stack after a terraform run: a plan with changes
+// means drift, a successful apply or refresh leaves the stack synced. The result is shown
+// in the status bar and header while the stack is selected, and raised as a toast.
+func (a *AppNew) recordResult(result *components.RunResult) {
+	dir, action, changes, err := result.Stack, result.Action, result.Changes, result.Err
+	switch {
+	case err != nil:
+		a.setStackStatus(dir, model.StatusError)
+	case action == "Plan" && changes != nil:
+		if changes.Empty() {
+			a.setStackStatus(dir, model.StatusSynced)
+		} else {
+			a.setStackStatus(dir, model.StatusDrift)
+		}
+	case action == "Apply" || action == "Refresh":
+		a.setStackStatus(dir, model.StatusSynced)
+	case action == "Destroy":
+		a.setStackStatus(dir, model.StatusUnknown)
+	}
+	if recordsHistory(action) {
+		a.refreshTreeBadges()
+	}
+
+	a.results[dir] = result
+	if path := a.treeView.GetCurrentPath(); path != "" && stackDir(path) == dir {
+		a.showLastResult(path)
+		a.statusBar.UpdatePath(path)
+	}
+
+	a.jobs = append(a.jobs, result)
+	if len(a.jobs) > maxJobs {
+		a.jobs = a.jobs[len(a.jobs)-maxJobs:]
+	}
+	a.raiseToast(result)
+}
+
+// showLastResult shows the last terraform result of the stack at path in the status bar and header
+func (a *AppNew) showLastResult(path string) {
+	result := a.results[stackDir(path)]
+	if result == nil {
+		a.statusBar.SetResult("")
+		a.headerView.SetLastRun("")
 		return
 	}
+	age := view.FormatAge(result.Finished)
+	color := result.Color()
+	a.statusBar.SetResult(fmt.Sprintf("[%s]%s[white] [gray](%s)[white]", color, tview.Escape(result.Summary()), age))
+	a.headerView.SetLastRun(fmt.Sprintf("[%s]%s[white] [gray]%s[white]", color, result.Short(), age))
+}
 
-	// Get directory path
-	info, err := os.Stat(path)
-	workDir := path
-	if err == nil && !info.IsDir() {
-		workDir = filepath.Dir(path)
+// raiseToast shows a finished job above the status bar for a few seconds
+func (a *AppNew) raiseToast(result *components.RunResult) {
+	if a.unopened < len(a.jobs) {
+		a.unopened++
 	}
+	a.toastSeq++
+	seq := a.toastSeq
+	a.toastJob = result
 
-	// Check if config directory exists
-	configDir := filepath.Join(workDir, "config")
+	a.toastBar.Show(result.Color(), a.stackName(result.Stack), result.Summary(), a.unopened-1)
+	a.mainLayout.ResizeItem(a.toastBar, 1, 0)
+
+	time.AfterFunc(toastDuration, func() {
+		a.tviewApp.QueueUpdateDraw(func() {
+			// A newer toast hides itself
+			if a.toastSeq == seq {
+				a.hideToast()
+			}
+		})
+	})
+}
+
+// hideToast hides the toast bar
+func (a *AppNew) hideToast() {
+	a.toastJob = nil
+	a.toastBar.Clear()
+	a.mainLayout.ResizeItem(a.toastBar, 0, 0)
+}
+
+// showJobs opens the output of the finished job, or a list to choose one when there are several
+func (a *AppNew) showJobs() {
+	switch len(a.jobs) {
+	case 0:
+		a.statusBar.ShowMessage("[yellow]No background job has finished yet[white]")
+		return
+	case 1:
+		a.openJob(a.jobs[0])
+		return
+	}
+
+	// Newest first
+	jobs := make([]*components.RunResult, len(a.jobs))
+	labels := make([]string, len(a.jobs))
+	for i, job := range a.jobs {
+		j := len(a.jobs) - 1 - i
+		jobs[j] = job
+		color := job.Color()
+		labels[j] = fmt.Sprintf("[%s]●[white] %-32s [%s]%s[white]  [gray]%s[white]",
+			color, tview.Escape(a.stackName(job.Stack)), color, tview.Escape(job.Summary()), view.FormatAge(job.Finished))
+	}
 
-	// DEBUG LOGGING
-	f, _ := os.OpenFile("/tmp/t9s_debug.log", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if f != nil {
-		fmt.Fprintf(f, "Apply Path: %s\nWorkDir: %s\nConfigDir: %s\n", path, workDir, configDir)
-		if _, err := os.Stat(configDir); os.IsNotExist(err) {
-			fmt.Fprintf(f, "ConfigDir does NOT exist: %v\n", err)
+	closeDialog := func() {
+		a.pages.RemovePage("jobs")
+		if a.focusOnTree {
+			a.tviewApp.SetFocus(a.treeView)
 		} else {
-			fmt.Fprintf(f, "ConfigDir exists\n")
+			a.tviewApp.SetFocus(a.contentView)
 		}
-		f.Close()
 	}
+	jobsDialog := dialog.NewJobsDialog(labels, func(index int) {
+		a.pages.RemovePage("jobs")
+		a.openJob(jobs[index])
+	}, closeDialog)
 
-	if _, err := os.Stat(configDir); os.IsNotExist(err) {
-		// No config directory, use default
-		a.showApplyConfirmationWithFile(path, "")
+	a.pages.AddPage("jobs", jobsDialog, true, true)
+	a.tviewApp.SetFocus(jobsDialog.GetList())
+}
+
+// openJob shows the output of a finished job in the content view, folding plan output
+func (a *AppNew) openJob(job *components.RunResult) {
+	a.unopened = 0
+	a.hideToast()
+
+	a.currentFile = ""
+	a.contentView.Clear()
+	a.contentView.SetTitle(fmt.Sprintf(" 🔔 %s: %s (%s) ", job.Action, a.stackName(job.Stack), view.FormatAge(job.Finished)))
+	if job.Output == "" {
+		fmt.Fprintf(a.contentView, "[gray]No output was captured for this job[white]\n")
+	} else {
+		w := tview.ANSIWriter(a.contentView)
+		w.Write([]byte(job.Output))
+	}
+	color := job.Color()
+	fmt.Fprintf(a.contentView, "\n[%s]%s[white]", color, tview.Escape(job.Summary()))
+
+	a.focusOnTree = false
+	a.tviewApp.SetFocus(a.contentView)
+	a.statusBar.SetFocusIndicator("Content View")
+	a.contentView.ScrollToBeginning()
+	if a.contentView.FoldPlan() > 0 {
+		a.showFoldStatus()
+	}
+}
+
+// refreshTreeBadges reloads the git state of files and the last apply of each directory
+// in the background and shows them in the tree
+func (a *AppNew) refreshTreeBadges() {
+	root := a.currentDir
+	historyDB, release := a.holdHistory()
+	go func() {
+		defer release()
+		states, err := a.gitManager.FileStates(root)
+		if err != nil {
+			states = nil
+		}
+		var applied map[string]*db.HistoryEntry
+		if historyDB != nil {
+			applied, _ = historyDB.LastApplied()
+		}
+		a.tviewApp.QueueUpdateDraw(func() {
+			if a.currentDir != root {
+				return
+			}
+			a.treeView.SetGitStates(states)
+			a.treeView.SetLastApplied(applied)
+		})
+	}()
+}
+
+// toggleStacksOnly switches the tree between listing everything and listing only stacks
+// and their tfvars files
+func (a *AppNew) toggleStacksOnly() {
+	if a.treeView.StacksOnly() {
+		a.treeView.SetStacksOnly(nil)
+		a.statusBar.ShowMessage("[green]Showing all files[white]")
 		return
 	}
 
-	// Show file selection dialog
-	fileDialog := dialog.NewFileSelectionDialog(
-		configDir,
-		"*.tfvars",
-		"Select Terraform Variables File for Apply",
-		func(filePath, content string) {
-			// File selected, show confirmation
-			a.pages.RemovePage("file_selection")
-			a.showApplyConfirmationWithFile(path, filePath)
-		},
-		func() {
-			// Cancelled
-			a.pages.RemovePage("file_selection")
-			a.focusOnTree = true
-			a.tviewApp.SetFocus(a.treeView)
-		},
-	)
+	root := a.currentDir
+	a.statusBar.ShowMessage("[yellow]Discovering stacks...[white]")
+	go func() {
+		index, err := a.terraformDAO().Discover()
+		a.tviewApp.QueueUpdateDraw(func() {
+			if a.currentDir != root {
+				return
+			}
+			if err != nil {
+				a.statusBar.ShowMessage(fmt.Sprintf("[red]Failed to discover stacks: %s[white]", tview.Escape(err.Error())))
+				return
+			}
+			stacks := make(map[string]bool)
+			for _, stack := range index.Stacks() {
+				stacks[stack] = true
+			}
+			a.treeView.SetStacksOnly(stacks)
+			a.statusBar.ShowMessage(fmt.Sprintf("[green]Showing %d stack(s) and their tfvars (f to show all)[white]", len(stacks)))
+		})
+	}()
+}
 
-	a.pages.AddPage("file_selection", fileDialog, true, true)
-	a.tviewApp.SetFocus(fileDialog.GetList())
+// showFinder collects stacks, terraform files and recent history in the background
+// and opens the fuzzy finder
+func (a *AppNew) showFinder() {
+	root := a.currentDir
+	historyDB, release := a.holdHistory()
+	a.statusBar.ShowMessage("[yellow]Indexing stacks and files...[white]")
+	go func() {
+		items, err := a.finderItems(historyDB)
+		release()
+		a.tviewApp.QueueUpdateDraw(func() {
+			if a.currentDir != root {
+				return
+			}
+			a.statusBar.ShowDefault()
+			if err != nil {
+				a.statusBar.ShowMessage(fmt.Sprintf("[red]Failed to index stacks: %s[white]", tview.Escape(err.Error())))
+				return
+			}
+			a.openFinder(items)
+		})
+	}()
+}
+
+// finderItems returns the stacks, .tf and tfvars files and recent history entries of the
+// root. Items are used when their stack last appeared in the history.
+func (a *AppNew) finderItems(historyDB *db.HistoryDB) ([]*view.FinderItem, error) {
+	tf := a.terraformDAO()
+	index, err := tf.Discover()
+	if err != nil {
+		return nil, err
+	}
+	files, err := tf.TerraformFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	var recent []*db.HistoryEntry
+	if historyDB != nil {
+		recent, _ = historyDB.GetRecent(100)
+	}
+	used := make(map[string]time.Time)
+	for _, entry := range recent {
+		if entry.Timestamp.After(used[entry.Directory]) {
+			used[entry.Directory] = entry.Timestamp
+		}
+	}
+
+	var items []*view.FinderItem
+	for _, stack := range index.Stacks() {
+		detail := ""
+		if e := index.Entries[stack]; e != nil && e.Backend != "" {
+			detail = e.Backend
+		}
+		items = append(items, &view.FinderItem{
+			Kind:   view.FinderStack,
+			Path:   stack,
+			Name:   a.stackName(stack),
+			Detail: detail,
+			Used:   used[stack],
+		})
+	}
+	for _, file := range files {
+		kind := view.FinderFile
+		if strings.HasSuffix(file, ".tfvars") {
+			kind = view.FinderTfvars
+		}
+		items = append(items, &view.FinderItem{
+			Kind: kind,
+			Path: file,
+			Name: a.stackName(file),
+			Used: used[filepath.Dir(file)],
+		})
+	}
+	for _, entry := range recent {
+		var preview strings.Builder
+		fmt.Fprintf(&preview, "[yellow]%s[white] %s\n\n", strings.ToUpper(entry.Action), tview.Escape(a.stackName(entry.Directory)))
+		fmt.Fprintf(&preview, "[cyan]Time:[white]      %s (%s)\n", entry.Timestamp.Format("2006-01-02 15:04:05"), view.FormatAge(entry.Timestamp))
+		fmt.Fprintf(&preview, "[cyan]User:[white]      %s\n", tview.Escape(entry.User))
+		fmt.Fprintf(&preview, "[cyan]Branch:[white]    %s\n", tview.Escape(entry.Branch))
+		fmt.Fprintf(&preview, "[cyan]Workspace:[white] %s\n", tview.Escape(entry.Workspace))
+		fmt.Fprintf(&preview, "[cyan]Config:[white]    %s\n", tview.Escape(entry.ConfigFile))
+		if entry.Success {
+			fmt.Fprintf(&preview, "[cyan]Result:[white]    [green]success[white]\n")
+		} else {
+			fmt.Fprintf(&preview, "[cyan]Result:[white]    [red]failed[white] %s\n", tview.Escape(entry.ErrorMsg))
+		}
+		if entry.Targets != "" {
+			fmt.Fprintf(&preview, "\n[red]Targets:[white]\n%s\n", tview.Escape(entry.Targets))
+		}
+		items = append(items, &view.FinderItem{
+			Kind:    view.FinderHistory,
+			Path:    entry.Directory,
+			Name:    fmt.Sprintf("%s %s", entry.Action, a.stackName(entry.Directory)),
+			Detail:  fmt.Sprintf("%s by %s", view.FormatAge(entry.Timestamp), entry.User),
+			Used:    entry.Timestamp,
+			Preview: preview.String(),
+		})
+	}
+	return items, nil
+}
+
+// openFinder opens the fuzzy finder over items; choosing one reveals it in the tree
+func (a *AppNew) openFinder(items []*view.FinderItem) {
+	finder := view.NewFinderView(items)
+	closeFinder := func() {
+		a.pages.RemovePage("finder")
+		a.focusOnTree = true
+		a.tviewApp.SetFocus(a.treeView)
+	}
+
+	finder.GetInput().SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			closeFinder()
+			return nil
+		case tcell.KeyUp, tcell.KeyCtrlP:
+			finder.Move(-1)
+			return nil
+		case tcell.KeyDown, tcell.KeyCtrlN:
+			finder.Move(1)
+			return nil
+		case tcell.KeyPgUp:
+			finder.Move(-10)
+			return nil
+		case tcell.KeyPgDn:
+			finder.Move(10)
+			return nil
+		case tcell.KeyEnter:
+			item := finder.Selected()
+			if item == nil {
+				return nil
+			}
+			closeFinder()
+			a.revealPath(item.Path)
+			return nil
+		}
+		return event
+	})
+
+	a.pages.AddPage("finder", finder, true, true)
+	a.tviewApp.SetFocus(finder.GetInput())
+}
+
+// revealPath selects path in the tree, expanding its parents, and shows it like a tree selection
+func (a *AppNew) revealPath(path string) {
+	if !a.treeView.Reveal(path) && a.treeView.StacksOnly() {
+		// The path is hidden by the stacks-only listing
+		a.treeView.SetStacksOnly(nil)
+		a.treeView.Reveal(path)
+	}
+	if a.treeView.GetCurrentPath() != path {
+		a.statusBar.ShowMessage(fmt.Sprintf("[red]%s is not in the tree[white]", tview.Escape(a.stackName(path))))
+		return
+	}
+
+	a.statusBar.UpdatePath(path)
+	a.refreshWorkspace(path, false)
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		a.currentFile = path
+		a.contentView.DisplayFile(path)
+	} else {
+		a.contentView.ShowWelcome()
+	}
+}
+
+// showDefinitions lists the var., local. and module. references of a file and jumps to
+// the declaration of the chosen one
+func (a *AppNew) showDefinitions(file string) {
+	refs, err := a.terraformDAO().References(file)
+	if err != nil {
+		a.statusBar.ShowMessage(fmt.Sprintf("[red]Failed to parse the stack: %s[white]", tview.Escape(err.Error())))
+		return
+	}
+	if len(refs) == 0 {
+		a.statusBar.ShowMessage(fmt.Sprintf("[yellow]No var., local. or module. references in %s[white]", filepath.Base(file)))
+		return
+	}
+
+	closeDialog := func() {
+		a.pages.RemovePage("definitions")
+		a.tviewApp.SetFocus(a.contentView)
+	}
+	definitionDialog := dialog.NewDefinitionDialog(
+		file,
+		refs,
+		a.contentView.TopLine(),
+		func(ref *model.SymbolReference) {
+			closeDialog()
+			if ref.DefFile != file {
+				a.revealPath(ref.DefFile)
+				a.focusOnTree = false
+				a.tviewApp.SetFocus(a.contentView)
+				a.statusBar.SetFocusIndicator("Content View")
+			}
+			a.currentFile = ref.DefFile
+			a.contentView.GoToLine(ref.DefFile, ref.DefLine)
+			a.statusBar.ShowMessage(fmt.Sprintf("[green]%s is declared at %s:%d[white]", ref.Address, tview.Escape(a.stackName(ref.DefFile)), ref.DefLine))
+		},
+		closeDialog,
+	)
+
+	a.pages.AddPage("definitions", definitionDialog, true, true)
+	a.tviewApp.SetFocus(definitionDialog.GetList())
+}
+
+// showState loads the state of the stack at path and opens the state browser
+func (a *AppNew) showState(path string) {
+	dir := stackDir(path)
+	if dir == "" {
+		a.contentView.DisplayText("State", "[yellow]Select a directory containing .tf files to browse its state[white]")
+		return
+	}
+
+	st := a.stack(dir)
+	a.statusBar.ShowMessage(fmt.Sprintf("[yellow]Reading state of %s...[white]", st.Name))
+	go func() {
+		// LoadState updates the counts of a copy so the cache is only touched on the UI goroutine
+		loaded := *st
+		state, err := a.terraformDAO().LoadState(&loaded)
+		a.tviewApp.QueueUpdateDraw(func() {
+			a.statusBar.UpdatePath(path)
+			if err != nil {
+				a.contentView.DisplayText("Error", fmt.Sprintf("[red]Failed to read state:[white] %s", tview.Escape(err.Error())))
+				return
+			}
+			st.Resources = loaded.Resources
+			st.Outputs = loaded.Outputs
+			a.openStateView(st, state)
+		})
+	}()
+}
+
+// openStateView shows a loaded state in the state browser
+func (a *AppNew) openStateView(st *model.TerraformDirectory, state *model.State) {
+	stateView := view.NewStateView(fmt.Sprintf("%s (%d resources, %d outputs)", st.Name, st.Resources, st.Outputs), state, a.keymap)
+	tree := stateView.GetTree()
+	filter := stateView.GetFilter()
+	detail := stateView.GetDetail()
+
+	closeView := func() {
+		a.pages.RemovePage("state")
+		a.focusOnTree = true
+		a.tviewApp.SetFocus(a.treeView)
+	}
+	backToTree := func() {
+		a.tviewApp.SetFocus(tree)
+	}
+
+	tree.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			closeView()
+			return nil
+		case tcell.KeyTab:
+			a.tviewApp.SetFocus(detail)
+			return nil
+		case tcell.KeyRune:
+			if event.Rune() == 'q' {
+				closeView()
+				return nil
+			}
+		}
+
+		switch a.keymap.Lookup(view.KeyName(event), config.ScopeState) {
+		case config.ActionStateFilter:
+			a.tviewApp.SetFocus(filter)
+			return nil
+		case config.ActionStateMove:
+			if res := stateView.SelectedResource(); res != nil {
+				a.showStateMove(st.Path, res.Address, state, backToTree)
+			}
+			return nil
+		case config.ActionStateRemove:
+			if res := stateView.SelectedResource(); res != nil {
+				a.confirmStateOperation(components.NewStateRemove(st.Path, res.Address), backToTree)
+			}
+			return nil
+		case config.ActionStateImport:
+			address := ""
+			if res := stateView.SelectedResource(); res != nil {
+				address = res.Address
+			}
+			a.showImport(st.Path, address, backToTree)
+			return nil
+		case config.ActionStateTarget:
+			a.pages.RemovePage("state")
+			a.openTargetDialog(st.Path, state, a.plans[st.Path])
+			return nil
+		case config.ActionStateReplace:
+			if res := stateView.SelectedResource(); res != nil && res.Mode == "managed" {
+				a.showReplace(st.Path, res.Address, backToTree)
+			}
+			return nil
+		}
+		return event
+	})
+
+	detail.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyTab, tcell.KeyEscape:
+			a.tviewApp.SetFocus(tree)
+			return nil
+		}
+		return event
+	})
+
+	// Enter keeps the filter, Esc clears it
+	filter.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEscape {
+			filter.SetText("")
+		}
+		a.tviewApp.SetFocus(tree)
+	})
+
+	a.pages.AddPage("state", stateView, true, true)
+	a.tviewApp.SetFocus(tree)
+}
+
+// showOutputs loads the outputs of the stack at path and opens the outputs viewer
+func (a *AppNew) showOutputs(path string) {
+	dir := stackDir(path)
+	if dir == "" {
+		a.contentView.DisplayText("Outputs", "[yellow]Select a directory containing .tf files to view its outputs[white]")
+		return
+	}
+
+	st := a.stack(dir)
+	a.statusBar.ShowMessage(fmt.Sprintf("[yellow]Reading outputs of %s...[white]", st.Name))
+	go func() {
+		outputs, err := a.terraformDAO().GetOutputs(dir)
+		a.tviewApp.QueueUpdateDraw(func() {
+			a.statusBar.UpdatePath(path)
+			if err != nil {
+				a.contentView.DisplayText("Error", fmt.Sprintf("[red]Failed to read outputs:[white] %s", tview.Escape(err.Error())))
+				return
+			}
+			st.Outputs = len(outputs)
+			a.openOutputsView(st, outputs)
+		})
+	}()
+}
+
+// openOutputsView shows loaded outputs and scans the other stacks for consumers in the background
+func (a *AppNew) openOutputsView(st *model.TerraformDirectory, outputs []*model.StateOutput) {
+	outputsView := view.NewOutputsView(st.Name, a.currentDir, outputs, a.keymap)
+	table := outputsView.GetTable()
+	detail := outputsView.GetDetail()
+
+	closeView := func() {
+		a.pages.RemovePage("outputs")
+		a.focusOnTree = true
+		a.tviewApp.SetFocus(a.treeView)
+	}
+
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			closeView()
+			return nil
+		case tcell.KeyTab:
+			a.tviewApp.SetFocus(detail)
+			return nil
+		case tcell.KeyRune:
+			if event.Rune() == 'q' {
+				closeView()
+				return nil
+			}
+		}
+
+		switch a.keymap.Lookup(view.KeyName(event), config.ScopeOutputs) {
+		case config.ActionOutputsReveal:
+			outputsView.ToggleReveal()
+			return nil
+		case config.ActionOutputsRevealAll:
+			outputsView.ToggleRevealAll()
+			return nil
+		case config.ActionOutputsCopy:
+			out := outputsView.SelectedOutput()
+			if out == nil {
+				return nil
+			}
+			if err := components.CopyToClipboard(view.OutputValue(out)); err != nil {
+				a.statusBar.ShowMessage(fmt.Sprintf("[red]Copy failed: %s[white]", tview.Escape(err.Error())))
+				return nil
+			}
+			a.statusBar.ShowMessage(fmt.Sprintf("[green]Copied %s to clipboard[white]", out.Name))
+			return nil
+		}
+		return event
+	})
+
+	detail.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyTab, tcell.KeyEscape:
+			a.tviewApp.SetFocus(table)
+			return nil
+		}
+		return event
+	})
+
+	a.pages.AddPage("outputs", outputsView, true, true)
+	a.tviewApp.SetFocus(table)
+
+	go func() {
+		consumers, err := a.terraformDAO().OutputConsumers(st.Path)
+		a.tviewApp.QueueUpdateDraw(func() {
+			if err != nil {
+				a.statusBar.ShowMessage(fmt.Sprintf("[red]Failed to scan consumers: %s[white]", tview.Escape(err.Error())))
+			}
+			outputsView.SetConsumers(consumers)
+		})
+	}()
+}
+
+// showStackDashboard infers the stack dependency graph and opens the stack dashboard
+func (a *AppNew) showStackDashboard() {
+	a.statusBar.ShowMessage("[yellow]Scanning stack dependencies...[white]")
+	go func() {
+		graph, err := a.terraformDAO().StackGraph()
+		a.tviewApp.QueueUpdateDraw(func() {
+			a.statusBar.ShowDefault()
+			if err != nil {
+				a.contentView.DisplayText("Error", fmt.Sprintf("[red]Failed to scan stacks:[white] %s", tview.Escape(err.Error())))
+				return
+			}
+			a.openStackDashboard(graph)
+		})
+	}()
+}
+
+// openStackDashboard shows the stack dashboard for a dependency graph
+func (a *AppNew) openStackDashboard(graph *model.StackGraph) {
+	stacksView := view.NewStacksView(a.currentDir, graph, a.keymap)
+	table := stacksView.GetTable()
+	detail := stacksView.GetDetail()
+
+	closeView := func() {
+		a.pages.RemovePage("stacks")
+		a.focusOnTree = true
+		a.tviewApp.SetFocus(a.treeView)
+	}
+	backToTable := func() {
+		a.tviewApp.SetFocus(table)
+	}
+
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			closeView()
+			return nil
+		case tcell.KeyTab:
+			a.tviewApp.SetFocus(detail)
+			return nil
+		case tcell.KeyRune:
+			if event.Rune() == 'q' {
+				closeView()
+				return nil
+			}
+		}
+
+		switch a.keymap.Lookup(view.KeyName(event), config.ScopeStacks) {
+		case config.ActionStacksMark:
+			stacksView.ToggleMark()
+			return nil
+		case config.ActionStacksMarkDeps:
+			stacksView.MarkWithDependencies()
+			return nil
+		case config.ActionStacksPlan:
+			a.confirmBatch("Plan", graph, stacksView.Marked(), stacksView.Relative, backToTable)
+			return nil
+		case config.ActionStacksApply:
+			a.confirmBatch("Apply", graph, stacksView.Marked(), stacksView.Relative, backToTable)
+			return nil
+		case config.ActionStacksBatch:
+			a.showBatchDialog(stacksView.Marked())
+			return nil
+		}
+		return event
+	})
+
+	detail.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyTab, tcell.KeyEscape:
+			a.tviewApp.SetFocus(table)
+			return nil
+		}
+		return event
+	})
+
+	a.pages.AddPage("stacks", stacksView, true, true)
+	a.tviewApp.SetFocus(table)
+}
+
+// confirmBatch orders the stacks by dependency, asks for the tfvars name and confirms before
+// running an action across them. back restores focus when the batch is cancelled.
+func (a *AppNew) confirmBatch(action string, graph *model.StackGraph, stacks []string, name func(string) string, back func()) {
+	if len(stacks) == 0 {
+		return
+	}
+	ordered, err := graph.Order(stacks)
+	if err != nil {
+		a.statusBar.ShowMessage(fmt.Sprintf("[red]%s[white]", tview.Escape(err.Error())))
+		return
+	}
+
+	pattern, defaultFile := "*.tfvars", a.config.Commands.TfvarsFile
+	if action == "Init" {
+		pattern, defaultFile = "*.conf", a.config.Commands.InitConfFile
+	}
+	defaultLabel := "(none)"
+	if defaultFile != "" {
+		defaultLabel = fmt.Sprintf("(default: %s)", defaultFile)
+	}
+
+	envDialog := dialog.NewEnvDialog(
+		fmt.Sprintf(" 📄 Batch %s: %s ", action, strings.TrimPrefix(pattern, "*.")),
+		defaultLabel,
+		batchEnvs(ordered, pattern),
+		func(env string) {
+			a.pages.RemovePage("batch_env")
+			a.confirmBatchSteps(action, a.batchConfigFile(action, env), ordered, name, back)
+		},
+		func() {
+			a.pages.RemovePage("batch_env")
+			back()
+		},
+	)
+
+	a.pages.AddPage("batch_env", envDialog, true, true)
+	a.tviewApp.SetFocus(envDialog.GetList())
+}
+
+// prepareBatch renders the commands of a batch off the UI goroutine, since this looks up the
+// workspace and git branch of every stack, and passes the steps to show on the UI goroutine.
+// back, if set, is called when a template is invalid.
+func (a *AppNew) prepareBatch(action, configFile string, stacks []string, back func(), show func(steps []*components.BatchStep)) {
+	root := a.currentDir
+	a.statusBar.ShowMessage(fmt.Sprintf("[yellow]Preparing batch %s...[white]", strings.ToLower(action)))
+	go func() {
+		steps, err := components.PrepareBatch(action, configFile, stacks, a.config)
+		a.tviewApp.QueueUpdateDraw(func() {
+			if a.currentDir != root {
+				return
+			}
+			a.statusBar.ShowDefault()
+			if err != nil {
+				a.statusBar.ShowMessage(fmt.Sprintf("[red]Invalid %s template: %s[white]", strings.ToLower(action), tview.Escape(err.Error())))
+				if back != nil {
+					back()
+				}
+				return
+			}
+			show(steps)
+		})
+	}()
+}
+
+// confirmBatchSteps prepares the batch with a config file and shows what each stack runs with.
+// Nothing runs if a stack lacks the config file.
+func (a *AppNew) confirmBatchSteps(action, configFile string, ordered []string, name func(string) string, back func()) {
+	a.prepareBatch(action, configFile, ordered, back, func(steps []*components.BatchStep) {
+		a.showBatchSteps(action, configFile, steps, name, back)
+	})
+}
+
+// showBatchSteps shows the prepared steps of a batch for confirmation
+func (a *AppNew) showBatchSteps(action, configFile string, steps []*components.BatchStep, name func(string) string, back func()) {
+	if skipped := components.SkippedSteps(steps); len(skipped) > 0 {
+		var names []string
+		for _, step := range skipped {
+			names = append(names, name(step.Stack))
+		}
+		a.statusBar.ShowMessage(fmt.Sprintf("[red]Batch %s aborted: %s not found in %s[white]",
+			strings.ToLower(action), tview.Escape(configFile), tview.Escape(strings.Join(names, ", "))))
+		back()
+		return
+	}
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "%s %d stack(s) in dependency order?\n\n", action, len(steps))
+	for i, step := range steps {
+		varFile := "(none)"
+		if step.Info.ConfigFile != "" {
+			varFile = step.Info.ConfigFile
+			if rel, err := filepath.Rel(step.Stack, varFile); err == nil && !strings.HasPrefix(rel, "..") {
+				varFile = rel
+			}
+		}
+		profile := "(none)"
+		if step.Info.Env != nil && step.Info.Env.Profile != "" {
+			profile = step.Info.Env.Profile
+		}
+		workspace := step.Info.Vars["workspace"]
+		if workspace == "" {
+			workspace = "default"
+		}
+		fmt.Fprintf(&text, "%d. %s\n   var file: %s · profile: %s · workspace: %s\n",
+			i+1, name(step.Stack), varFile, profile, workspace)
+	}
+	if promptsForApproval(action) {
+		text.WriteString("\nEach stack runs with -auto-approve. The batch stops at the first failure.")
+	} else {
+		text.WriteString("\nThe batch stops at the first failure.")
+	}
+
+	modal := tview.NewModal().
+		SetText(text.String()).
+		AddButtons([]string{"Run", "Cancel"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			a.pages.RemovePage("batch_confirm")
+			if buttonLabel != "Run" {
+				back()
+				return
+			}
+			a.pages.RemovePage("stacks")
+			a.runBatch(action, steps, name)
+		})
+	modal.SetBackgroundColor(tcell.ColorBlack)
+	if promptsForApproval(action) {
+		modal.SetBackgroundColor(tcell.ColorDarkRed)
+	}
+
+	a.pages.AddPage("batch_confirm", modal, true, true)
+	a.tviewApp.SetFocus(modal)
+}
+
+// runBatch runs prepared batch steps with streaming output and a summary at the end
+func (a *AppNew) runBatch(action string, steps []*components.BatchStep, name func(string) string) {
+	a.focusOnTree = false
+	a.tviewApp.SetFocus(a.contentView)
+
+	a.contentView.Clear()
+	a.contentView.SetTitle(fmt.Sprintf(" 🚀 Batch %s ", action))
+	fmt.Fprintf(a.contentView, "[yellow]Batch Terraform %s across %d stack(s)[white]\n", action, len(steps))
+	fmt.Fprintf(a.contentView, "[cyan]%s[white]\n\n", strings.Repeat("─", 60))
+
+	historyDB, release := a.holdHistory()
+	go func() {
+		defer release()
+
+		// Output of the running step, kept for opening it from the toast
+		var mu sync.Mutex
+		var stepOutput strings.Builder
+		appendLine := func(line string) {
+			mu.Lock()
+			stepOutput.WriteString(line + "\n")
+			mu.Unlock()
+			a.tviewApp.QueueUpdateDraw(func() {
+				w := tview.ANSIWriter(a.contentView)
+				w.Write([]byte(line + "\n"))
+				a.contentView.ScrollToEnd()
+			})
+		}
+
+		err := components.RunBatch(action, steps, a.config, promptsForApproval(action), appendLine, func(step *components.BatchStep) {
+			if recordsHistory(action) {
+				a.saveHistory(historyDB, action, step.Info, step.StartTime, step.Err)
+			}
+			mu.Lock()
+			result := &components.RunResult{
+				Stack:    step.Info.WorkDir,
+				Action:   action,
+				Changes:  step.Changes,
+				Err:      step.Err,
+				Finished: time.Now(),
+				Output:   stepOutput.String(),
+			}
+			stepOutput.Reset()
+			mu.Unlock()
+			a.tviewApp.QueueUpdateDraw(func() {
+				a.recordResult(result)
+			})
+		})
+
+		a.tviewApp.QueueUpdateDraw(func() {
+			fmt.Fprintf(a.contentView, "\n[cyan]%s[white]\n[yellow]Summary[white]\n", strings.Repeat("─", 60))
+			for i, step := range steps {
+				status := "[gray]skipped[white]"
+				if step.Ran && step.Err == nil {
+					status = fmt.Sprintf("[green]✅ ok[white] (%s)", step.Duration.Round(time.Second))
+				} else if step.Ran {
+					status = fmt.Sprintf("[red]❌ failed[white] (%s)", step.Duration.Round(time.Second))
+				}
+				fmt.Fprintf(a.contentView, "%2d. %-40s %s\n", i+1, tview.Escape(name(step.Stack)), status)
+			}
+			if err != nil {
+				fmt.Fprintf(a.contentView, "\n[red]Stopped:[white] %s\n", tview.Escape(err.Error()))
+			}
+			fmt.Fprintf(a.contentView, "\n[green]Done.[white]")
+			if recordsHistory(action) {
+				fmt.Fprintf(a.contentView, "\n[gray](Saved to history)[white]")
+			}
+			a.contentView.ScrollToEnd()
+		})
+	}()
+}
+
+// showGraph runs terraform graph for the stack at path and opens the graph view
+func (a *AppNew) showGraph(path string) {
+	dir := stackDir(path)
+	if dir == "" {
+		a.contentView.DisplayText("Graph", "[yellow]Select a directory containing .tf files to view its graph[white]")
+		return
+	}
+
+	name := a.stackName(dir)
+	a.statusBar.ShowMessage(fmt.Sprintf("[yellow]Running terraform graph for %s...[white]", name))
+	go func() {
+		graph, err := a.terraformDAO().GetGraph(dir)
+		a.tviewApp.QueueUpdateDraw(func() {
+			a.statusBar.UpdatePath(path)
+			if err != nil {
+				a.contentView.DisplayText("Error", fmt.Sprintf("[red]Failed to read graph:[white] %s", tview.Escape(err.Error())))
+				return
+			}
+			a.openGraphView(dir, graph)
+		})
+	}()
+}
+
+// openGraphView shows a parsed resource graph
+func (a *AppNew) openGraphView(dir string, graph *model.ResourceGraph) {
+	graphView := view.NewGraphView(a.stackName(dir), graph, a.keymap)
+	tree := graphView.GetTree()
+	filter := graphView.GetFilter()
+	detail := graphView.GetDetail()
+
+	closeView := func() {
+		a.pages.RemovePage("graph")
+		a.focusOnTree = true
+		a.tviewApp.SetFocus(a.treeView)
+	}
+
+	tree.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			closeView()
+			return nil
+		case tcell.KeyTab:
+			a.tviewApp.SetFocus(detail)
+			return nil
+		case tcell.KeyRune:
+			if event.Rune() == 'q' {
+				closeView()
+				return nil
+			}
+		}
+
+		switch a.keymap.Lookup(view.KeyName(event), config.ScopeGraph) {
+		case config.ActionGraphFilter:
+			a.tviewApp.SetFocus(filter)
+			return nil
+		case config.ActionGraphExport:
+			a.showGraphExport(dir, graph)
+			return nil
+		}
+		return event
+	})
+
+	detail.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyTab, tcell.KeyEscape:
+			a.tviewApp.SetFocus(tree)
+			return nil
+		}
+		return event
+	})
+
+	// Enter keeps the filter, Esc clears it
+	filter.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEscape {
+			filter.SetText("")
+		}
+		a.tviewApp.SetFocus(tree)
+	})
+
+	a.pages.AddPage("graph", graphView, true, true)
+	a.tviewApp.SetFocus(tree)
+}
+
+// showGraphExport asks for a file and exports the graph; the extension picks the format
+func (a *AppNew) showGraphExport(dir string, graph *model.ResourceGraph) {
+	inputDialog := dialog.NewInputDialog(
+		" 💾 Export Graph ",
+		"File (.dot/.svg/.png)",
+		filepath.Join(dir, "graph.dot"),
+		"Export",
+		func(path string) {
+			a.pages.RemovePage("graph_export")
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(dir, path)
+			}
+			if err := dao.ExportGraph(graph, path); err != nil {
+				a.statusBar.ShowMessage(fmt.Sprintf("[red]Export failed: %s[white]", tview.Escape(err.Error())))
+				return
+			}
+			a.statusBar.ShowMessage(fmt.Sprintf("[green]Graph exported to %s[white]", tview.Escape(path)))
+		},
+		func() {
+			a.pages.RemovePage("graph_export")
+		},
+	)
+
+	a.pages.AddPage("graph_export", inputDialog, true, true)
+	a.tviewApp.SetFocus(inputDialog.GetForm())
+}
+
+// showBatchDialog asks how to run init, plan or validate across several stacks
+func (a *AppNew) showBatchDialog(paths []string) {
+	var stacks []string
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		if dir := stackDir(path); dir != "" && !seen[dir] {
+			seen[dir] = true
+			stacks = append(stacks, dir)
+		}
+	}
+	if len(stacks) == 0 {
+		a.statusBar.ShowMessage("[yellow]Mark directories containing .tf files with Space first[white]")
+		return
+	}
+
+	var names []string
+	for _, s := range stacks {
+		names = append(names, a.stackName(s))
+	}
+
+	batchDialog := dialog.NewBatchDialog(names, batchEnvs(stacks, "*.tfvars", "*.conf"), a.config.Defaults.Parallelism(),
+		func(action, env string, parallelism int) {
+			a.pages.RemovePage("batch_dialog")
+			a.runParallelBatch(action, env, stacks, parallelism)
+		},
+		func() {
+			a.pages.RemovePage("batch_dialog")
+		},
+	)
+
+	a.pages.AddPage("batch_dialog", batchDialog, true, true)
+	a.tviewApp.SetFocus(batchDialog.GetForm())
+}
+
+// batchEnvs returns the names of the files matching patterns in the config directories of the stacks
+func batchEnvs(stacks []string, patterns ...string) []string {
+	seen := make(map[string]bool)
+	var envs []string
+	for _, stack := range stacks {
+		for _, pattern := range patterns {
+			files, _ := filepath.Glob(filepath.Join(stack, "config", pattern))
+			for _, f := range files {
+				name := strings.TrimSuffix(filepath.Base(f), filepath.Ext(f))
+				if !seen[name] {
+					seen[name] = true
+					envs = append(envs, name)
+				}
+			}
+		}
+	}
+	sort.Strings(envs)
+	return envs
+}
+
+// batchConfigFile returns the config file of a batch action for a tfvars/conf name,
+// or the configured default when env is ""
+func (a *AppNew) batchConfigFile(action, env string) string {
+	switch action {
+	case "Init":
+		if env != "" {
+			return filepath.Join("config", env+".conf")
+		}
+		return a.config.Commands.InitConfFile
+	case "Plan", "Apply":
+		if env != "" {
+			return filepath.Join("config", env+".tfvars")
+		}
+		return a.config.Commands.TfvarsFile
+	}
+	return ""
+}
+
+// runParallelBatch runs an action across stacks in parallel and shows the summary table
+func (a *AppNew) runParallelBatch(action, env string, stacks []string, parallelism int) {
+	a.prepareBatch(action, a.batchConfigFile(action, env), stacks, nil, func(steps []*components.BatchStep) {
+		a.runParallelSteps(action, steps, parallelism)
+	})
+}
+
+// runParallelSteps runs prepared batch steps in parallel and shows the summary table
+func (a *AppNew) runParallelSteps(action string, steps []*components.BatchStep, parallelism int) {
+	rows := make([]view.BatchRow, len(steps))
+	for i, step := range steps {
+		rows[i] = view.BatchRow{Stack: a.stackName(step.Stack), State: view.BatchQueued}
+		if step.Skipped {
+			rows[i].State = view.BatchSkipped
+			rows[i].Output = []string{fmt.Sprintf("[orange]Skipped:[white] %s", tview.Escape(step.Err.Error()))}
+		}
+	}
+	batchView := view.NewBatchView(action, rows)
+	table := batchView.GetTable()
+	output := batchView.GetOutput()
+
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			a.pages.RemovePage("batch")
+			a.focusOnTree = true
+			a.tviewApp.SetFocus(a.treeView)
+			return nil
+		case tcell.KeyTab:
+			a.tviewApp.SetFocus(output)
+			return nil
+		}
+		return event
+	})
+	output.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyTab, tcell.KeyEscape:
+			a.tviewApp.SetFocus(table)
+			return nil
+		}
+		return event
+	})
+
+	a.pages.RemovePage("stacks")
+	a.pages.AddPage("batch", batchView, true, true)
+	a.tviewApp.SetFocus(table)
+
+	go func() {
+		start := time.Now()
+		components.RunParallel(action, steps, a.config, parallelism, func(i int, step components.BatchStep) {
+			row := view.BatchRow{
+				Stack:    a.stackName(step.Stack),
+				State:    view.BatchRunning,
+				Duration: step.Duration,
+				Output:   step.Output,
+			}
+			if step.Ran {
+				row.State = view.BatchOK
+				if step.Err != nil {
+					row.State = view.BatchFailed
+				}
+			}
+			if step.Changes != nil {
+				row.Changes = step.Changes.String()
+			}
+			a.tviewApp.QueueUpdateDraw(func() {
+				batchView.Update(i, row)
+				if step.Ran {
+					a.recordResult(&components.RunResult{
+						Stack:    step.Info.WorkDir,
+						Action:   action,
+						Changes:  step.Changes,
+						Err:      step.Err,
+						Finished: time.Now(),
+						Output:   strings.Join(step.Output, "\n"),
+					})
+				}
+			})
+		})
+
+		failed, skipped := 0, 0
+		for _, step := range steps {
+			if step.Skipped {
+				skipped++
+			} else if step.Err != nil {
+				failed++
+			}
+		}
+		a.tviewApp.QueueUpdateDraw(func() {
+			color := "green"
+			if failed+skipped > 0 {
+				color = "red"
+			}
+			summary := fmt.Sprintf("%d of %d failed", failed, len(steps))
+			if skipped > 0 {
+				summary += fmt.Sprintf(", %d skipped", skipped)
+			}
+			a.statusBar.ShowMessage(fmt.Sprintf("[%s]Batch %s finished: %s (%s)[white]",
+				color, strings.ToLower(action), summary, time.Since(start).Round(time.Second)))
+		})
+	}()
+}
+
+// showTargetPicker loads the state and the saved plan of the stack at path and opens the target picker
+func (a *AppNew) showTargetPicker(path string) {
+	dir := stackDir(path)
+	if dir == "" {
+		a.contentView.DisplayText("Targets", "[yellow]Select a directory containing .tf files to pick targets[white]")
+		return
+	}
+
+	a.statusBar.ShowMessage(fmt.Sprintf("[yellow]Reading state of %s...[white]", a.stackName(dir)))
+	go func() {
+		state, err := a.terraformDAO().GetState(dir)
+		var plan *model.Plan
+		var planErr error
+		if err == nil {
+			plan, planErr = a.terraformDAO().GetPlan(dir)
+		}
+		a.tviewApp.QueueUpdateDraw(func() {
+			a.statusBar.UpdatePath(path)
+			if err != nil {
+				a.contentView.DisplayText("Error", fmt.Sprintf("[red]Failed to read state:[white] %s", tview.Escape(err.Error())))
+				return
+			}
+			if planErr != nil {
+				a.statusBar.ShowMessage(fmt.Sprintf("[yellow]Ignoring the saved plan: %s[white]", tview.Escape(planErr.Error())))
+			}
+			if plan == nil {
+				plan = a.plans[dir]
+			}
+			a.openTargetDialog(dir, state, plan)
+		})
+	}()
+}
+
+// openTargetDialog lets the user pick modules and managed resources of a state as -target addresses.
+// Addresses a plan changes but the state lacks, such as resources to create, are added after them.
+func (a *AppNew) openTargetDialog(dir string, state *model.State, plan *model.Plan) {
+	addresses := state.ModuleAddresses()
+	for _, res := range state.Resources() {
+		if res.Mode == "managed" {
+			addresses = append(addresses, res.Address)
+		}
+	}
+
+	notes := make(map[string]string)
+	if plan != nil {
+		known := make(map[string]bool)
+		for _, addr := range addresses {
+			known[addr] = true
+		}
+		planned, actions := plan.ChangedAddresses()
+		for _, addr := range planned {
+			if action := actions[addr]; action != "" {
+				notes[addr] = "plan: " + action
+			}
+			if !known[addr] {
+				addresses = append(addresses, addr)
+			}
+		}
+	}
+	if len(addresses) == 0 {
+		a.contentView.DisplayText("Targets", "[yellow]Neither the state nor a plan has resources to target[white]")
+		return
+	}
+
+	targetDialog := dialog.NewTargetDialog(
+		a.stackName(dir),
+		addresses,
+		func(action string, targets []string) {
+			a.pages.RemovePage("target_select")
+			template := a.config.Commands.PlanTemplate
+			if action == "Apply" {
+				template = a.config.Commands.ApplyTemplate
+			}
+			a.selectConfigFile(dir, "*.tfvars", fmt.Sprintf("Select Terraform Variables File for Targeted %s", action), func(configFile string) {
+				a.showCommandConfirmation(action, dir, template, configFile, func(info *components.TerraformCommandInfo) {
+					info.Target(targets...)
+				})
+			})
+		},
+		func() {
+			a.pages.RemovePage("target_select")
+			a.focusOnTree = true
+			a.tviewApp.SetFocus(a.treeView)
+		},
+	)
+
+	targetDialog.SetNotes(notes)
+
+	a.pages.AddPage("target_select", targetDialog, true, true)
+	a.tviewApp.SetFocus(targetDialog.GetList())
+}
+
+// showStateMove asks for the destination address of a resource, completing known addresses.
+// back restores focus when the move is cancelled.
+func (a *AppNew) showStateMove(dir, source string, state *model.State, back func()) {
+	var known []string
+	for _, res := range state.Resources() {
+		known = append(known, res.Address)
+	}
+	for _, module := range state.ModuleAddresses() {
+		known = append(known, module+".")
+	}
+
+	inputDialog := dialog.NewInputDialog(
+		" 🔀 Move Resource ",
+		"Destination",
+		source,
+		"Move",
+		func(destination string) {
+			a.pages.RemovePage("state_move")
+			if destination == source {
+				back()
+				return
+			}
+			a.confirmStateOperation(components.NewStateMove(dir, source, destination), back)
+		},
+		func() {
+			a.pages.RemovePage("state_move")
+			back()
+		},
+	)
+	inputDialog.SetAutocomplete(func(text string) []string {
+		if text == "" {
+			return nil
+		}
+		var entries []string
+		for _, addr := range known {
+			if strings.HasPrefix(addr, text) && addr != text {
+				entries = append(entries, addr)
+			}
+		}
+		return entries
+	})
+
+	a.pages.AddPage("state_move", inputDialog, true, true)
+	a.tviewApp.SetFocus(inputDialog.GetForm())
+}
+
+// showImport asks for a resource address and ID, then the tfvars file to import with.
+// back restores focus when the import is cancelled.
+func (a *AppNew) showImport(dir, address string, back func()) {
+	importDialog := dialog.NewImportDialog(
+		address,
+		func(address, id string) {
+			a.pages.RemovePage("state_import")
+			a.pages.RemovePage("state")
+			a.selectConfigFile(dir, "*.tfvars", "Select Terraform Variables File for Import", func(configFile string) {
+				a.confirmStateOperation(components.NewImport(dir, configFile, address, id), func() {
+					a.focusOnTree = true
+					a.tviewApp.SetFocus(a.treeView)
+				})
+			})
+		},
+		func(address, id string) {
+			a.pages.RemovePage("state_import")
+			a.pages.RemovePage("state")
+			path, err := dao.WriteImportBlock(dir, address, id)
+			a.reviewGeneratedFile(path, err)
+		},
+		func() {
+			a.pages.RemovePage("state_import")
+			back()
+		},
+	)
+
+	a.pages.AddPage("state_import", importDialog, true, true)
+	a.tviewApp.SetFocus(importDialog.GetForm())
+}
+
+// showReplace offers a plan or apply that replaces a resource.
+// back restores focus when the replace is cancelled.
+func (a *AppNew) showReplace(dir, address string, back func()) {
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("Replace %s?\n\nRun plan or apply with -replace.\nApply backs up the state to %s first.", address, a.config.BackupDir())).
+		AddButtons([]string{"Plan", "Apply", "Cancel"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			a.pages.RemovePage("state_replace")
+			replace := func(info *components.TerraformCommandInfo) {
+				info.Replace(address)
+			}
+			switch buttonLabel {
+			case "Plan":
+				a.pages.RemovePage("state")
+				a.selectConfigFile(dir, "*.tfvars", "Select Terraform Variables File for Plan", func(configFile string) {
+					a.showCommandConfirmation("Plan", dir, a.config.Commands.PlanTemplate, configFile, replace)
+				})
+			case "Apply":
+				a.pages.RemovePage("state")
+				a.selectConfigFile(dir, "*.tfvars", "Select Terraform Variables File for Apply", func(configFile string) {
+					a.showCommandConfirmation("Apply", dir, a.config.Commands.ApplyTemplate, configFile, replace)
+				})
+			default:
+				back()
+			}
+		})
+	modal.SetBackgroundColor(tcell.ColorBlack)
+	modal.SetBorderColor(tcell.NewRGBColor(255, 165, 0))
+	modal.SetButtonBackgroundColor(tcell.NewRGBColor(50, 50, 50))
+	modal.SetButtonTextColor(tcell.ColorWhite)
+
+	a.pages.AddPage("state_replace", modal, true, true)
+	a.tviewApp.SetFocus(modal)
+}
+
+// confirmStateOperation confirms and runs an operation that modifies state.
+// back restores focus when the operation is cancelled.
+func (a *AppNew) confirmStateOperation(op *components.StateOperation, back func()) {
+	confirmDialog := dialog.NewConfirmDialog(
+		fmt.Sprintf("Run in %s?\n\n%s\n\nThe current state is backed up to %s first.",
+			a.stackName(op.WorkDir), op.Command().String(), a.config.BackupDir()),
+		func() {
+			a.pages.RemovePage("state_confirm")
+			a.pages.RemovePage("state")
+			a.runStateOperation(op)
+		},
+		func() {
+			a.pages.RemovePage("state_confirm")
+			back()
+		},
+	)
+
+	a.pages.AddPage("state_confirm", confirmDialog, true, true)
+	a.tviewApp.SetFocus(confirmDialog)
+}
+
+// runStateOperation backs up the state, runs the operation and records it in history
+func (a *AppNew) runStateOperation(op *components.StateOperation) {
+	a.focusOnTree = false
+	a.tviewApp.SetFocus(a.contentView)
+
+	a.contentView.Clear()
+	a.contentView.SetTitle(fmt.Sprintf(" 🗃  Terraform %s ", op.Action))
+	fmt.Fprintf(a.contentView, "[yellow]Executing Terraform %s[white]\n", op.Action)
+	fmt.Fprintf(a.contentView, "[cyan]Directory:[white] %s\n", op.WorkDir)
+	fmt.Fprintf(a.contentView, "[cyan]Command:[white] %s\n", tview.Escape(op.Command().String()))
+	fmt.Fprintf(a.contentView, "[cyan]%s[white]\n\n", strings.Repeat("─", 60))
+
+	historyDB, release := a.holdHistory()
+	go func() {
+		defer release()
+
+		appendLine := func(line string) {
+			a.tviewApp.QueueUpdateDraw(func() {
+				w := tview.ANSIWriter(a.contentView)
+				w.Write([]byte(line + "\n"))
+				a.contentView.ScrollToEnd()
+			})
+		}
+
+		startTime := time.Now()
+		err := components.RunStateOperation(op, a.config, appendLine)
+
+		saved := false
+		if historyDB != nil {
+			user := os.Getenv("USER")
+			if user == "" {
+				user = "unknown"
+			}
+			entry := &db.HistoryEntry{
+				Directory:  op.WorkDir,
+				Action:     op.Action,
+				Timestamp:  startTime,
+				User:       user,
+				Branch:     a.gitManager.CurrentBranch(op.WorkDir),
+				Workspace:  a.terraformDAO().CurrentWorkspace(op.WorkDir),
+				ConfigFile: op.ConfigFile,
+				Addresses:  strings.Join(op.Addresses, "\n"),
+				Success:    err == nil,
+			}
+			if err != nil {
+				entry.ErrorMsg = err.Error()
+			}
+			if saveErr := historyDB.AddEntry(entry); saveErr != nil {
+				fmt.Fprintf(os.Stderr, "Failed to save history: %v\n", saveErr)
+			} else {
+				saved = true
+			}
+		}
+
+		a.tviewApp.QueueUpdateDraw(func() {
+			if err != nil {
+				fmt.Fprintf(a.contentView, "\n[red]Terraform %s failed:[white] %s\n", op.Action, tview.Escape(err.Error()))
+			} else {
+				fmt.Fprintf(a.contentView, "\n[green]Done.[white]\n")
+			}
+			if saved {
+				fmt.Fprintf(a.contentView, "[gray](Saved to history)[white]")
+			}
+			a.contentView.ScrollToEnd()
+		})
+	}()
+}
+
+// showMovedSuggestions asks for a tfvars file and suggests moved blocks from a plan
+func (a *AppNew) showMovedSuggestions(path string) {
+	dir := stackDir(path)
+	if dir == "" {
+		a.contentView.DisplayText("Moved Blocks", "[yellow]Select a directory containing .tf files to suggest moved blocks[white]")
+		return
+	}
+
+	a.selectConfigFile(dir, "*.tfvars", "Select Terraform Variables File for Moved Blocks", func(configFile string) {
+		a.suggestMovedBlocks(dir, configFile)
+	})
+}
+
+// suggestMovedBlocks plans the stack and offers moved blocks for resources that only changed address
+func (a *AppNew) suggestMovedBlocks(dir, configFile string) {
+	a.contentView.Clear()
+	a.contentView.SetTitle(" 🔀 Moved Blocks ")
+	fmt.Fprintf(a.contentView, "[yellow]Planning to find moved resources[white]\n")
+	fmt.Fprintf(a.contentView, "[cyan]Directory:[white] %s\n", dir)
+	fmt.Fprintf(a.contentView, "[cyan]%s[white]\n\n", strings.Repeat("─", 60))
+
+	go func() {
+		appendLine := func(line string) {
+			a.tviewApp.QueueUpdateDraw(func() {
+				w := tview.ANSIWriter(a.contentView)
+				w.Write([]byte(line + "\n"))
+				a.contentView.ScrollToEnd()
+			})
+		}
+
+		plan, err := components.PlanChanges(dir, configFile, a.config, appendLine)
+		var moves []model.MovedBlock
+		if err == nil {
+			moves = dao.SuggestMoves(plan)
+		}
+
+		a.tviewApp.QueueUpdateDraw(func() {
+			if plan != nil {
+				a.plans[dir] = plan
+			}
+			fmt.Fprintf(a.contentView, "\n[cyan]%s[white]\n", strings.Repeat("─", 60))
+			if err != nil {
+				fmt.Fprintf(a.contentView, "[red]Error:[white] %s\n", tview.Escape(err.Error()))
+				return
+			}
+			if len(moves) == 0 {
+				fmt.Fprintf(a.contentView, "[green]No resources are destroyed and recreated unchanged at another address.[white]\n")
+				a.contentView.ScrollToEnd()
+				return
+			}
+
+			fmt.Fprintf(a.contentView, "[yellow]Suggested moved blocks:[white]\n")
+			for _, move := range moves {
+				fmt.Fprintf(a.contentView, "  %s [gray]→[white] %s\n", tview.Escape(move.From), tview.Escape(move.To))
+			}
+			a.contentView.ScrollToEnd()
+
+			confirmDialog := dialog.NewConfirmDialog(
+				fmt.Sprintf("Write %d moved block(s) to %s/moved.tf and open it for review?", len(moves), a.stackName(dir)),
+				func() {
+					a.pages.RemovePage("moved_confirm")
+					path, written, err := dao.WriteMovedBlocks(dir, moves)
+					if err == nil && written == 0 {
+						a.contentView.DisplayText("Moved Blocks", "[yellow]All suggested moved blocks are already in moved.tf[white]")
+						return
+					}
+					a.reviewGeneratedFile(path, err)
+				},
+				func() {
+					a.pages.RemovePage("moved_confirm")
+					a.focusOnTree = false
+					a.tviewApp.SetFocus(a.contentView)
+				},
+			)
+			a.pages.AddPage("moved_confirm", confirmDialog, true, true)
+			a.tviewApp.SetFocus(confirmDialog)
+		})
+	}()
+}
+
+// reviewGeneratedFile opens a file written by t9s in the editor and shows it afterwards
+func (a *AppNew) reviewGeneratedFile(path string, err error) {
+	if err != nil {
+		a.contentView.DisplayText("Error", fmt.Sprintf("[red]%s[white]", tview.Escape(err.Error())))
+		return
+	}
+	a.currentFile = path
+	a.executor.EditFile(path, 0)
+	a.contentView.DisplayFile(path)
+	a.focusOnTree = true
+	a.tviewApp.SetFocus(a.treeView)
+}
+
+// stackName returns the path of a stack, or of a file in it, relative to the terraform root
+func (a *AppNew) stackName(dir string) string {
+	if rel, err := filepath.Rel(a.currentDir, dir); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return dir
+}
+
+// rebuildMainPage rebuilds the main page with updated views
+func (a *AppNew) rebuildMainPage() {
+	// Main content layout
+	mainFlex := tview.NewFlex().
+		AddItem(a.treeView, 0, 1, true).
+		AddItem(a.contentPane, 0, 2, false)
+	mainFlex.SetBackgroundColor(tcell.ColorBlack)
+
+	// Root layout
+	mainPage := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(a.headerView, 8, 0, false).
+		AddItem(mainFlex, 0, 1, true).
+		AddItem(a.toastBar, 0, 0, false).
+		AddItem(a.statusBar, 1, 0, false)
+	mainPage.SetBackgroundColor(tcell.ColorBlack)
+	a.mainLayout = mainPage
+
+	a.pages.RemovePage("main")
+	a.pages.AddPage("main", mainPage, true, true)
+}
+
+// showInitConfirmation shows file selection for init config
+func (a *AppNew) showInitConfirmation(path string) {
+	a.selectConfigFile(path, "*.conf", "Select Init Config File", func(configFile string) {
+		a.showCommandConfirmation("Init", path, a.config.Commands.InitTemplate, configFile, nil)
+	})
 }
 
-// showApplyConfirmationWithFile shows confirmation dialog with selected file
-func (a *AppNew) showApplyConfirmationWithFile(path, configFile string) {
-	info := components.GetTerraformCommandInfo(path, a.config.Commands.ApplyTemplate, configFile, a.config)
-
-	confirmDialog := dialog.NewTerraformConfirmDialog(
-		"terraform apply",
-		info.WorkDir,
-		info.ConfigFile,
-		info.Content,
-		// Execute: normal execution (terraform will ask for 'yes')
-		func() {
-			a.pages.RemovePage("confirm_tf")
-			a.executeTerraformCommand("Apply", info.WorkDir, info.Command, info.ConfigFile, info.Content)
-		},
-		// Auto Approve: add -auto-approve flag
-		func() {
-			a.pages.RemovePage("confirm_tf")
-			cmdWithAutoApprove := info.Command + " -auto-approve"
-			a.executeTerraformCommand("Apply", info.WorkDir, cmdWithAutoApprove, info.ConfigFile, info.Content)
-		},
-		// Cancel
-		func() {
-			a.pages.RemovePage("confirm_tf")
-			a.focusOnTree = true
-			a.tviewApp.SetFocus(a.treeView)
-		},
-	)
+// showPlanConfirmation shows file selection for plan tfvars
+func (a *AppNew) showPlanConfirmation(path string) {
+	a.selectConfigFile(path, "*.tfvars", "Select Terraform Variables File for Plan", func(configFile string) {
+		a.showCommandConfirmation("Plan", path, a.config.Commands.PlanTemplate, configFile, nil)
+	})
+}
 
-	a.pages.AddPage("confirm_tf", confirmDialog, true, true)
-	if form := confirmDialog.GetForm(); form != nil {
-		a.tviewApp.SetFocus(form)
+// showApplyConfirmation shows file selection for apply tfvars
+func (a *AppNew) showApplyConfirmation() {
+	path := a.treeView.GetCurrentPath()
+	if path == "" {
+		return
 	}
+
+	a.selectConfigFile(path, "*.tfvars", "Select Terraform Variables File for Apply", func(configFile string) {
+		a.showCommandConfirmation("Apply", path, a.config.Commands.ApplyTemplate, configFile, nil)
+	})
 }
 
 // showDestroyConfirmation shows file selection for destroy tfvars
 func (a *AppNew) showDestroyConfirmation(path string) {
+	a.selectConfigFile(path, "*.tfvars", "Select Terraform Variables File for Destroy", func(configFile string) {
+		a.showCommandConfirmation("Destroy", path, a.config.Commands.DestroyTemplate, configFile, nil)
+	})
+}
+
+// showRefreshConfirmation offers a refresh-only plan, which only shows the drift, or a
+// refresh-only apply, which accepts it into the state, then shows file selection for tfvars
+func (a *AppNew) showRefreshConfirmation(path string) {
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("Refresh-only in %s\n\nPlan shows changes made outside of Terraform.\nApply accepts them into the state.", a.stackName(path))).
+		AddButtons([]string{"Plan", "Apply", "Cancel"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			a.pages.RemovePage("refresh_choice")
+			switch buttonLabel {
+			case "Plan":
+				a.selectConfigFile(path, "*.tfvars", "Select Terraform Variables File for Refresh-Only Plan", func(configFile string) {
+					a.showCommandConfirmation("Refresh Plan", path, a.config.Commands.RefreshPlanTemplate, configFile, nil)
+				})
+			case "Apply":
+				a.selectConfigFile(path, "*.tfvars", "Select Terraform Variables File for Refresh-Only", func(configFile string) {
+					a.showCommandConfirmation("Refresh", path, a.config.Commands.RefreshTemplate, configFile, nil)
+				})
+			default:
+				a.focusOnTree = true
+				a.tviewApp.SetFocus(a.treeView)
+			}
+		})
+	modal.SetBackgroundColor(tcell.ColorBlack)
+	modal.SetButtonBackgroundColor(tcell.NewRGBColor(50, 50, 50))
+	modal.SetButtonTextColor(tcell.ColorWhite)
+
+	a.pages.AddPage("refresh_choice", modal, true, true)
+	a.tviewApp.SetFocus(modal)
+}
+
+// selectConfigFile lets the user pick a file from the config directory of path.
+// onSelect receives an empty string when there is no config directory.
+func (a *AppNew) selectConfigFile(path, pattern, title string, onSelect func(configFile string)) {
 	// Get directory path
 	info, err := os.Stat(path)
 	workDir := path
@@ -595,19 +2705,19 @@ func (a *AppNew) showDestroyConfirmation(path string) {
 	configDir := filepath.Join(workDir, "config")
 	if _, err := os.Stat(configDir); os.IsNotExist(err) {
 		// No config directory, use default
-		a.showDestroyConfirmationWithFile(path, "")
+		onSelect("")
 		return
 	}
 
 	// Show file selection dialog
 	fileDialog := dialog.NewFileSelectionDialog(
 		configDir,
-		"*.tfvars",
-		"Select Terraform Variables File for Destroy",
+		pattern,
+		title,
 		func(filePath, content string) {
 			// File selected, show confirmation
 			a.pages.RemovePage("file_selection")
-			a.showDestroyConfirmationWithFile(path, filePath)
+			onSelect(filePath)
 		},
 		func() {
 			// Cancelled
@@ -621,25 +2731,58 @@ func (a *AppNew) showDestroyConfirmation(path string) {
 	a.tviewApp.SetFocus(fileDialog.GetList())
 }
 
-// showDestroyConfirmationWithFile shows confirmation dialog with selected file
-func (a *AppNew) showDestroyConfirmationWithFile(path, configFile string) {
-	info := components.GetTerraformCommandInfo(path, a.config.Commands.DestroyTemplate, configFile, a.config)
+// showCommandConfirmation renders the command template and shows the confirmation dialog.
+// adjust, if set, can add arguments to the rendered command.
+func (a *AppNew) showCommandConfirmation(action, path, template, configFile string, adjust func(info *components.TerraformCommandInfo)) {
+	// Rendering looks up the workspace and git branch of the stack, so it runs off the UI goroutine
+	root := a.currentDir
+	a.statusBar.ShowMessage(fmt.Sprintf("[yellow]Preparing %s...[white]", strings.ToLower(action)))
+	go func() {
+		info := components.GetTerraformCommandInfo(path, template, configFile, a.config)
+		a.tviewApp.QueueUpdateDraw(func() {
+			if a.currentDir != root {
+				return
+			}
+			a.statusBar.ShowDefault()
+			a.confirmCommand(action, template, info, adjust)
+		})
+	}()
+}
+
+// confirmCommand shows the confirmation dialog of a rendered command
+func (a *AppNew) confirmCommand(action, template string, info *components.TerraformCommandInfo, adjust func(info *components.TerraformCommandInfo)) {
+	if info.Err != nil {
+		a.contentView.DisplayText("Template Error", fmt.Sprintf(
+			"[red]Invalid %s template:[white] %v\n\n[cyan]Template:[white] %s",
+			strings.ToLower(action), info.Err, tview.Escape(template)))
+		a.focusOnTree = true
+		a.tviewApp.SetFocus(a.treeView)
+		return
+	}
+	if adjust != nil {
+		adjust(info)
+	}
 
 	confirmDialog := dialog.NewTerraformConfirmDialog(
-		"terraform destroy",
-		info.WorkDir,
-		info.ConfigFile,
-		info.Content,
+		dialog.TerraformConfirmInfo{
+			Command:     info.Command,
+			Argv:        info.Cmd.Argv,
+			WorkDir:     info.WorkDir,
+			ConfigFile:  info.ConfigFile,
+			FileContent: info.Content,
+			Profile:     info.Env.Profile,
+			EnvLines:    info.Env.Describe(),
+			Warning:     targetWarning(info.Targets),
+		},
 		// Execute: normal execution (terraform will ask for 'yes')
 		func() {
 			a.pages.RemovePage("confirm_tf")
-			a.executeTerraformCommand("Destroy", info.WorkDir, info.Command, info.ConfigFile, info.Content)
+			a.executeTerraformCommand(action, info, false)
 		},
-		// Auto Approve: add -auto-approve flag
+		// Auto Approve: add -auto-approve flag where terraform would prompt
 		func() {
 			a.pages.RemovePage("confirm_tf")
-			cmdWithAutoApprove := info.Command + " -auto-approve"
-			a.executeTerraformCommand("Destroy", info.WorkDir, cmdWithAutoApprove, info.ConfigFile, info.Content)
+			a.executeTerraformCommand(action, info, promptsForApproval(action))
 		},
 		// Cancel
 		func() {
@@ -655,10 +2798,47 @@ func (a *AppNew) showDestroyConfirmationWithFile(path, configFile string) {
 	}
 }
 
+// targetWarning returns the confirm dialog banner for a targeted run
+func targetWarning(targets []string) string {
+	if len(targets) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("TARGETED RUN: only %d address(es) are planned, the rest of the stack is ignored", len(targets))
+}
+
+// promptsForApproval reports whether terraform asks for confirmation for an action
+func promptsForApproval(action string) bool {
+	return action == "Apply" || action == "Destroy" || action == "Refresh"
+}
+
+// recordsHistory reports whether an action changes state and is saved to history
+func recordsHistory(action string) bool {
+	return action == "Apply" || action == "Destroy" || action == "Refresh"
+}
+
 // executeTerraformCommand executes a terraform command with real-time streaming output
-func (a *AppNew) executeTerraformCommand(action, workDir, cmdStr, configFile, configData string) {
-	// Auto-switch focus to content view for Apply/Destroy to prevent accidental input
-	if action == "Apply" || action == "Destroy" {
+func (a *AppNew) executeTerraformCommand(action string, info *components.TerraformCommandInfo, autoApprove bool) {
+	workDir := info.WorkDir
+
+	cmdSpec := info.Cmd.Clone()
+	if autoApprove && !cmdSpec.HasArg("-auto-approve") {
+		cmdSpec.Append("-auto-approve")
+	}
+
+	// With -json, apply events are shown as a live per-resource progress table.
+	// terraform only accepts -json for an apply that needs no interactive approval.
+	if promptsForApproval(action) && a.config.Defaults.JSONProgress && cmdSpec.HasArg("-auto-approve") && !cmdSpec.HasArg("-json") {
+		cmdSpec.Append("-json")
+	}
+	var progress *model.ApplyProgress
+	var progressView *view.ProgressView
+	if promptsForApproval(action) && cmdSpec.HasArg("-json") {
+		progress = model.NewApplyProgress()
+		progressView = view.NewProgressView(action, a.stackName(workDir))
+	}
+
+	// Auto-switch focus to content view for state changing actions to prevent accidental input
+	if promptsForApproval(action) {
 		a.focusOnTree = false
 		a.tviewApp.SetFocus(a.contentView)
 	}
@@ -667,25 +2847,82 @@ func (a *AppNew) executeTerraformCommand(action, workDir, cmdStr, configFile, co
 	a.contentView.SetTitle(fmt.Sprintf(" 🚀 Terraform %s ", action))
 	fmt.Fprintf(a.contentView, "[yellow]Executing Terraform %s[white]\n", action)
 	fmt.Fprintf(a.contentView, "[cyan]Directory:[white] %s\n", workDir)
-	fmt.Fprintf(a.contentView, "[cyan]Command:[white] %s\n", cmdStr)
+	fmt.Fprintf(a.contentView, "[cyan]Command:[white] %s\n", tview.Escape(cmdSpec.String()))
+	if info.Env.Profile != "" {
+		fmt.Fprintf(a.contentView, "[cyan]Profile:[white] %s\n", tview.Escape(info.Env.Profile))
+	}
+	if len(info.Targets) > 0 {
+		fmt.Fprintf(a.contentView, "[red::b]⚠ Targeted run:[-:-:-] %s\n", tview.Escape(strings.Join(info.Targets, ", ")))
+	}
+	if action == "Refresh" {
+		fmt.Fprintf(a.contentView, "[gray]Only changes made outside of Terraform are shown; accepting them updates the state, not the infrastructure.[white]\n")
+	}
+	if action == "Refresh Plan" {
+		fmt.Fprintf(a.contentView, "[gray]Only changes made outside of Terraform are shown; nothing is written to the state.[white]\n")
+	}
 	fmt.Fprintf(a.contentView, "[cyan]%s[white]\n\n", strings.Repeat("─", 60))
 
+	if progressView != nil {
+		a.progressView = progressView
+		a.showProgress()
+	}
+
+	historyDB, release := a.holdHistory()
 	go func() {
-		parts := strings.Fields(cmdStr)
-		if len(parts) == 0 {
+		defer release()
+
+		// Stream a line of output into the content view
+		appendLine := func(line string) {
+			a.tviewApp.QueueUpdateDraw(func() {
+				w := tview.ANSIWriter(a.contentView)
+				w.Write([]byte(line + "\n"))
+				a.contentView.ScrollToEnd()
+			})
+		}
+
+		// Run pre-hooks; a failing pre-hook aborts the run
+		hooks := a.config.Commands.Hooks
+		hookCtx := components.HookContext{
+			Action:   action,
+			Stage:    "pre_" + strings.ToLower(action),
+			WorkDir:  workDir,
+			Vars:     info.Vars,
+			Command:  cmdSpec.String(),
+			ExitCode: -1,
+			BaseEnv:  info.Env.Environ,
+		}
+		if err := components.RunHooks(hooks.Pre(action), hookCtx, appendLine); err != nil {
+			a.runFailureHooks(hookCtx, appendLine)
+			a.tviewApp.QueueUpdateDraw(func() {
+				fmt.Fprintf(a.contentView, "\n[red]Aborted:[white] %s\n", tview.Escape(err.Error()))
+				a.contentView.ScrollToEnd()
+			})
 			return
 		}
 
-		cmd := exec.Command(parts[0], parts[1:]...)
-		cmd.Dir = workDir
+		// An apply with -replace changes state like the state operations, so back it up first
+		if action == "Apply" && len(info.Addresses) > 0 {
+			backupPath, err := components.BackupState(workDir, a.config.BackupDir(), info.Env.Environ)
+			if err != nil {
+				a.runFailureHooks(hookCtx, appendLine)
+				a.tviewApp.QueueUpdateDraw(func() {
+					fmt.Fprintf(a.contentView, "\n[red]Aborted:[white] state backup failed, nothing was changed: %s\n", tview.Escape(err.Error()))
+					a.contentView.ScrollToEnd()
+				})
+				return
+			}
+			appendLine(fmt.Sprintf("State backup written to %s", backupPath))
+		}
+
+		cmd := cmdSpec.ExecEnv(workDir, info.Env.Environ)
 
 		// Check if command has -auto-approve flag
-		hasAutoApprove := strings.Contains(cmdStr, "-auto-approve")
+		hasAutoApprove := cmdSpec.HasArg("-auto-approve")
 
 		// Create stdin pipe for interactive input (only if no auto-approve)
 		var stdinPipe io.WriteCloser
 		var stdinErr error
-		if !hasAutoApprove && (action == "Apply" || action == "Destroy") {
+		if !hasAutoApprove && promptsForApproval(action) {
 			stdinPipe, stdinErr = cmd.StdinPipe()
 			if stdinErr != nil {
 				a.tviewApp.QueueUpdateDraw(func() {
@@ -723,13 +2960,19 @@ func (a *AppNew) executeTerraformCommand(action, workDir, cmdStr, configFile, co
 
 		// Buffer to save full output for history
 		var outputBuf bytes.Buffer
+		// Human-readable messages of the -json events, only written on the UI goroutine
+		var messages strings.Builder
 
-		// Channel to signal when "Enter a value:" is detected
-		confirmChan := make(chan bool)
+		// Channel to receive the answer when "Enter a value:" is detected
 		userResponseChan := make(chan string)
 
+		// Both streams must be fully read before waiting for the command
+		var streams sync.WaitGroup
+		streams.Add(2)
+
 		// Stream stdout in real-time and detect "Enter a value:"
 		go func() {
+			defer streams.Done()
 			scanner := bufio.NewScanner(stdoutPipe)
 			for scanner.Scan() {
 				line := scanner.Text()
@@ -759,11 +3002,20 @@ func (a *AppNew) executeTerraformCommand(action, workDir, cmdStr, configFile, co
 						stdinPipe.Write([]byte(response))
 						stdinPipe.Close()
 					}
-					confirmChan <- true
 				} else {
 					a.tviewApp.QueueUpdateDraw(func() {
+						text := line
+						if progress != nil {
+							// Show the human-readable message of each event
+							if msg, ok := dao.ConsumeApplyEvent(progress, line, time.Now()); ok {
+								text = msg
+							}
+							messages.WriteString(text + "\n")
+							progressView.AppendLog(text)
+							progressView.Update(progress)
+						}
 						w := tview.ANSIWriter(a.contentView)
-						w.Write([]byte(line + "\n"))
+						w.Write([]byte(text + "\n"))
 						a.contentView.ScrollToEnd()
 					})
 				}
@@ -772,11 +3024,15 @@ func (a *AppNew) executeTerraformCommand(action, workDir, cmdStr, configFile, co
 
 		// Stream stderr in real-time
 		go func() {
+			defer streams.Done()
 			scanner := bufio.NewScanner(stderrPipe)
 			for scanner.Scan() {
 				line := scanner.Text()
 				outputBuf.WriteString(line + "\n")
 				a.tviewApp.QueueUpdateDraw(func() {
+					if progressView != nil {
+						progressView.AppendLog(line)
+					}
 					w := tview.ANSIWriter(a.contentView)
 					w.Write([]byte(line + "\n"))
 					a.contentView.ScrollToEnd()
@@ -784,43 +3040,95 @@ func (a *AppNew) executeTerraformCommand(action, workDir, cmdStr, configFile, co
 			}
 		}()
 
+		// Advance the elapsed time of running resources every second
+		streamsDone := make(chan struct{})
+		if progress != nil {
+			go func() {
+				ticker := time.NewTicker(time.Second)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-streamsDone:
+						return
+					case now := <-ticker.C:
+						a.tviewApp.QueueUpdateDraw(func() {
+							progress.Tick(now)
+							progressView.Update(progress)
+						})
+					}
+				}
+			}()
+		}
+
 		// Wait for command to complete
+		streams.Wait()
+		close(streamsDone)
 		cmdErr := cmd.Wait()
 
-		// Save to history if it's apply or destroy
-		if (action == "Apply" || action == "Destroy") && a.historyDB != nil {
-			// Get user and branch info
-			user := os.Getenv("USER")
-			if user == "" {
-				user = "unknown"
+		// Run post-hooks on success, failure hooks otherwise
+		hookCtx.ExitCode = components.ExitCode(cmdErr)
+		if cmdErr == nil {
+			hookCtx.Stage = "post_" + strings.ToLower(action)
+			if err := components.RunHooks(hooks.Post(action), hookCtx, appendLine); err != nil {
+				appendLine(fmt.Sprintf("[red]Hook failed:[white] %s", tview.Escape(err.Error())))
+				a.runFailureHooks(hookCtx, appendLine)
 			}
+		} else {
+			a.runFailureHooks(hookCtx, appendLine)
+		}
 
-			branch := ""
-			if status, gitErr := a.gitManager.GetStatus(workDir); gitErr == nil {
-				branch = status.Branch
-			}
+		// Save to history if the action changes state. A targeted plan is saved as well,
+		// to keep the -target set it ran with.
+		saveToHistory := recordsHistory(action) || len(info.Targets) > 0
+		if saveToHistory {
+			a.saveHistory(historyDB, action, info, startTime, cmdErr)
+		}
 
-			entry := &db.HistoryEntry{
-				Directory:  workDir,
-				Action:     strings.ToLower(action),
-				Timestamp:  startTime,
-				User:       user,
-				Branch:     branch,
-				ConfigFile: configFile,
-				ConfigData: configData,
-				Success:    cmdErr == nil,
-				ErrorMsg:   "",
-			}
-			if cmdErr != nil {
-				entry.ErrorMsg = cmdErr.Error()
+		var changes *components.ChangeSummary
+		output := outputBuf.String()
+		// A refresh-only plan does not compare the configuration, so its
+		// "No changes." does not mean the stack is in sync
+		if action != "Refresh Plan" {
+			for _, line := range strings.Split(output, "\n") {
+				if c := components.ParseChangeSummary(line); c != nil {
+					changes = c
+				}
 			}
-
-			if saveErr := a.historyDB.AddEntry(entry); saveErr != nil {
-				fmt.Fprintf(os.Stderr, "Failed to save history: %v\n", saveErr)
+		}
+		// Answering no to the approval prompt is not a failure of the stack
+		cancelled := strings.Contains(output, "Apply cancelled.") || strings.Contains(output, "Destroy cancelled.")
+
+		// Errors and warnings with their source locations for the diagnostics panel.
+		// validate -json adds configuration errors with exact columns.
+		diags := dao.ParseDiagnostics(output, workDir)
+		if cmdErr != nil && !cancelled && action != "Init" {
+			if validated, err := a.terraformDAO().Validate(workDir); err == nil {
+				diags = dao.MergeDiagnostics(validated, diags)
 			}
 		}
 
 		a.tviewApp.QueueUpdateDraw(func() {
+			if progress != nil {
+				if progress.Finished {
+					changes = &components.ChangeSummary{Add: progress.Added, Change: progress.Changed, Destroy: progress.Destroyed, Import: progress.Imported}
+				}
+				progressView.Finish(progress, cmdErr)
+			}
+			if !cancelled {
+				result := &components.RunResult{
+					Stack:    workDir,
+					Action:   action,
+					Changes:  changes,
+					Err:      cmdErr,
+					Finished: time.Now(),
+					Output:   output,
+				}
+				if progress != nil {
+					result.Output = messages.String()
+				}
+				a.recordResult(result)
+			}
+
 			if cmdErr != nil {
 				fmt.Fprintf(a.contentView, "\n[red]Error:[white] %v\n", cmdErr)
 			}
@@ -828,16 +3136,216 @@ func (a *AppNew) executeTerraformCommand(action, workDir, cmdStr, configFile, co
 			fmt.Fprintf(a.contentView, "\n[green]Done.[white]")
 
 			// Show saved to history message
-			if action == "Apply" || action == "Destroy" {
+			if saveToHistory {
 				fmt.Fprintf(a.contentView, "\n[gray](Saved to history)[white]")
 			}
 
+			a.diagnostics, a.diagnosticsDir = diags, workDir
+			if len(diags) > 0 {
+				fmt.Fprintf(a.contentView, "\n[yellow]%s", diagnosticCounts(diags))
+				if label := a.keymap.Label(config.ActionDiagnostics); label != "" {
+					fmt.Fprintf(a.contentView, ", press %s to open them with their source locations", tview.Escape(label))
+				}
+				fmt.Fprintf(a.contentView, "[white]")
+			}
+
+			// Fold resource blocks, hiding long runs of unchanged attributes
+			if a.contentView.FoldPlan() > 0 {
+				a.showFoldStatus()
+			}
+
 			// Scroll to end
 			a.contentView.ScrollToEnd()
 		})
 	}()
 }
 
+// showProgress opens the live progress of the last apply run with -json
+func (a *AppNew) showProgress() {
+	pv := a.progressView
+	closeView := func() {
+		a.pages.RemovePage("progress")
+		a.focusOnTree = false
+		a.tviewApp.SetFocus(a.contentView)
+	}
+
+	pv.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			closeView()
+			return nil
+		case tcell.KeyTab:
+			pv.ShowTab(1 - pv.Tab())
+			a.tviewApp.SetFocus(pv.Focusable())
+			return nil
+		case tcell.KeyRune:
+			switch event.Rune() {
+			case 'q':
+				closeView()
+				return nil
+			case '1':
+				pv.ShowTab(view.ProgressTab)
+				a.tviewApp.SetFocus(pv.Focusable())
+				return nil
+			case '2':
+				pv.ShowTab(view.LogTab)
+				a.tviewApp.SetFocus(pv.Focusable())
+				return nil
+			}
+		}
+		return event
+	})
+
+	a.pages.AddPage("progress", pv, true, true)
+	a.tviewApp.SetFocus(pv.Focusable())
+}
+
+// diagnosticCounts describes the number of errors and warnings, e.g. "2 errors, 1 warning"
+func diagnosticCounts(diags []*model.Diagnostic) string {
+	errors, warnings := 0, 0
+	for _, d := range diags {
+		if d.IsError() {
+			errors++
+		} else {
+			warnings++
+		}
+	}
+	plural := func(n int, word string) string {
+		if n == 1 {
+			return fmt.Sprintf("1 %s", word)
+		}
+		return fmt.Sprintf("%d %ss", n, word)
+	}
+	return plural(errors, "error") + ", " + plural(warnings, "warning")
+}
+
+// showDiagnostics opens the errors and warnings of the last terraform run
+func (a *AppNew) showDiagnostics() {
+	if len(a.diagnostics) == 0 {
+		a.statusBar.ShowMessage("[green]No errors or warnings from the last terraform run[white]")
+		return
+	}
+
+	diagnosticsView := view.NewDiagnosticsView(a.stackName(a.diagnosticsDir), a.currentDir, a.diagnostics, a.keymap)
+	table := diagnosticsView.GetTable()
+	detail := diagnosticsView.GetDetail()
+
+	closeView := func() {
+		a.pages.RemovePage("diagnostics")
+		a.focusOnTree = true
+		a.tviewApp.SetFocus(a.treeView)
+	}
+
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			closeView()
+			return nil
+		case tcell.KeyTab:
+			a.tviewApp.SetFocus(detail)
+			return nil
+		case tcell.KeyEnter:
+			d := diagnosticsView.Selected()
+			if d == nil || d.File == "" {
+				a.statusBar.ShowMessage("[yellow]This diagnostic has no source location[white]")
+				return nil
+			}
+			a.executor.EditFile(d.File, d.Line)
+			if a.contentView.CurrentFile() == d.File {
+				a.contentView.ReloadFile()
+			}
+			return nil
+		case tcell.KeyRune:
+			if event.Rune() == 'q' {
+				closeView()
+				return nil
+			}
+		}
+
+		switch a.keymap.Lookup(view.KeyName(event), config.ScopeDiagnostics) {
+		case config.ActionDiagnosticsView:
+			d := diagnosticsView.Selected()
+			if d == nil || d.File == "" {
+				return nil
+			}
+			closeView()
+			a.revealPath(d.File)
+			a.currentFile = d.File
+			a.contentView.GoToLine(d.File, d.Line)
+			return nil
+		}
+		return event
+	})
+
+	detail.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyTab, tcell.KeyEscape:
+			a.tviewApp.SetFocus(table)
+			return nil
+		}
+		return event
+	})
+
+	a.pages.AddPage("diagnostics", diagnosticsView, true, true)
+	a.tviewApp.SetFocus(table)
+}
+
+// holdHistory returns the history DB for a background job. The DB stays open until
+// release is called, even if the context is switched while the job runs.
+func (a *AppNew) holdHistory() (historyDB *db.HistoryDB, release func()) {
+	jobs := a.historyJobs
+	jobs.Add(1)
+	return a.historyDB, jobs.Done
+}
+
+// saveHistory records a finished terraform run in historyDB, the DB of the context it was started in
+func (a *AppNew) saveHistory(historyDB *db.HistoryDB, action string, info *components.TerraformCommandInfo, startTime time.Time, cmdErr error) {
+	if historyDB == nil {
+		return
+	}
+
+	// Get user and branch info
+	user := os.Getenv("USER")
+	if user == "" {
+		user = "unknown"
+	}
+
+	branch := ""
+	if status, gitErr := a.gitManager.GetStatus(info.WorkDir); gitErr == nil {
+		branch = status.Branch
+	}
+
+	entry := &db.HistoryEntry{
+		Directory:  info.WorkDir,
+		Action:     strings.ToLower(action),
+		Timestamp:  startTime,
+		User:       user,
+		Branch:     branch,
+		Workspace:  info.Vars["workspace"],
+		Addresses:  strings.Join(info.Addresses, "\n"),
+		Targets:    strings.Join(info.Targets, "\n"),
+		ConfigFile: info.ConfigFile,
+		ConfigData: info.Content,
+		Success:    cmdErr == nil,
+		ErrorMsg:   "",
+	}
+	if cmdErr != nil {
+		entry.ErrorMsg = cmdErr.Error()
+	}
+
+	if saveErr := historyDB.AddEntry(entry); saveErr != nil {
+		fmt.Fprintf(os.Stderr, "Failed to save history: %v\n", saveErr)
+	}
+}
+
+// runFailureHooks runs the on_failure hooks, reporting but not propagating their errors
+func (a *AppNew) runFailureHooks(hookCtx components.HookContext, out func(line string)) {
+	hookCtx.Stage = "on_failure"
+	if err := components.RunHooks(a.config.Commands.Hooks.OnFailure, hookCtx, out); err != nil {
+		out(fmt.Sprintf("[red]Hook failed:[white] %s", tview.Escape(err.Error())))
+	}
+}
+
 // showApplyConfirmDialog shows a Yes/No dialog for terraform confirmation
 func (a *AppNew) showApplyConfirmDialog(onYes, onNo func()) {
 	confirmDialog := tview.NewModal().
@@ -866,7 +3374,7 @@ func (a *AppNew) showApplyConfirmDialog(onYes, onNo func()) {
 // showHelp displays the help screen
 func (a *AppNew) showHelp() {
 	if a.helpView == nil {
-		a.helpView = view.NewHelpView()
+		a.helpView = view.NewHelpView(a.keymap)
 	}
 
 	// Create modal with help view
@@ -875,14 +3383,14 @@ func (a *AppNew) showHelp() {
 		AddItem(tview.NewFlex().
 			SetDirection(tview.FlexRow).
 			AddItem(nil, 0, 1, false).
-			AddItem(a.helpView, 30, 0, true).
+			AddItem(a.helpView, a.helpView.Height(), 0, true).
 			AddItem(nil, 0, 1, false), 100, 0, true).
 		AddItem(nil, 0, 1, false)
 	modal.SetBackgroundColor(tcell.ColorBlack)
 
 	// Setup input capture for help view
 	modal.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		if event.Key() == tcell.KeyEscape || event.Rune() == 'q' || event.Rune() == '?' || event.Rune() == 'H' {
+		if key := view.KeyName(event); key == "Esc" || key == "q" || a.keymap.Lookup(key, config.ScopeTree) == config.ActionHelp {
 			a.pages.RemovePage("help")
 			a.tviewApp.SetFocus(a.treeView)
 			return nil
@@ -912,7 +3420,7 @@ func (a *AppNew) showHistory(path string) {
 	}
 
 	// Create history view
-	a.historyView = view.NewHistoryView(path, entries)
+	a.historyView = view.NewHistoryView(path, entries, a.keymap)
 
 	// Set up key handler for history view
 	a.historyView.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
@@ -921,21 +3429,6 @@ func (a *AppNew) showHistory(path string) {
 			a.pages.RemovePage("history")
 			a.tviewApp.SetFocus(a.treeView)
 			return nil
-		case tcell.KeyRune:
-			switch event.Rune() {
-			case 'M':
-				// Shift+M: Toggle details
-				a.historyView.ToggleDetails()
-				return nil
-			case 'd', 'D':
-				// d: Load more
-				a.historyView.LoadMore()
-				return nil
-			case 'u', 'U':
-				// u: Load less (go back)
-				a.historyView.LoadLess()
-				return nil
-			}
 		case tcell.KeyDown:
 			if event.Modifiers()&tcell.ModShift != 0 {
 				// Shift+Down: Load more
@@ -949,6 +3442,19 @@ func (a *AppNew) showHistory(path string) {
 				return nil
 			}
 		}
+
+		switch a.keymap.Lookup(view.KeyName(event), config.ScopeHistory) {
+		case config.ActionHistoryDetails:
+			a.historyView.ToggleDetails()
+			return nil
+		case config.ActionHistoryMore:
+			a.historyView.LoadMore()
+			return nil
+		case config.ActionHistoryLess:
+			// Load less (go back)
+			a.historyView.LoadLess()
+			return nil
+		}
 		return event
 	})
 
@@ -997,13 +3503,18 @@ func (a *AppNew) showCommandInput() {
 	a.tviewApp.SetFocus(a.commandView.GetInput())
 }
 
-// executeCommand executes a command in the current directory
+// executeCommand executes a command in the current directory. A terraform action runs
+// like one started from the tree, with its hooks, environment profile and history.
 func (a *AppNew) executeCommand(cmd string) {
 	if cmd == "" {
 		return
 	}
 
 	workDir := a.commandView.GetCurrentDir()
+	if action := terraformAction(cmd); action != "" {
+		a.executeTypedTerraform(action, workDir, cmd)
+		return
+	}
 
 	a.contentView.Clear()
 	a.contentView.SetTitle(" 🚀 Command Execution ")
@@ -1033,6 +3544,63 @@ func (a *AppNew) executeCommand(cmd string) {
 	}()
 }
 
+// executeTypedTerraform runs a terraform action typed in command mode
+func (a *AppNew) executeTypedTerraform(action, workDir, cmd string) {
+	// Preparing looks up the workspace and git branch, so it runs off the UI goroutine
+	root := a.currentDir
+	go func() {
+		info := components.TypedCommandInfo(workDir, cmd, a.config)
+		a.tviewApp.QueueUpdateDraw(func() {
+			if a.currentDir != root {
+				return
+			}
+			if info.Err != nil {
+				a.contentView.DisplayText("Command Error", fmt.Sprintf(
+					"[red]Invalid command:[white] %v\n\n[cyan]Command:[white] %s", info.Err, tview.Escape(cmd)))
+				return
+			}
+			a.executeTerraformCommand(action, info, false)
+		})
+	}()
+}
+
+// terraformAction returns the action a typed command starts, e.g. "Plan" for "terraform plan",
+// or "" if it is not a terraform action with hooks
+func terraformAction(cmd string) string {
+	fields := strings.Fields(cmd)
+	if len(fields) == 0 || filepath.Base(fields[0]) != "terraform" {
+		return ""
+	}
+	// The subcommand follows global options such as -chdir=...
+	for i, field := range fields[1:] {
+		if strings.HasPrefix(field, "-") {
+			continue
+		}
+		switch field {
+		case "init":
+			return "Init"
+		case "plan":
+			for _, arg := range fields[i+2:] {
+				if arg == "-refresh-only" {
+					return "Refresh Plan"
+				}
+			}
+			return "Plan"
+		case "apply":
+			for _, arg := range fields[i+2:] {
+				if arg == "-refresh-only" {
+					return "Refresh"
+				}
+			}
+			return "Apply"
+		case "destroy":
+			return "Destroy"
+		}
+		return ""
+	}
+	return ""
+}
+
 // showBranchSwitch shows branch selection dialog
 func (a *AppNew) showBranchSwitch(path string) {
 	// Get directory path
@@ -1221,5 +3789,30 @@ func (a *AppNew) performBranchSwitch(workDir, targetBranch, mode string) {
 
 // Run starts the application
 func (a *AppNew) Run() error {
+	defer a.stopWatcher()
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go a.tickResultAge(stop)
+
 	return a.tviewApp.Run()
 }
+
+// tickResultAge redraws the last result of the selected stack every ageInterval, so that
+// its age stays current, until stop is closed
+func (a *AppNew) tickResultAge(stop <-chan struct{}) {
+	ticker := time.NewTicker(ageInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			a.tviewApp.QueueUpdateDraw(func() {
+				if path := a.treeView.GetCurrentPath(); path != "" && a.results[stackDir(path)] != nil {
+					a.showLastResult(path)
+				}
+			})
+		}
+	}
+}
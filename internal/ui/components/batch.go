@@ -0,0 +1,261 @@
+package components
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/idongju/t9s/internal/config"
+	"github.com/rivo/tview"
+)
+
+// BatchStep is one stack of a batch run and, once run, its outcome
+type BatchStep struct {
+	Stack     string
+	Info      *TerraformCommandInfo
+	StartTime time.Time
+	Duration  time.Duration
+	Err       error
+	Ran       bool
+	Running   bool
+	Skipped   bool     // not runnable, Err holds the reason
+	Output    []string // captured output lines, only set by RunParallel
+	Changes   *ChangeSummary
+}
+
+// ChangeSummary holds the change counts reported by terraform
+type ChangeSummary struct {
+	Add     int
+	Change  int
+	Destroy int
+	Import  int
+}
+
+// String formats the counts like terraform does
+func (c *ChangeSummary) String() string {
+	if c.Empty() {
+		return "no changes"
+	}
+	s := fmt.Sprintf("+%d ~%d -%d", c.Add, c.Change, c.Destroy)
+	if c.Import > 0 {
+		s += fmt.Sprintf(" ⇣%d", c.Import)
+	}
+	return s
+}
+
+var (
+	planSummaryPattern = regexp.MustCompile(`Plan: (?:(\d+) to import, )?(\d+) to add, (\d+) to change, (\d+) to destroy`)
+	ansiPattern        = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+)
+
+// Empty reports whether terraform reported no changes
+func (c *ChangeSummary) Empty() bool {
+	return c.Add+c.Change+c.Destroy+c.Import == 0
+}
+
+// ParseChangeSummary extracts the change counts from plan or apply output, nil if there are none
+func ParseChangeSummary(line string) *ChangeSummary {
+	line = ansiPattern.ReplaceAllString(line, "")
+	if strings.Contains(line, "No changes.") {
+		return &ChangeSummary{}
+	}
+	m := planSummaryPattern.FindStringSubmatch(line)
+	if m == nil {
+		return nil
+	}
+	atoi := func(s string) int {
+		n, _ := strconv.Atoi(s)
+		return n
+	}
+	return &ChangeSummary{Import: atoi(m[1]), Add: atoi(m[2]), Change: atoi(m[3]), Destroy: atoi(m[4])}
+}
+
+// PrepareBatch renders the command of an action for each stack, keeping the given order.
+// configFile is looked up in every stack like the single-stack commands do; stacks without
+// it are marked Skipped instead of running without the file.
+func PrepareBatch(action, configFile string, stacks []string, cfg *config.Config) ([]*BatchStep, error) {
+	template := cfg.Commands.Template(action)
+	if template == "" {
+		return nil, fmt.Errorf("no template for %s", action)
+	}
+
+	var steps []*BatchStep
+	for _, stack := range stacks {
+		info := GetTerraformCommandInfo(stack, template, configFile, cfg)
+		if info.Err != nil {
+			return nil, fmt.Errorf("%s: %w", stack, info.Err)
+		}
+		step := &BatchStep{Stack: stack, Info: info}
+		if configFile != "" && info.ConfigFile == "" {
+			step.Skipped = true
+			step.Err = fmt.Errorf("%s not found", configFile)
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// SkippedSteps returns the steps that cannot run
+func SkippedSteps(steps []*BatchStep) []*BatchStep {
+	var skipped []*BatchStep
+	for _, step := range steps {
+		if step.Skipped {
+			skipped = append(skipped, step)
+		}
+	}
+	return skipped
+}
+
+// RunBatch runs the steps one after the other and stops at the first failure. Nothing runs
+// if a step is skipped. Terraform never prompts: -input=false is added, and -auto-approve
+// when autoApprove is set. done is called after every step that ran.
+func RunBatch(action string, steps []*BatchStep, cfg *config.Config, autoApprove bool, out func(line string), done func(step *BatchStep)) error {
+	if skipped := SkippedSteps(steps); len(skipped) > 0 {
+		return fmt.Errorf("%s: %w", skipped[0].Stack, skipped[0].Err)
+	}
+
+	hooks := cfg.Commands.Hooks
+	for i, step := range steps {
+		info := step.Info
+		cmdSpec := info.Cmd.Clone()
+		if !cmdSpec.HasArg("-input=false") {
+			cmdSpec.Append("-input=false")
+		}
+		if autoApprove && !cmdSpec.HasArg("-auto-approve") {
+			cmdSpec.Append("-auto-approve")
+		}
+
+		out(fmt.Sprintf("[yellow::b]━━ (%d/%d) %s[-:-:-]", i+1, len(steps), tview.Escape(step.Stack)))
+		out("» " + cmdSpec.String())
+
+		hookCtx := HookContext{
+			Action:   action,
+			Stage:    "pre_" + strings.ToLower(action),
+			WorkDir:  info.WorkDir,
+			Vars:     info.Vars,
+			Command:  cmdSpec.String(),
+			ExitCode: -1,
+			BaseEnv:  info.Env.Environ,
+		}
+
+		stepOut := func(line string) {
+			if c := ParseChangeSummary(line); c != nil {
+				step.Changes = c
+			}
+			out(line)
+		}
+
+		step.StartTime = time.Now()
+		step.Ran = true
+		step.Err = RunHooks(hooks.Pre(action), hookCtx, out)
+		if step.Err == nil {
+			step.Err = runStreaming(cmdSpec.ExecEnv(info.WorkDir, info.Env.Environ), stepOut)
+			hookCtx.ExitCode = ExitCode(step.Err)
+			if step.Err == nil {
+				hookCtx.Stage = "post_" + strings.ToLower(action)
+				step.Err = RunHooks(hooks.Post(action), hookCtx, out)
+			}
+		}
+		step.Duration = time.Since(step.StartTime)
+
+		if step.Err != nil {
+			hookCtx.Stage = "on_failure"
+			if err := RunHooks(hooks.OnFailure, hookCtx, out); err != nil {
+				out(fmt.Sprintf("[red]Hook failed:[white] %s", tview.Escape(err.Error())))
+			}
+		}
+		if done != nil {
+			done(step)
+		}
+		if step.Err != nil {
+			return fmt.Errorf("%s failed in %s: %w", strings.ToLower(action), step.Stack, step.Err)
+		}
+	}
+	return nil
+}
+
+// RunParallel runs the steps independently, at most parallelism at once, capturing each
+// step's output. Failures do not stop the other steps and skipped steps do not run. update
+// receives a copy of the step at index i whenever it starts or finishes; it runs on the
+// worker goroutine.
+func RunParallel(action string, steps []*BatchStep, cfg *config.Config, parallelism int, update func(i int, step BatchStep)) {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	hooks := cfg.Commands.Hooks
+	sem := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+
+	for i, step := range steps {
+		if step.Skipped {
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, step *BatchStep) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			info := step.Info
+			cmdSpec := info.Cmd.Clone()
+			if !cmdSpec.HasArg("-input=false") {
+				cmdSpec.Append("-input=false")
+			}
+
+			var output []string
+			var changes *ChangeSummary
+			out := func(line string) {
+				output = append(output, line)
+				if c := ParseChangeSummary(line); c != nil {
+					changes = c
+				}
+			}
+			out("» " + cmdSpec.String())
+
+			hookCtx := HookContext{
+				Action:   action,
+				Stage:    "pre_" + strings.ToLower(action),
+				WorkDir:  info.WorkDir,
+				Vars:     info.Vars,
+				Command:  cmdSpec.String(),
+				ExitCode: -1,
+				BaseEnv:  info.Env.Environ,
+			}
+
+			step.StartTime = time.Now()
+			step.Running = true
+			update(i, *step)
+
+			err := RunHooks(hooks.Pre(action), hookCtx, out)
+			if err == nil {
+				err = runStreaming(cmdSpec.ExecEnv(info.WorkDir, info.Env.Environ), out)
+				hookCtx.ExitCode = ExitCode(err)
+				if err == nil {
+					hookCtx.Stage = "post_" + strings.ToLower(action)
+					err = RunHooks(hooks.Post(action), hookCtx, out)
+				}
+			}
+			if err != nil {
+				out(fmt.Sprintf("[red]Error:[white] %s", tview.Escape(err.Error())))
+				hookCtx.Stage = "on_failure"
+				if hookErr := RunHooks(hooks.OnFailure, hookCtx, out); hookErr != nil {
+					out(fmt.Sprintf("[red]Hook failed:[white] %s", tview.Escape(hookErr.Error())))
+				}
+			}
+
+			step.Output = output
+			step.Changes = changes
+			step.Duration = time.Since(step.StartTime)
+			step.Err = err
+			step.Running = false
+			step.Ran = true
+			update(i, *step)
+		}(i, step)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,29 @@
+package components
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+)
+
+// CopyToClipboard copies text to the system clipboard with an OSC52 escape sequence.
+// The terminal does the copying, so it also works over SSH and inside tmux.
+func CopyToClipboard(text string) error {
+	seq := fmt.Sprintf("\x1b]52;c;%s\a", base64.StdEncoding.EncodeToString([]byte(text)))
+	if os.Getenv("TMUX") != "" {
+		// tmux needs the sequence wrapped in a passthrough with doubled escapes
+		seq = "\x1bPtmux;\x1b" + seq + "\x1b\\"
+	}
+
+	var out io.Writer = os.Stdout
+	if tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0); err == nil {
+		defer tty.Close()
+		out = tty
+	}
+
+	if _, err := io.WriteString(out, seq); err != nil {
+		return fmt.Errorf("failed to write to terminal: %w", err)
+	}
+	return nil
+}
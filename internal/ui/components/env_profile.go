@@ -0,0 +1,206 @@
+package components
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/idongju/t9s/internal/config"
+)
+
+// EnvVar is an environment variable set for a run
+type EnvVar struct {
+	Name   string
+	Value  string
+	Source string // "profile" or the .env file it was loaded from
+}
+
+// RunEnv is the effective environment of a terraform run
+type RunEnv struct {
+	Profile string   // name of the matched profile, empty if none matched
+	Set     []EnvVar // variables set by the profile, in name order
+	Unset   []string // variables (or NAME_* prefixes) removed from the environment
+	Environ []string // full environment for the process
+}
+
+// secretPattern matches variable names whose values must not be displayed
+var secretPattern = regexp.MustCompile(`(?i)(SECRET|TOKEN|PASSWORD|PASSWD|CREDENTIAL|PRIVATE|SESSION|ACCESS_KEY|API_KEY|_KEY$)`)
+
+// ResolveEnv finds the profile matching a run and builds its environment.
+// Without a matching profile the shell environment is inherited unchanged.
+func ResolveEnv(cfg *config.Config, workDir, configFile, envName string) (*RunEnv, error) {
+	runEnv := &RunEnv{}
+	profile := matchProfile(cfg.EnvProfiles, cfg.TerraformRoot, workDir, envName)
+	if profile == nil {
+		runEnv.Environ = os.Environ()
+		return runEnv, nil
+	}
+	runEnv.Profile = profile.Name
+	runEnv.Unset = profile.Unset
+
+	set := map[string]EnvVar{}
+	if profile.DotEnv && configFile != "" {
+		dotEnv, err := findDotEnv(configFile, envName)
+		if err != nil {
+			return nil, err
+		}
+		if dotEnv != "" {
+			values, err := loadDotEnv(dotEnv)
+			if err != nil {
+				return nil, err
+			}
+			for name, value := range values {
+				set[name] = EnvVar{Name: name, Value: value, Source: dotEnv}
+			}
+		}
+	}
+	// Values from the profile take precedence over the .env file
+	for name, value := range profile.Set {
+		set[name] = EnvVar{Name: name, Value: value, Source: "profile"}
+	}
+
+	for _, v := range set {
+		runEnv.Set = append(runEnv.Set, v)
+	}
+	sort.Slice(runEnv.Set, func(i, j int) bool { return runEnv.Set[i].Name < runEnv.Set[j].Name })
+
+	// Drop unset and overridden variables from the inherited environment
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		if _, ok := set[name]; ok || isUnset(name, profile.Unset) {
+			continue
+		}
+		runEnv.Environ = append(runEnv.Environ, kv)
+	}
+	for _, v := range runEnv.Set {
+		runEnv.Environ = append(runEnv.Environ, v.Name+"="+v.Value)
+	}
+
+	return runEnv, nil
+}
+
+// Describe returns display lines for the confirm dialog, with secrets masked
+func (re *RunEnv) Describe() []string {
+	var lines []string
+	for _, name := range re.Unset {
+		lines = append(lines, fmt.Sprintf("unset %s", name))
+	}
+	for _, v := range re.Set {
+		source := ""
+		if v.Source != "profile" {
+			source = fmt.Sprintf("  (%s)", filepath.Base(v.Source))
+		}
+		lines = append(lines, fmt.Sprintf("%s=%s%s", v.Name, MaskValue(v.Name, v.Value), source))
+	}
+	return lines
+}
+
+// MaskValue hides the value of variables that look like secrets
+func MaskValue(name, value string) string {
+	if !secretPattern.MatchString(name) || value == "" {
+		return value
+	}
+	return "********"
+}
+
+// matchProfile returns the first profile matching the tfvars name or directory
+func matchProfile(profiles []config.EnvProfile, root, workDir, envName string) *config.EnvProfile {
+	stack := workDir
+	if rel, err := filepath.Rel(root, workDir); err == nil && !strings.HasPrefix(rel, "..") {
+		stack = filepath.ToSlash(rel)
+	}
+
+	for i := range profiles {
+		for _, pattern := range profiles[i].Match {
+			if envName != "" && globMatch(pattern, envName) {
+				return &profiles[i]
+			}
+			if globMatch(pattern, stack) || globMatch(pattern, workDir) {
+				return &profiles[i]
+			}
+		}
+	}
+	return nil
+}
+
+// globMatch matches a glob where "*" may also span path separators
+func globMatch(pattern, name string) bool {
+	if ok, _ := filepath.Match(pattern, name); ok {
+		return true
+	}
+	if !strings.Contains(pattern, "*") {
+		return false
+	}
+	expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$"
+	ok, _ := regexp.MatchString(expr, name)
+	return ok
+}
+
+// isUnset reports whether a variable is removed by the unset list; NAME_* removes a prefix
+func isUnset(name string, unset []string) bool {
+	for _, u := range unset {
+		if u == name {
+			return true
+		}
+		if strings.HasSuffix(u, "*") && strings.HasPrefix(name, strings.TrimSuffix(u, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+// findDotEnv looks for <env>.env and then .env next to the tfvars file
+func findDotEnv(configFile, envName string) (string, error) {
+	dir := filepath.Dir(configFile)
+	var candidates []string
+	if envName != "" {
+		candidates = append(candidates, filepath.Join(dir, envName+".env"))
+	}
+	candidates = append(candidates, filepath.Join(dir, ".env"))
+
+	for _, candidate := range candidates {
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		} else if !os.IsNotExist(err) {
+			return "", fmt.Errorf("failed to read %s: %w", candidate, err)
+		}
+	}
+	return "", nil
+}
+
+// loadDotEnv parses KEY=value lines, ignoring comments and an "export " prefix
+func loadDotEnv(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	values := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: expected KEY=value", path, lineNo)
+		}
+		name = strings.TrimSpace(name)
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		values[name] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return values, nil
+}
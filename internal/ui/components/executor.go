@@ -31,14 +31,9 @@ func NewCommandExecutor(app *tview.Application, contentView *view.ContentView, c
 	}
 }
 
-// ExecutePlan executes terraform plan
-func (ce *CommandExecutor) ExecutePlan(path string) {
-	ce.runTerraformCommand("Plan", path, ce.config.Commands.PlanTemplate)
-}
-
-// ExecuteApply executes terraform apply
-func (ce *CommandExecutor) ExecuteApply(path string) {
-	ce.runTerraformCommand("Apply", path, ce.config.Commands.ApplyTemplate)
+// SetHistoryDB replaces the history DB, e.g. after a context switch
+func (ce *CommandExecutor) SetHistoryDB(historyDB *db.HistoryDB) {
+	ce.historyDB = historyDB
 }
 
 // ShowHistory shows terraform history
@@ -161,8 +156,8 @@ func (ce *CommandExecutor) ShowHelm() {
 	ce.contentView.AppendText(string(output))
 }
 
-// EditFile opens a file in the default editor
-func (ce *CommandExecutor) EditFile(filePath string) {
+// EditFile opens a file in the default editor, at line unless it is 0
+func (ce *CommandExecutor) EditFile(filePath string, line int) {
 	if filePath == "" {
 		ce.contentView.DisplayText("No File", "[yellow]No file selected[white]\n\nPlease select a file from the tree first.")
 		return
@@ -174,7 +169,7 @@ func (ce *CommandExecutor) EditFile(filePath string) {
 	}
 
 	ce.app.Suspend(func() {
-		cmd := exec.Command(editor, filePath)
+		cmd := editorCommand(editor, filePath, line)
 		cmd.Stdin = os.Stdin
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
@@ -186,63 +181,37 @@ func (ce *CommandExecutor) EditFile(filePath string) {
 	})
 }
 
-// runTerraformCommand executes a terraform command
-func (ce *CommandExecutor) runTerraformCommand(action string, path string, template string) {
-	var workDir string
-	var varFile string
-
-	info, err := os.Stat(path)
-	if err == nil && !info.IsDir() {
-		workDir = filepath.Dir(path)
-		if strings.HasSuffix(path, ".tfvars") {
-			varFile = path
-		}
-	} else {
-		workDir = path
-		defaultVar := filepath.Join(workDir, ce.config.Commands.TfvarsFile)
-		if _, err := os.Stat(defaultVar); err == nil {
-			varFile = defaultVar
-		}
-	}
-
-	ce.contentView.Clear()
-	ce.contentView.SetTitle(fmt.Sprintf(" 🚀 Terraform %s ", action))
-	fmt.Fprintf(ce.contentView, "[yellow]Executing Terraform %s[white]\n", action)
-	fmt.Fprintf(ce.contentView, "[cyan]Directory:[white] %s\n", workDir)
-	if varFile != "" {
-		fmt.Fprintf(ce.contentView, "[cyan]Var File:[white] %s\n", varFile)
-	} else {
-		fmt.Fprintf(ce.contentView, "[yellow]Warning:[white] No .tfvars file found or selected. Running without -var-file.\n")
+// editorCommand builds the command opening filePath in editor at line. $EDITOR may
+// include arguments, e.g. "code -w". Editors without a known line syntax open the file only.
+func editorCommand(editor, filePath string, line int) *exec.Cmd {
+	fields := strings.Fields(editor)
+	if len(fields) == 0 {
+		fields = []string{"vim"}
 	}
-	fmt.Fprintf(ce.contentView, "[cyan]%s[white]\n\n", strings.Repeat("─", 60))
-
-	cmdStr := template
-	if varFile != "" {
-		cmdStr = strings.ReplaceAll(cmdStr, "{varfile}", varFile)
-	} else {
-		cmdStr = strings.ReplaceAll(cmdStr, "-var-file={varfile}", "")
+	args := fields[1:]
+	if line <= 0 {
+		return exec.Command(fields[0], append(args, filePath)...)
 	}
 
-	fmt.Fprintf(ce.contentView, "[gray]Command: %s[white]\n\n", cmdStr)
-
-	go func() {
-		parts := strings.Fields(cmdStr)
-		if len(parts) == 0 {
-			return
-		}
-		
-		cmd := exec.Command(parts[0], parts[1:]...)
-		cmd.Dir = workDir
-		
-		output, err := cmd.CombinedOutput()
-		
-		ce.app.QueueUpdateDraw(func() {
-			if err != nil {
-				fmt.Fprintf(ce.contentView, "[red]Error executing command:[white] %v\n", err)
+	switch filepath.Base(fields[0]) {
+	case "vim", "vi", "nvim", "gvim", "nano", "emacs", "emacsclient", "micro", "kak":
+		args = append(args, fmt.Sprintf("+%d", line), filePath)
+	case "code", "code-insiders", "codium", "cursor":
+		// VS Code returns at once unless it waits for the file to be closed
+		hasWait := false
+		for _, arg := range args {
+			if arg == "-w" || arg == "--wait" {
+				hasWait = true
 			}
-			ce.contentView.AppendText(string(output))
-			ce.contentView.AppendText("\n\n[green]Done.[white]")
-		})
-	}()
+		}
+		if !hasWait {
+			args = append(args, "--wait")
+		}
+		args = append(args, "--goto", fmt.Sprintf("%s:%d", filePath, line))
+	case "subl", "hx", "helix":
+		args = append(args, fmt.Sprintf("%s:%d", filePath, line))
+	default:
+		args = append(args, filePath)
+	}
+	return exec.Command(fields[0], args...)
 }
-
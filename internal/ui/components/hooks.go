@@ -0,0 +1,108 @@
+package components
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/idongju/t9s/internal/command"
+)
+
+// HookContext describes the terraform run a hook belongs to
+type HookContext struct {
+	Action   string // "init", "plan", "apply", "destroy"
+	Stage    string // "pre_plan", "post_apply", "on_failure", ...
+	WorkDir  string
+	Vars     command.Vars
+	Command  string   // rendered terraform command
+	ExitCode int      // exit code of the terraform command, -1 if it did not run
+	BaseEnv  []string // environment of the terraform run, nil for the shell environment
+}
+
+// Env returns the environment variables passed to hooks
+func (hc HookContext) Env() []string {
+	user := os.Getenv("USER")
+	if user == "" {
+		user = "unknown"
+	}
+	return []string{
+		"T9S_ACTION=" + strings.ToLower(hc.Action),
+		"T9S_HOOK=" + hc.Stage,
+		"T9S_DIR=" + hc.WorkDir,
+		"T9S_STACK=" + hc.Vars["stack"],
+		"T9S_ENV=" + hc.Vars["env"],
+		"T9S_VARFILE=" + hc.Vars["varfile"],
+		"T9S_INITCONF=" + hc.Vars["initconf"],
+		"T9S_WORKSPACE=" + hc.Vars["workspace"],
+		"T9S_BRANCH=" + hc.Vars["branch"],
+		"T9S_USER=" + user,
+		"T9S_COMMAND=" + hc.Command,
+		"T9S_EXIT_CODE=" + strconv.Itoa(hc.ExitCode),
+	}
+}
+
+// RunHooks runs hook templates in order, streaming their combined output line by line.
+// It stops at the first hook that fails and returns its error.
+func RunHooks(templates []string, hc HookContext, out func(line string)) error {
+	for _, template := range templates {
+		if strings.TrimSpace(template) == "" {
+			continue
+		}
+
+		cmdSpec, err := command.Render(template, hc.Vars)
+		if err != nil {
+			return fmt.Errorf("%s hook %q: %w", hc.Stage, template, err)
+		}
+		out(fmt.Sprintf("» %s: %s", hc.Stage, cmdSpec.String()))
+
+		base := hc.BaseEnv
+		if base == nil {
+			base = os.Environ()
+		}
+		cmd := cmdSpec.ExecEnv(hc.WorkDir, append(append([]string(nil), base...), hc.Env()...))
+		if err := runStreaming(cmd, out); err != nil {
+			return fmt.Errorf("%s hook %q failed: %w", hc.Stage, cmdSpec.String(), err)
+		}
+	}
+	return nil
+}
+
+// ExitCode extracts the process exit code from a command error
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+// runStreaming runs a command and passes each line of stdout and stderr to out
+func runStreaming(cmd *exec.Cmd, out func(line string)) error {
+	pr, pw := io.Pipe()
+	cmd.Stdout = pw
+	cmd.Stderr = pw
+
+	done := make(chan struct{})
+	go func() {
+		scanner := bufio.NewScanner(pr)
+		for scanner.Scan() {
+			out(scanner.Text())
+		}
+		// Drain anything left after a scan error so the writer never blocks
+		io.Copy(io.Discard, pr)
+		close(done)
+	}()
+
+	err := cmd.Run()
+	pw.Close()
+	<-done
+	return err
+}
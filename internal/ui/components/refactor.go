@@ -0,0 +1,41 @@
+package components
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/idongju/t9s/internal/command"
+	"github.com/idongju/t9s/internal/config"
+	"github.com/idongju/t9s/internal/dao"
+	"github.com/idongju/t9s/internal/model"
+)
+
+// PlanChanges runs the plan template into a temporary plan file and returns its resource changes
+func PlanChanges(workDir, varFile string, cfg *config.Config, out func(line string)) (*model.Plan, error) {
+	info := GetTerraformCommandInfo(workDir, cfg.Commands.PlanTemplate, varFile, cfg)
+	if info.Err != nil {
+		return nil, info.Err
+	}
+
+	tmp, err := os.MkdirTemp("", "t9s-plan-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+	planFile := filepath.Join(tmp, "tfplan")
+
+	plan := info.Cmd.Clone()
+	plan.Append("-input=false", "-out="+planFile)
+	out("» " + plan.String())
+	if err := runStreaming(plan.ExecEnv(workDir, info.Env.Environ), out); err != nil {
+		return nil, fmt.Errorf("terraform plan failed: %w", err)
+	}
+
+	show := &command.Command{Argv: []string{"terraform", "show", "-json", planFile}}
+	output, err := show.ExecEnv(workDir, info.Env.Environ).Output()
+	if err != nil {
+		return nil, fmt.Errorf("terraform show failed: %w", err)
+	}
+	return dao.ParsePlan(output)
+}
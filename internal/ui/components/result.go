@@ -0,0 +1,68 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RunResult is the outcome of a finished terraform run of a stack
+type RunResult struct {
+	Stack    string
+	Action   string // "Plan", "Apply", ...
+	Changes  *ChangeSummary
+	Err      error
+	Finished time.Time
+	Output   string // raw output with ANSI colors, empty if it was not captured
+}
+
+// Summary describes the outcome like terraform does, e.g.
+// "Plan: 3 to add, 1 to change, 0 to destroy" or "Apply failed: exit status 1"
+func (r *RunResult) Summary() string {
+	switch {
+	case r.Err != nil:
+		return fmt.Sprintf("%s failed: %s", r.Action, r.Err)
+	case r.Changes == nil:
+		return fmt.Sprintf("%s: succeeded", r.Action)
+	case r.Action == "Plan" && r.Changes.Empty():
+		return "Plan: no changes"
+	case r.Action == "Plan":
+		s := fmt.Sprintf("Plan: %d to add, %d to change, %d to destroy", r.Changes.Add, r.Changes.Change, r.Changes.Destroy)
+		if r.Changes.Import > 0 {
+			s = fmt.Sprintf("Plan: %d to import, %d to add, %d to change, %d to destroy", r.Changes.Import, r.Changes.Add, r.Changes.Change, r.Changes.Destroy)
+		}
+		return s
+	default:
+		return fmt.Sprintf("%s: %d added, %d changed, %d destroyed", r.Action, r.Changes.Add, r.Changes.Change, r.Changes.Destroy)
+	}
+}
+
+// Short describes the outcome in a few words, e.g. "plan +3 ~1 -0" or "apply failed"
+func (r *RunResult) Short() string {
+	action := strings.ToLower(r.Action)
+	switch {
+	case r.Err != nil:
+		return action + " failed"
+	case r.Changes == nil:
+		return action + " ok"
+	case r.Action == "Plan" && r.Changes.Empty():
+		return "plan no changes"
+	default:
+		return fmt.Sprintf("%s +%d ~%d -%d", action, r.Changes.Add, r.Changes.Change, r.Changes.Destroy)
+	}
+}
+
+// Color returns the color of the outcome by severity: red for failures and destroys,
+// yellow for other changes, green otherwise
+func (r *RunResult) Color() string {
+	switch {
+	case r.Err != nil:
+		return "red"
+	case r.Changes != nil && r.Changes.Destroy > 0:
+		return "red"
+	case r.Changes != nil && !r.Changes.Empty():
+		return "yellow"
+	default:
+		return "green"
+	}
+}
@@ -0,0 +1,108 @@
+package components
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/idongju/t9s/internal/command"
+	"github.com/idongju/t9s/internal/config"
+)
+
+// StateOperation describes a terraform command that modifies state
+type StateOperation struct {
+	Action     string // history action, e.g. "state mv", "state rm", "import"
+	WorkDir    string
+	ConfigFile string   // tfvars file, only used by import
+	Args       []string // terraform arguments, e.g. ["state", "mv", src, dst]
+	Addresses  []string // resource addresses involved, recorded in history
+}
+
+// NewStateMove creates a `terraform state mv` operation
+func NewStateMove(workDir, source, destination string) *StateOperation {
+	return &StateOperation{
+		Action:    "state mv",
+		WorkDir:   workDir,
+		Args:      []string{"state", "mv", source, destination},
+		Addresses: []string{source, destination},
+	}
+}
+
+// NewStateRemove creates a `terraform state rm` operation
+func NewStateRemove(workDir string, addresses ...string) *StateOperation {
+	return &StateOperation{
+		Action:    "state rm",
+		WorkDir:   workDir,
+		Args:      append([]string{"state", "rm"}, addresses...),
+		Addresses: addresses,
+	}
+}
+
+// NewImport creates a `terraform import` operation, using varFile if set
+func NewImport(workDir, varFile, address, id string) *StateOperation {
+	args := []string{"import", "-input=false"}
+	if varFile != "" {
+		args = append(args, "-var-file="+varFile)
+	}
+	return &StateOperation{
+		Action:     "import",
+		WorkDir:    workDir,
+		ConfigFile: varFile,
+		Args:       append(args, address, id),
+		Addresses:  []string{address},
+	}
+}
+
+// Command returns the terraform command of the operation
+func (op *StateOperation) Command() *command.Command {
+	return &command.Command{Argv: append([]string{"terraform"}, op.Args...)}
+}
+
+// Environ resolves the environment profile for the operation
+func (op *StateOperation) Environ(cfg *config.Config) (*RunEnv, error) {
+	env := ""
+	if op.ConfigFile != "" {
+		env = configEnvName(op.ConfigFile)
+	}
+	return ResolveEnv(cfg, op.WorkDir, op.ConfigFile, env)
+}
+
+// BackupState writes the current state of a directory to backupDir and returns the backup path
+func BackupState(workDir, backupDir string, environ []string) (string, error) {
+	if err := os.MkdirAll(backupDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create backup dir: %w", err)
+	}
+
+	pull := &command.Command{Argv: []string{"terraform", "state", "pull"}}
+	cmd := pull.ExecEnv(workDir, environ)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("terraform state pull failed: %w", err)
+	}
+
+	name := strings.ReplaceAll(strings.Trim(filepath.ToSlash(workDir), "/"), "/", "_")
+	backupPath := filepath.Join(backupDir, fmt.Sprintf("%s-%s.tfstate", name, time.Now().Format("20060102-150405")))
+	if err := os.WriteFile(backupPath, output, 0600); err != nil {
+		return "", fmt.Errorf("failed to write backup: %w", err)
+	}
+	return backupPath, nil
+}
+
+// RunStateOperation backs up the state and runs the operation, streaming its output
+func RunStateOperation(op *StateOperation, cfg *config.Config, out func(line string)) error {
+	runEnv, err := op.Environ(cfg)
+	if err != nil {
+		return err
+	}
+
+	backupPath, err := BackupState(op.WorkDir, cfg.BackupDir(), runEnv.Environ)
+	if err != nil {
+		return fmt.Errorf("state backup failed, nothing was changed: %w", err)
+	}
+	out(fmt.Sprintf("State backup written to %s", backupPath))
+
+	cmd := op.Command().ExecEnv(op.WorkDir, runEnv.Environ)
+	return runStreaming(cmd, out)
+}
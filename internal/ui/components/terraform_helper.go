@@ -6,7 +6,10 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/idongju/t9s/internal/command"
 	"github.com/idongju/t9s/internal/config"
+	"github.com/idongju/t9s/internal/dao"
+	"github.com/idongju/t9s/internal/git"
 )
 
 // TerraformCommandInfo holds information about a terraform command
@@ -14,7 +17,13 @@ type TerraformCommandInfo struct {
 	WorkDir    string
 	ConfigFile string
 	Content    string
-	Command    string
+	Command    string           // shell-quoted preview of the rendered command
+	Cmd        *command.Command // rendered command, nil if the template is invalid
+	Vars       command.Vars
+	Addresses  []string // resource addresses passed with -replace, recorded in history
+	Targets    []string // addresses passed with -target, recorded in history
+	Env        *RunEnv  // environment from the matching profile, nil if it could not be resolved
+	Err        error    // template rendering or environment error
 }
 
 // GetTerraformCommandInfo prepares terraform command information
@@ -56,16 +65,112 @@ func GetTerraformCommandInfo(path string, template string, configFileName string
 	}
 
 	// Build command
-	cmdStr := template
+	info.Vars = CommandVars(info.WorkDir, info.ConfigFile, cfg)
+	info.Render(template)
+	if info.Err != nil {
+		return info
+	}
+
+	// Resolve the environment profile
+	info.Env, info.Err = ResolveEnv(cfg, info.WorkDir, info.ConfigFile, info.Vars["env"])
+
+	return info
+}
+
+// TypedCommandInfo prepares a terraform command typed in command mode, so that it runs with
+// the hooks, environment profile and history of the action it starts. A -var-file argument
+// selects the config file; -target and -replace arguments are recorded like the pickers' ones.
+func TypedCommandInfo(workDir, typed string, cfg *config.Config) *TerraformCommandInfo {
+	info := &TerraformCommandInfo{WorkDir: workDir}
+	info.Vars = CommandVars(workDir, "", cfg)
+	info.Render(typed)
+	if info.Err != nil {
+		return info
+	}
+
+	for _, arg := range info.Cmd.Argv {
+		switch {
+		case strings.HasPrefix(arg, "-var-file="):
+			info.ConfigFile = strings.TrimPrefix(arg, "-var-file=")
+			if !filepath.IsAbs(info.ConfigFile) {
+				info.ConfigFile = filepath.Join(workDir, info.ConfigFile)
+			}
+		case strings.HasPrefix(arg, "-target="):
+			info.Targets = append(info.Targets, strings.TrimPrefix(arg, "-target="))
+		case strings.HasPrefix(arg, "-replace="):
+			info.Addresses = append(info.Addresses, strings.TrimPrefix(arg, "-replace="))
+		}
+	}
 	if info.ConfigFile != "" {
-		cmdStr = strings.ReplaceAll(cmdStr, "{varfile}", info.ConfigFile)
-		cmdStr = strings.ReplaceAll(cmdStr, "{initconf}", info.ConfigFile)
-	} else {
-		cmdStr = strings.ReplaceAll(cmdStr, "-var-file={varfile}", "")
-		cmdStr = strings.ReplaceAll(cmdStr, "-backend-config={initconf}", "")
+		if content, err := ioutil.ReadFile(info.ConfigFile); err == nil {
+			info.Content = string(content)
+		}
+		info.Vars = CommandVars(workDir, info.ConfigFile, cfg)
 	}
-	info.Command = cmdStr
 
+	info.Env, info.Err = ResolveEnv(cfg, workDir, info.ConfigFile, info.Vars["env"])
 	return info
 }
 
+// Render (re-)renders the command from a template with the current variables
+func (info *TerraformCommandInfo) Render(template string) {
+	info.Cmd, info.Err = command.Render(template, info.Vars)
+	if info.Err != nil {
+		info.Command = template
+		return
+	}
+	info.Command = info.Cmd.String()
+}
+
+// Replace adds a -replace argument for each address
+func (info *TerraformCommandInfo) Replace(addresses ...string) {
+	for _, addr := range addresses {
+		info.Cmd.Append("-replace=" + addr)
+	}
+	info.Addresses = append(info.Addresses, addresses...)
+	info.Command = info.Cmd.String()
+}
+
+// Target adds a -target argument for each address
+func (info *TerraformCommandInfo) Target(addresses ...string) {
+	for _, addr := range addresses {
+		info.Cmd.Append("-target=" + addr)
+	}
+	info.Targets = append(info.Targets, addresses...)
+	info.Command = info.Cmd.String()
+}
+
+// CommandVars collects the template variables for a working directory and selected config file
+func CommandVars(workDir, configFile string, cfg *config.Config) command.Vars {
+	vars := command.Vars{
+		"dir":       workDir,
+		"stack":     filepath.Base(workDir),
+		"workspace": dao.NewTerraformDAO(cfg.TerraformRoot).CurrentWorkspace(workDir),
+		"branch":    git.NewManager().CurrentBranch(workDir),
+	}
+	if rel, err := filepath.Rel(cfg.TerraformRoot, workDir); err == nil && !strings.HasPrefix(rel, "..") && rel != "." {
+		vars["stack"] = filepath.ToSlash(rel)
+	}
+
+	if configFile != "" {
+		if strings.HasSuffix(configFile, ".tfvars") || strings.HasSuffix(configFile, ".tfvars.json") {
+			vars["varfile"] = configFile
+		} else {
+			vars["initconf"] = configFile
+		}
+		vars["env"] = configEnvName(configFile)
+	}
+
+	return vars
+}
+
+// configEnvName derives the environment name from a config file, e.g. "prod" for config/prod.tfvars
+func configEnvName(configFile string) string {
+	name := filepath.Base(configFile)
+	for _, ext := range []string{".tfvars.json", ".tfvars", ".conf", ".hcl"} {
+		if strings.HasSuffix(name, ext) {
+			return strings.TrimSuffix(name, ext)
+		}
+	}
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
@@ -0,0 +1,110 @@
+package dialog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// BatchActions are the actions that can run across several stacks at once
+var BatchActions = []string{"Init", "Plan", "Validate"}
+
+// BatchDialog asks for the action, tfvars name and parallelism of a batch run
+type BatchDialog struct {
+	*tview.Flex
+	form *tview.Form
+}
+
+// NewBatchDialog creates a new batch dialog. envs are the tfvars/conf names found in the stacks;
+// onRun receives the action, the chosen name ("" for the configured default) and the parallelism.
+func NewBatchDialog(stacks []string, envs []string, parallelism int, onRun func(action, env string, parallelism int), onCancel func()) *BatchDialog {
+	bd := &BatchDialog{
+		Flex: tview.NewFlex(),
+	}
+
+	action := BatchActions[0]
+	env := ""
+	options := append([]string{"(default)"}, envs...)
+
+	// Stack list
+	list := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true)
+	list.SetBackgroundColor(tcell.ColorBlack)
+	list.SetBorder(true).
+		SetBorderColor(tcell.NewRGBColor(0, 255, 255)).
+		SetTitle(fmt.Sprintf(" 📦 %d Stack(s) ", len(stacks)))
+	for _, s := range stacks {
+		fmt.Fprintf(list, " • %s\n", tview.Escape(s))
+	}
+
+	// Create form
+	bd.form = tview.NewForm().
+		AddDropDown("Action", BatchActions, 0, func(option string, index int) {
+			action = option
+		}).
+		AddDropDown("tfvars / conf", options, 0, func(option string, index int) {
+			env = ""
+			if index > 0 {
+				env = option
+			}
+		}).
+		AddInputField("Parallelism", strconv.Itoa(parallelism), 5, tview.InputFieldInteger, func(text string) {
+			if n, err := strconv.Atoi(strings.TrimSpace(text)); err == nil && n > 0 {
+				parallelism = n
+			}
+		}).
+		AddButton("Run", func() {
+			if onRun != nil {
+				onRun(action, env, parallelism)
+			}
+		}).
+		AddButton("Cancel", func() {
+			if onCancel != nil {
+				onCancel()
+			}
+		})
+
+	bd.form.SetBorder(true).
+		SetTitle(" 🚀 Batch Run ").
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.NewRGBColor(0, 255, 255))
+
+	bd.form.SetButtonsAlign(tview.AlignCenter).
+		SetButtonBackgroundColor(tcell.NewRGBColor(0, 100, 100)).
+		SetButtonTextColor(tcell.ColorWhite).
+		SetFieldBackgroundColor(tcell.NewRGBColor(30, 30, 30))
+	bd.form.SetBackgroundColor(tcell.ColorBlack)
+
+	bd.form.SetCancelFunc(func() {
+		if onCancel != nil {
+			onCancel()
+		}
+	})
+
+	panel := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(list, 0, 1, false).
+		AddItem(bd.form, 11, 0, true)
+
+	// Create layout
+	bd.SetDirection(tview.FlexRow).
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexColumn).
+			AddItem(nil, 0, 1, false).
+			AddItem(panel, 70, 1, true).
+			AddItem(nil, 0, 1, false), 0, 4, true).
+		AddItem(nil, 0, 1, false)
+
+	bd.SetBackgroundColor(tcell.ColorDefault)
+
+	return bd
+}
+
+// GetForm returns the form component
+func (bd *BatchDialog) GetForm() *tview.Form {
+	return bd.form
+}
@@ -0,0 +1,91 @@
+package dialog
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/idongju/t9s/internal/config"
+	"github.com/rivo/tview"
+)
+
+// ContextDialog represents a context selection dialog
+type ContextDialog struct {
+	*tview.Flex
+	list     *tview.List
+	onSelect func(name string)
+	onCancel func()
+}
+
+// NewContextDialog creates a new context selection dialog
+func NewContextDialog(contexts []config.ContextConfig, current string, onSelect func(name string), onCancel func()) *ContextDialog {
+	cd := &ContextDialog{
+		Flex:     tview.NewFlex(),
+		onSelect: onSelect,
+		onCancel: onCancel,
+	}
+
+	// Create list
+	cd.list = tview.NewList().
+		ShowSecondaryText(true).
+		SetHighlightFullLine(true).
+		SetSecondaryTextColor(tcell.ColorGray)
+
+	// Add contexts to list
+	for i, ctx := range contexts {
+		name := ctx.Name
+		label := fmt.Sprintf("  %s", name)
+		if name == current {
+			label = fmt.Sprintf("* %s (current)", name)
+		}
+		cd.list.AddItem(label, "    "+ctx.TerraformRoot, 0, func() {
+			if cd.onSelect != nil {
+				cd.onSelect(name)
+			}
+		})
+		if name == current {
+			cd.list.SetCurrentItem(i)
+		}
+	}
+
+	cd.list.SetBorder(true).
+		SetTitle(" 🧭 Select Context ").
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(tcell.NewRGBColor(0, 255, 255))
+
+	// Set up key bindings
+	cd.list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			if cd.onCancel != nil {
+				cd.onCancel()
+			}
+			return nil
+		case tcell.KeyRune:
+			if event.Rune() == 'q' || event.Rune() == 'Q' {
+				if cd.onCancel != nil {
+					cd.onCancel()
+				}
+				return nil
+			}
+		}
+		return event
+	})
+
+	// Create layout
+	cd.SetDirection(tview.FlexRow).
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexColumn).
+			AddItem(nil, 0, 1, false).
+			AddItem(cd.list, 70, 1, true).
+			AddItem(nil, 0, 1, false), 20, 1, true).
+		AddItem(nil, 0, 1, false)
+
+	cd.SetBackgroundColor(tcell.ColorDefault)
+
+	return cd
+}
+
+// GetList returns the list component
+func (cd *ContextDialog) GetList() *tview.List {
+	return cd.list
+}
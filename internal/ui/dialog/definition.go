@@ -0,0 +1,111 @@
+package dialog
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/idongju/t9s/internal/model"
+	"github.com/rivo/tview"
+)
+
+// DefinitionDialog lists the references of a file and jumps to where they are declared
+type DefinitionDialog struct {
+	*tview.Flex
+	list     *tview.List
+	refs     []*model.SymbolReference
+	onSelect func(ref *model.SymbolReference)
+	onCancel func()
+}
+
+// NewDefinitionDialog creates a new definition picker. The reference on or after line is
+// selected first. onSelect is only called for references declared in the stack.
+func NewDefinitionDialog(file string, refs []*model.SymbolReference, line int, onSelect func(ref *model.SymbolReference), onCancel func()) *DefinitionDialog {
+	dd := &DefinitionDialog{
+		Flex:     tview.NewFlex(),
+		refs:     refs,
+		onSelect: onSelect,
+		onCancel: onCancel,
+	}
+
+	// Create list
+	dd.list = tview.NewList().
+		ShowSecondaryText(false).
+		SetHighlightFullLine(true)
+
+	current := -1
+	for i, ref := range refs {
+		ref := ref
+		target := "[red]not declared in this stack[white]"
+		if ref.DefFile != "" {
+			target = fmt.Sprintf("[gray]→ %s:%d[white]", tview.Escape(filepath.Base(ref.DefFile)), ref.DefLine)
+		}
+		label := fmt.Sprintf("[gray]%4d[white]  [#a6e22e]%s[white]  %s", ref.Line, tview.Escape(ref.Address), target)
+		dd.list.AddItem(label, "", 0, func() {
+			if dd.onSelect != nil && ref.DefFile != "" {
+				dd.onSelect(ref)
+			}
+		})
+		if current < 0 && ref.Line >= line {
+			current = i
+		}
+	}
+	if current >= 0 {
+		dd.list.SetCurrentItem(current)
+	}
+
+	dd.list.SetBorder(true).
+		SetTitle(fmt.Sprintf(" 🔗 References: %s ", filepath.Base(file))).
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(tcell.NewRGBColor(0, 255, 255))
+
+	// Help line
+	help := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignCenter)
+	help.SetBackgroundColor(tcell.ColorBlack)
+	fmt.Fprintf(help, "[yellow]<Enter>[white] Go to Definition  [yellow]<Esc>[white] Close")
+
+	// Set up key bindings
+	dd.list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			if dd.onCancel != nil {
+				dd.onCancel()
+			}
+			return nil
+		case tcell.KeyRune:
+			switch event.Rune() {
+			case 'q', 'Q':
+				if dd.onCancel != nil {
+					dd.onCancel()
+				}
+				return nil
+			}
+		}
+		return event
+	})
+
+	panel := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(dd.list, 0, 1, true).
+		AddItem(help, 1, 0, false)
+
+	// Create layout
+	dd.SetDirection(tview.FlexRow).
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexColumn).
+			AddItem(nil, 0, 1, false).
+			AddItem(panel, 80, 1, true).
+			AddItem(nil, 0, 1, false), 22, 1, true).
+		AddItem(nil, 0, 1, false)
+
+	dd.SetBackgroundColor(tcell.ColorDefault)
+
+	return dd
+}
+
+// GetList returns the list component
+func (dd *DefinitionDialog) GetList() *tview.List {
+	return dd.list
+}
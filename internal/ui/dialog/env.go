@@ -0,0 +1,96 @@
+package dialog
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// EnvDialog asks which tfvars or conf name a batch run uses
+type EnvDialog struct {
+	*tview.Flex
+	list *tview.List
+}
+
+// NewEnvDialog creates a new env picker. The first entry is the configured default, described
+// by defaultLabel; onSelect receives the chosen name, or "" for the default.
+func NewEnvDialog(title, defaultLabel string, envs []string, onSelect func(env string), onCancel func()) *EnvDialog {
+	ed := &EnvDialog{
+		Flex: tview.NewFlex(),
+	}
+
+	// Create list
+	ed.list = tview.NewList().
+		ShowSecondaryText(false).
+		SetHighlightFullLine(true)
+
+	ed.list.AddItem(fmt.Sprintf("[gray]%s[white]", tview.Escape(defaultLabel)), "", 0, func() {
+		if onSelect != nil {
+			onSelect("")
+		}
+	})
+	for _, env := range envs {
+		env := env
+		ed.list.AddItem(tview.Escape(env), "", 0, func() {
+			if onSelect != nil {
+				onSelect(env)
+			}
+		})
+	}
+
+	ed.list.SetBorder(true).
+		SetTitle(title).
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(tcell.NewRGBColor(0, 255, 255))
+
+	// Help line
+	help := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignCenter)
+	help.SetBackgroundColor(tcell.ColorBlack)
+	fmt.Fprintf(help, "[yellow]<Enter>[white] Select  [yellow]<Esc>[white] Cancel")
+
+	// Set up key bindings
+	ed.list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			if onCancel != nil {
+				onCancel()
+			}
+			return nil
+		case tcell.KeyRune:
+			switch event.Rune() {
+			case 'q', 'Q':
+				if onCancel != nil {
+					onCancel()
+				}
+				return nil
+			}
+		}
+		return event
+	})
+
+	panel := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(ed.list, 0, 1, true).
+		AddItem(help, 1, 0, false)
+
+	// Create layout
+	ed.SetDirection(tview.FlexRow).
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexColumn).
+			AddItem(nil, 0, 1, false).
+			AddItem(panel, 60, 1, true).
+			AddItem(nil, 0, 1, false), 14, 1, true).
+		AddItem(nil, 0, 1, false)
+
+	ed.SetBackgroundColor(tcell.ColorDefault)
+
+	return ed
+}
+
+// GetList returns the list component
+func (ed *EnvDialog) GetList() *tview.List {
+	return ed.list
+}
@@ -0,0 +1,92 @@
+package dialog
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// ImportDialog represents a resource import dialog asking for address and ID
+type ImportDialog struct {
+	*tview.Flex
+	form     *tview.Form
+	onImport func(address, id string)
+	onBlock  func(address, id string)
+	onCancel func()
+	address  string
+	id       string
+}
+
+// NewImportDialog creates a new import dialog.
+// onImport runs terraform import, onBlock writes a declarative import block instead.
+func NewImportDialog(address string, onImport, onBlock func(address, id string), onCancel func()) *ImportDialog {
+	id := &ImportDialog{
+		Flex:     tview.NewFlex(),
+		onImport: onImport,
+		onBlock:  onBlock,
+		onCancel: onCancel,
+		address:  address,
+	}
+
+	// Create form
+	id.form = tview.NewForm().
+		AddInputField("Resource Address", address, 50, nil, func(text string) {
+			id.address = text
+		}).
+		AddInputField("Import ID", "", 50, nil, func(text string) {
+			id.id = text
+		}).
+		AddButton("Import", func() {
+			if id.onImport != nil && id.address != "" && id.id != "" {
+				id.onImport(id.address, id.id)
+			}
+		}).
+		AddButton("Import Block", func() {
+			if id.onBlock != nil && id.address != "" && id.id != "" {
+				id.onBlock(id.address, id.id)
+			}
+		}).
+		AddButton("Cancel", func() {
+			if id.onCancel != nil {
+				id.onCancel()
+			}
+		})
+
+	id.form.SetBorder(true).
+		SetTitle(" 📥 Import Resource ").
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.NewRGBColor(0, 255, 255))
+
+	id.form.SetButtonsAlign(tview.AlignCenter).
+		SetButtonBackgroundColor(tcell.NewRGBColor(0, 100, 100)).
+		SetButtonTextColor(tcell.ColorWhite).
+		SetFieldBackgroundColor(tcell.NewRGBColor(30, 30, 30))
+
+	// Set up key bindings
+	id.form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			if id.onCancel != nil {
+				id.onCancel()
+			}
+			return nil
+		}
+		return event
+	})
+
+	// Create layout
+	id.SetDirection(tview.FlexRow).
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexColumn).
+			AddItem(nil, 0, 1, false).
+			AddItem(id.form, 74, 1, true).
+			AddItem(nil, 0, 1, false), 11, 1, true).
+		AddItem(nil, 0, 1, false)
+
+	id.SetBackgroundColor(tcell.ColorDefault)
+
+	return id
+}
+
+// GetForm returns the form component
+func (id *ImportDialog) GetForm() *tview.Form {
+	return id.form
+}
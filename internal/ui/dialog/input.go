@@ -0,0 +1,87 @@
+package dialog
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// InputDialog represents a single-line text input dialog
+type InputDialog struct {
+	*tview.Flex
+	form     *tview.Form
+	onSubmit func(text string)
+	onCancel func()
+	text     string
+}
+
+// NewInputDialog creates a new input dialog
+func NewInputDialog(title, label, initial, button string, onSubmit func(text string), onCancel func()) *InputDialog {
+	id := &InputDialog{
+		Flex:     tview.NewFlex(),
+		onSubmit: onSubmit,
+		onCancel: onCancel,
+		text:     initial,
+	}
+
+	// Create form
+	id.form = tview.NewForm().
+		AddInputField(label, initial, 50, nil, func(text string) {
+			id.text = text
+		}).
+		AddButton(button, func() {
+			if id.onSubmit != nil && id.text != "" {
+				id.onSubmit(id.text)
+			}
+		}).
+		AddButton("Cancel", func() {
+			if id.onCancel != nil {
+				id.onCancel()
+			}
+		})
+
+	id.form.SetBorder(true).
+		SetTitle(title).
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.NewRGBColor(0, 255, 255))
+
+	id.form.SetButtonsAlign(tview.AlignCenter).
+		SetButtonBackgroundColor(tcell.NewRGBColor(0, 100, 100)).
+		SetButtonTextColor(tcell.ColorWhite).
+		SetFieldBackgroundColor(tcell.NewRGBColor(30, 30, 30))
+
+	// Set up key bindings
+	id.form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			if id.onCancel != nil {
+				id.onCancel()
+			}
+			return nil
+		}
+		return event
+	})
+
+	// Create layout
+	id.SetDirection(tview.FlexRow).
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexColumn).
+			AddItem(nil, 0, 1, false).
+			AddItem(id.form, 70, 1, true).
+			AddItem(nil, 0, 1, false), 10, 1, true).
+		AddItem(nil, 0, 1, false)
+
+	id.SetBackgroundColor(tcell.ColorDefault)
+
+	return id
+}
+
+// SetAutocomplete offers completions for the input while typing
+func (id *InputDialog) SetAutocomplete(complete func(text string) []string) {
+	if input, ok := id.form.GetFormItem(0).(*tview.InputField); ok {
+		input.SetAutocompleteFunc(complete)
+	}
+}
+
+// GetForm returns the form component
+func (id *InputDialog) GetForm() *tview.Form {
+	return id.form
+}
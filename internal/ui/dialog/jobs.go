@@ -0,0 +1,95 @@
+package dialog
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// JobsDialog lists finished background jobs to open the output of one of them
+type JobsDialog struct {
+	*tview.Flex
+	list     *tview.List
+	onSelect func(index int)
+	onCancel func()
+}
+
+// NewJobsDialog creates a new job picker. labels describe the jobs, newest first, and may
+// contain style tags; onSelect receives the index of the chosen job.
+func NewJobsDialog(labels []string, onSelect func(index int), onCancel func()) *JobsDialog {
+	jd := &JobsDialog{
+		Flex:     tview.NewFlex(),
+		onSelect: onSelect,
+		onCancel: onCancel,
+	}
+
+	// Create list
+	jd.list = tview.NewList().
+		ShowSecondaryText(false).
+		SetHighlightFullLine(true)
+
+	for i, label := range labels {
+		i := i
+		jd.list.AddItem(label, "", 0, func() {
+			if jd.onSelect != nil {
+				jd.onSelect(i)
+			}
+		})
+	}
+
+	jd.list.SetBorder(true).
+		SetTitle(fmt.Sprintf(" 🔔 Finished Jobs (%d) ", len(labels))).
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(tcell.NewRGBColor(0, 255, 255))
+
+	// Help line
+	help := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignCenter)
+	help.SetBackgroundColor(tcell.ColorBlack)
+	fmt.Fprintf(help, "[yellow]<Enter>[white] Open Output  [yellow]<Esc>[white] Close")
+
+	// Set up key bindings
+	jd.list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			if jd.onCancel != nil {
+				jd.onCancel()
+			}
+			return nil
+		case tcell.KeyRune:
+			switch event.Rune() {
+			case 'q', 'Q':
+				if jd.onCancel != nil {
+					jd.onCancel()
+				}
+				return nil
+			}
+		}
+		return event
+	})
+
+	panel := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(jd.list, 0, 1, true).
+		AddItem(help, 1, 0, false)
+
+	// Create layout
+	jd.SetDirection(tview.FlexRow).
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexColumn).
+			AddItem(nil, 0, 1, false).
+			AddItem(panel, 90, 1, true).
+			AddItem(nil, 0, 1, false), 16, 1, true).
+		AddItem(nil, 0, 1, false)
+
+	jd.SetBackgroundColor(tcell.ColorDefault)
+
+	return jd
+}
+
+// GetList returns the list component
+func (jd *JobsDialog) GetList() *tview.List {
+	return jd.list
+}
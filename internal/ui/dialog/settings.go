@@ -5,6 +5,7 @@ import (
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
+	"github.com/idongju/t9s/internal/command"
 	"github.com/idongju/t9s/internal/config"
 )
 
@@ -22,6 +23,12 @@ func NewSettingsDialog(cfg *config.Config, onSave, onCancel func()) *SettingsDia
 		config: cfg,
 	}
 
+	help := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignLeft)
+	help.SetBackgroundColor(tcell.ColorBlack)
+	showTemplateHelp(help)
+
 	form := tview.NewForm().
 		AddInputField("Terraform Root Directory", cfg.TerraformRoot, 60, nil, func(text string) {
 			cfg.TerraformRoot = text
@@ -38,6 +45,15 @@ func NewSettingsDialog(cfg *config.Config, onSave, onCancel func()) *SettingsDia
 		AddInputField("Terraform Destroy Template", cfg.Commands.DestroyTemplate, 60, nil, func(text string) {
 			cfg.Commands.DestroyTemplate = text
 		}).
+		AddInputField("Terraform Refresh Template", cfg.Commands.RefreshTemplate, 60, nil, func(text string) {
+			cfg.Commands.RefreshTemplate = text
+		}).
+		AddInputField("Terraform Refresh Plan Template", cfg.Commands.RefreshPlanTemplate, 60, nil, func(text string) {
+			cfg.Commands.RefreshPlanTemplate = text
+		}).
+		AddInputField("Terraform Validate Template", cfg.Commands.ValidateTemplate, 60, nil, func(text string) {
+			cfg.Commands.ValidateTemplate = text
+		}).
 		AddInputField("Default tfvars File", cfg.Commands.TfvarsFile, 60, nil, func(text string) {
 			cfg.Commands.TfvarsFile = text
 		}).
@@ -49,6 +65,9 @@ func NewSettingsDialog(cfg *config.Config, onSave, onCancel func()) *SettingsDia
 				onSave()
 			}
 		}).
+		AddButton("Preview", func() {
+			showTemplatePreview(help, cfg)
+		}).
 		AddButton("Cancel", func() {
 			if onCancel != nil {
 				onCancel()
@@ -69,20 +88,10 @@ func NewSettingsDialog(cfg *config.Config, onSave, onCancel func()) *SettingsDia
 	fmt.Fprintf(header, "[::b][cyan]║[white]  T9s - Settings  [cyan]║\n")
 	fmt.Fprintf(header, "[::b][cyan]╚═══════════════════════════════════════════════════════════════════╝")
 
-	help := tview.NewTextView().
-		SetDynamicColors(true).
-		SetTextAlign(tview.AlignLeft)
-	help.SetBackgroundColor(tcell.ColorBlack)
-	fmt.Fprintf(help, "\n[yellow]Template Variables:[white]\n")
-	fmt.Fprintf(help, "  [cyan]{varfile}[white] - tfvars file path  [cyan]{initconf}[white] - init config file path\n")
-	fmt.Fprintf(help, "\n[yellow]Examples:[white]\n")
-	fmt.Fprintf(help, "  [green]terraform init -backend-config={initconf}[white]\n")
-	fmt.Fprintf(help, "  [green]terraform plan -var-file={varfile}[white]\n")
-
 	sd.SetDirection(tview.FlexRow).
 		AddItem(header, 3, 0, false).
 		AddItem(form, 0, 1, true).
-		AddItem(help, 6, 0, false)
+		AddItem(help, 10, 0, false)
 	sd.SetBackgroundColor(tcell.ColorBlack)
 
 	sd.form = form
@@ -94,3 +103,43 @@ func (sd *SettingsDialog) GetForm() *tview.Form {
 	return sd.form
 }
 
+// showTemplateHelp explains the template syntax
+func showTemplateHelp(help *tview.TextView) {
+	help.Clear()
+	fmt.Fprintf(help, "\n[yellow]Template Variables:[white]\n")
+	fmt.Fprintf(help, "  [cyan]{dir}[white] [cyan]{stack}[white] [cyan]{env}[white] [cyan]{varfile}[white] [cyan]{initconf}[white] [cyan]{workspace}[white] [cyan]{branch}[white]\n")
+	fmt.Fprintf(help, "  Words with an unset variable are dropped. [cyan]{?varfile ...}[white] only if set, [cyan]{!varfile ...}[white] only if unset.\n")
+	fmt.Fprintf(help, "  Quote like a shell; prefix with [cyan]shell:[white] to use pipes or redirects.\n")
+	fmt.Fprintf(help, "\n[yellow]Examples:[white]\n")
+	fmt.Fprintf(help, "  [green]terraform init -backend-config={initconf}[white]\n")
+	fmt.Fprintf(help, "  [green]AWS_PROFILE={env} terraform plan {?varfile -var-file={varfile} -out={env}.tfplan}[white]\n")
+	fmt.Fprintf(help, "  [gray]Press Preview for a dry run with sample values[white]\n")
+}
+
+// showTemplatePreview renders every template with sample values
+func showTemplatePreview(help *tview.TextView, cfg *config.Config) {
+	help.Clear()
+	sample := command.SampleVars()
+	fmt.Fprintf(help, "\n[yellow]Dry Run[white] [gray](env=%s, varfile=%s)[white]\n", sample["env"], sample["varfile"])
+	templates := []struct {
+		name     string
+		template string
+	}{
+		{"Init", cfg.Commands.InitTemplate},
+		{"Plan", cfg.Commands.PlanTemplate},
+		{"Apply", cfg.Commands.ApplyTemplate},
+		{"Destroy", cfg.Commands.DestroyTemplate},
+		{"Refresh", cfg.Commands.RefreshTemplate},
+		{"Refresh Plan", cfg.Commands.RefreshPlanTemplate},
+		{"Validate", cfg.Commands.ValidateTemplate},
+	}
+	for _, t := range templates {
+		cmd, err := command.Preview(t.template)
+		if err != nil {
+			fmt.Fprintf(help, "  [cyan]%-12s[white] [red]%v[white]\n", t.name, err)
+			continue
+		}
+		fmt.Fprintf(help, "  [cyan]%-12s[white] %s\n", t.name, tview.Escape(cmd.String()))
+	}
+	help.ScrollToBeginning()
+}
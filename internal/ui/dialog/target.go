@@ -0,0 +1,158 @@
+package dialog
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// TargetDialog represents a multi-select picker of resource and module addresses for -target
+type TargetDialog struct {
+	*tview.Flex
+	list      *tview.List
+	addresses []string
+	notes     map[string]string
+	selected  map[string]bool
+	onRun     func(action string, targets []string)
+	onCancel  func()
+}
+
+// NewTargetDialog creates a new target picker. onRun receives "Plan" or "Apply" with the selected addresses.
+func NewTargetDialog(stack string, addresses []string, onRun func(action string, targets []string), onCancel func()) *TargetDialog {
+	td := &TargetDialog{
+		Flex:      tview.NewFlex(),
+		addresses: addresses,
+		selected:  make(map[string]bool),
+		onRun:     onRun,
+		onCancel:  onCancel,
+	}
+
+	// Create list
+	td.list = tview.NewList().
+		ShowSecondaryText(false).
+		SetHighlightFullLine(true)
+	for range addresses {
+		td.list.AddItem("", "", 0, nil)
+	}
+	td.refresh()
+
+	td.list.SetBorder(true).
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(tcell.NewRGBColor(255, 165, 0))
+	td.list.SetTitle(fmt.Sprintf(" 🎯 Select Targets: %s ", stack))
+
+	// Warning and help lines
+	banner := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignCenter)
+	banner.SetBackgroundColor(tcell.ColorBlack)
+	fmt.Fprintf(banner, "[red::b]⚠ -target is for exceptional situations; the rest of the stack is left unplanned[-:-:-]")
+
+	help := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignCenter)
+	help.SetBackgroundColor(tcell.ColorBlack)
+	fmt.Fprintf(help, "[yellow]<Space>[white] Toggle  [yellow]<p>[white] Plan  [yellow]<a>[white] Apply  [yellow]<Esc>[white] Cancel")
+
+	// Set up key bindings
+	td.list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			if td.onCancel != nil {
+				td.onCancel()
+			}
+			return nil
+		case tcell.KeyEnter:
+			td.toggle()
+			return nil
+		case tcell.KeyRune:
+			switch event.Rune() {
+			case ' ':
+				td.toggle()
+				return nil
+			case 'p', 'P':
+				td.run("Plan")
+				return nil
+			case 'a', 'A':
+				td.run("Apply")
+				return nil
+			case 'q', 'Q':
+				if td.onCancel != nil {
+					td.onCancel()
+				}
+				return nil
+			}
+		}
+		return event
+	})
+
+	panel := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(banner, 1, 0, false).
+		AddItem(td.list, 0, 1, true).
+		AddItem(help, 1, 0, false)
+
+	// Create layout
+	td.SetDirection(tview.FlexRow).
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexColumn).
+			AddItem(nil, 0, 1, false).
+			AddItem(panel, 90, 1, true).
+			AddItem(nil, 0, 1, false), 0, 4, true).
+		AddItem(nil, 0, 1, false)
+
+	td.SetBackgroundColor(tcell.ColorDefault)
+
+	return td
+}
+
+// GetList returns the list component
+func (td *TargetDialog) GetList() *tview.List {
+	return td.list
+}
+
+// SetNotes shows a note after addresses, e.g. the action a plan has for them
+func (td *TargetDialog) SetNotes(notes map[string]string) *TargetDialog {
+	td.notes = notes
+	td.refresh()
+	return td
+}
+
+// toggle toggles the address under the cursor
+func (td *TargetDialog) toggle() {
+	if len(td.addresses) == 0 {
+		return
+	}
+	addr := td.addresses[td.list.GetCurrentItem()]
+	td.selected[addr] = !td.selected[addr]
+	td.refresh()
+}
+
+// run calls onRun with the selected addresses in list order
+func (td *TargetDialog) run(action string) {
+	var targets []string
+	for _, addr := range td.addresses {
+		if td.selected[addr] {
+			targets = append(targets, addr)
+		}
+	}
+	if len(targets) > 0 && td.onRun != nil {
+		td.onRun(action, targets)
+	}
+}
+
+// refresh redraws the checkboxes
+func (td *TargetDialog) refresh() {
+	for i, addr := range td.addresses {
+		mark := "[ ]"
+		if td.selected[addr] {
+			mark = "[x]"
+		}
+		text := fmt.Sprintf("%s %s", tview.Escape(mark), tview.Escape(addr))
+		if note := td.notes[addr]; note != "" {
+			text += fmt.Sprintf(" [gray](%s)[white]", tview.Escape(note))
+		}
+		td.list.SetItemText(i, text, "")
+	}
+}
@@ -13,8 +13,20 @@ type TerraformConfirmDialog struct {
 	*tview.Flex
 }
 
+// TerraformConfirmInfo holds what the confirmation dialog shows about a command
+type TerraformConfirmInfo struct {
+	Command     string   // shell-quoted preview of the command
+	Argv        []string // exact arguments passed to the process (dry run)
+	WorkDir     string
+	ConfigFile  string
+	FileContent string
+	Profile     string   // matched environment profile, empty if none
+	EnvLines    []string // variables set or unset by the profile, secrets masked
+	Warning     string   // shown as a banner above the command, e.g. for -target runs
+}
+
 // NewTerraformConfirmDialog creates a new terraform confirmation dialog
-func NewTerraformConfirmDialog(command, workDir, configFile, fileContent string, onExecute, onAutoApprove, onCancel func()) *TerraformConfirmDialog {
+func NewTerraformConfirmDialog(ci TerraformConfirmInfo, onExecute, onAutoApprove, onCancel func()) *TerraformConfirmDialog {
 	td := &TerraformConfirmDialog{
 		Flex: tview.NewFlex(),
 	}
@@ -33,13 +45,29 @@ func NewTerraformConfirmDialog(command, workDir, configFile, fileContent string,
 		SetDynamicColors(true).
 		SetTextAlign(tview.AlignLeft)
 	info.SetBackgroundColor(tcell.ColorBlack)
-	fmt.Fprintf(info, "\n[cyan]Command:[white] %s\n", command)
-	fmt.Fprintf(info, "[cyan]Directory:[white] %s\n", workDir)
-	fmt.Fprintf(info, "[cyan]Config File:[white] %s\n\n", configFile)
+	fmt.Fprintf(info, "\n[cyan]Command:[white] %s\n", tview.Escape(ci.Command))
+	fmt.Fprintf(info, "[cyan]Directory:[white] %s\n", ci.WorkDir)
+	fmt.Fprintf(info, "[cyan]Config File:[white] %s\n", ci.ConfigFile)
+	if ci.Profile != "" {
+		fmt.Fprintf(info, "[cyan]Env Profile:[white] %s\n", tview.Escape(ci.Profile))
+		for _, line := range ci.EnvLines {
+			fmt.Fprintf(info, "  [gray]•[white] %s\n", tview.Escape(line))
+		}
+	} else {
+		fmt.Fprintf(info, "[cyan]Env Profile:[white] [gray](none, shell environment inherited)[white]\n")
+	}
+	fmt.Fprintf(info, "\n")
+	if len(ci.Argv) > 0 {
+		fmt.Fprintf(info, "[yellow]Dry Run (arguments as passed to the process):[white]\n")
+		for i, arg := range ci.Argv {
+			fmt.Fprintf(info, "  [gray]%2d[white] %s\n", i, tview.Escape(arg))
+		}
+		fmt.Fprintf(info, "\n")
+	}
 	fmt.Fprintf(info, "[yellow]Config Content:[white]\n")
 	fmt.Fprintf(info, "[green]%s[white]\n", strings.Repeat("─", 65))
-	if fileContent != "" {
-		fmt.Fprintf(info, "%s\n", fileContent)
+	if ci.FileContent != "" {
+		fmt.Fprintf(info, "%s\n", ci.FileContent)
 	} else {
 		fmt.Fprintf(info, "[gray](empty or file not found)[white]\n")
 	}
@@ -76,8 +104,16 @@ func NewTerraformConfirmDialog(command, workDir, configFile, fileContent string,
 	form.SetButtonsAlign(tview.AlignCenter)
 
 	td.SetDirection(tview.FlexRow).
-		AddItem(header, 3, 0, false).
-		AddItem(info, 0, 1, false).
+		AddItem(header, 3, 0, false)
+	if ci.Warning != "" {
+		banner := tview.NewTextView().
+			SetDynamicColors(true).
+			SetTextAlign(tview.AlignCenter)
+		banner.SetBackgroundColor(tcell.ColorDarkRed)
+		fmt.Fprintf(banner, "[white::b]⚠  %s  ⚠", tview.Escape(ci.Warning))
+		td.AddItem(banner, 1, 0, false)
+	}
+	td.AddItem(info, 0, 1, false).
 		AddItem(question, 2, 0, false).
 		AddItem(form, 3, 0, true)
 	td.SetBackgroundColor(tcell.ColorBlack)
@@ -90,8 +126,8 @@ func NewTerraformConfirmDialog(command, workDir, configFile, fileContent string,
 
 // GetForm returns the form for focus management
 func (td *TerraformConfirmDialog) GetForm() *tview.Form {
-	// Get the form from the flex layout
-	item := td.GetItem(3)
+	// The form is the last item of the flex layout
+	item := td.GetItem(td.GetItemCount() - 1)
 	if form, ok := item.(*tview.Form); ok {
 		return form
 	}
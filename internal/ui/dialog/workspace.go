@@ -0,0 +1,118 @@
+package dialog
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// WorkspaceDialog represents a terraform workspace panel for a stack
+type WorkspaceDialog struct {
+	*tview.Flex
+	list       *tview.List
+	workspaces []string
+	onSelect   func(name string)
+	onNew      func()
+	onDelete   func(name string)
+	onCancel   func()
+}
+
+// NewWorkspaceDialog creates a new workspace dialog
+func NewWorkspaceDialog(stack string, workspaces []string, current string, onSelect func(name string), onNew func(), onDelete func(name string), onCancel func()) *WorkspaceDialog {
+	wd := &WorkspaceDialog{
+		Flex:       tview.NewFlex(),
+		workspaces: workspaces,
+		onSelect:   onSelect,
+		onNew:      onNew,
+		onDelete:   onDelete,
+		onCancel:   onCancel,
+	}
+
+	// Create list
+	wd.list = tview.NewList().
+		ShowSecondaryText(false).
+		SetHighlightFullLine(true)
+
+	// Add workspaces to list
+	for i, ws := range workspaces {
+		name := ws
+		label := fmt.Sprintf("  %s", name)
+		if name == current {
+			label = fmt.Sprintf("* %s (current)", name)
+		}
+		wd.list.AddItem(label, "", 0, func() {
+			if wd.onSelect != nil {
+				wd.onSelect(name)
+			}
+		})
+		if name == current {
+			wd.list.SetCurrentItem(i)
+		}
+	}
+
+	wd.list.SetBorder(true).
+		SetTitle(fmt.Sprintf(" 🗂  Workspaces: %s ", stack)).
+		SetTitleAlign(tview.AlignLeft).
+		SetBorderColor(tcell.NewRGBColor(0, 255, 255))
+
+	// Help line
+	help := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignCenter)
+	help.SetBackgroundColor(tcell.ColorBlack)
+	fmt.Fprintf(help, "[yellow]<Enter>[white] Select  [yellow]<n>[white] New  [yellow]<D>[white] Delete  [yellow]<Esc>[white] Close")
+
+	// Set up key bindings
+	wd.list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			if wd.onCancel != nil {
+				wd.onCancel()
+			}
+			return nil
+		case tcell.KeyRune:
+			switch event.Rune() {
+			case 'q', 'Q':
+				if wd.onCancel != nil {
+					wd.onCancel()
+				}
+				return nil
+			case 'n', 'N':
+				if wd.onNew != nil {
+					wd.onNew()
+				}
+				return nil
+			case 'D':
+				if wd.onDelete != nil && len(wd.workspaces) > 0 {
+					wd.onDelete(wd.workspaces[wd.list.GetCurrentItem()])
+				}
+				return nil
+			}
+		}
+		return event
+	})
+
+	panel := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(wd.list, 0, 1, true).
+		AddItem(help, 1, 0, false)
+
+	// Create layout
+	wd.SetDirection(tview.FlexRow).
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexColumn).
+			AddItem(nil, 0, 1, false).
+			AddItem(panel, 60, 1, true).
+			AddItem(nil, 0, 1, false), 18, 1, true).
+		AddItem(nil, 0, 1, false)
+
+	wd.SetBackgroundColor(tcell.ColorDefault)
+
+	return wd
+}
+
+// GetList returns the list component
+func (wd *WorkspaceDialog) GetList() *tview.List {
+	return wd.list
+}
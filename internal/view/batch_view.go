@@ -0,0 +1,199 @@
+package view
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Batch row states
+const (
+	BatchQueued  = "queued"
+	BatchRunning = "running"
+	BatchOK      = "ok"
+	BatchFailed  = "failed"
+	BatchSkipped = "skipped"
+)
+
+// BatchRow is the state of one stack in a batch run
+type BatchRow struct {
+	Stack    string
+	State    string // BatchQueued, BatchRunning, BatchOK, BatchFailed or BatchSkipped
+	Changes  string // e.g. "+1 ~2 -0", "" if unknown
+	Duration time.Duration
+	Output   []string
+}
+
+// BatchView shows the per-stack summary of a batch run with the output of the selected stack
+type BatchView struct {
+	*tview.Flex
+	table  *tview.Table
+	output *tview.TextView
+	action string
+	rows   []BatchRow
+	shown  int // row whose output is displayed, -1 for none
+}
+
+// NewBatchView creates a new batch summary view
+func NewBatchView(action string, rows []BatchRow) *BatchView {
+	bv := &BatchView{
+		Flex:   tview.NewFlex(),
+		action: action,
+		rows:   rows,
+		shown:  -1,
+	}
+
+	// Summary table
+	bv.table = tview.NewTable().
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	bv.table.SetBackgroundColor(tcell.ColorBlack)
+	bv.table.SetBorder(true)
+	bv.table.SetBorderColor(tcell.NewRGBColor(0, 255, 255))
+	bv.table.SetSelectedStyle(tcell.StyleDefault.
+		Background(tcell.NewRGBColor(0, 95, 135)).
+		Foreground(tcell.ColorWhite))
+	bv.table.SetSelectedFunc(func(row, column int) {
+		bv.ShowOutput(row - 1)
+	})
+
+	// Output pane
+	bv.output = tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetWrap(true)
+	bv.output.SetBackgroundColor(tcell.ColorBlack)
+	bv.output.SetBorder(true)
+	bv.output.SetBorderColor(tcell.NewRGBColor(0, 255, 255))
+	bv.output.SetTitle(" Output ")
+	fmt.Fprintf(bv.output, "[gray]Press Enter on a finished or skipped stack to see its output[white]")
+
+	// Help line
+	help := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignLeft)
+	help.SetBackgroundColor(tcell.ColorBlack)
+	fmt.Fprintf(help, "[yellow]<Enter>[white] Show Output  [yellow]<Tab>[white] Table/Output  [yellow]<Esc>[white] Back")
+
+	bv.SetDirection(tview.FlexRow).
+		AddItem(bv.table, 0, 1, true).
+		AddItem(bv.output, 0, 2, false).
+		AddItem(help, 1, 0, false)
+	bv.SetBackgroundColor(tcell.ColorBlack)
+
+	bv.render()
+	return bv
+}
+
+// GetTable returns the summary table
+func (bv *BatchView) GetTable() *tview.Table {
+	return bv.table
+}
+
+// GetOutput returns the output pane
+func (bv *BatchView) GetOutput() *tview.TextView {
+	return bv.output
+}
+
+// Update replaces the state of a row
+func (bv *BatchView) Update(i int, row BatchRow) {
+	if i < 0 || i >= len(bv.rows) {
+		return
+	}
+	bv.rows[i] = row
+	bv.render()
+	if bv.shown == i {
+		bv.ShowOutput(i)
+	}
+}
+
+// ShowOutput displays the captured output of a row
+func (bv *BatchView) ShowOutput(i int) {
+	if i < 0 || i >= len(bv.rows) {
+		return
+	}
+	bv.shown = i
+	row := bv.rows[i]
+
+	bv.output.Clear()
+	bv.output.SetTitle(fmt.Sprintf(" Output: %s ", row.Stack))
+	switch row.State {
+	case BatchQueued:
+		fmt.Fprintf(bv.output, "[gray]Waiting for a free slot...[white]")
+		return
+	case BatchRunning:
+		fmt.Fprintf(bv.output, "[yellow]Running, the output is shown once the stack finished...[white]")
+		return
+	}
+	w := tview.ANSIWriter(bv.output)
+	w.Write([]byte(strings.Join(row.Output, "\n") + "\n"))
+	bv.output.ScrollToEnd()
+}
+
+// render fills the summary table, keeping the selection
+func (bv *BatchView) render() {
+	sel, _ := bv.table.GetSelection()
+	bv.table.Clear()
+
+	counts := make(map[string]int)
+	for _, r := range bv.rows {
+		counts[r.State]++
+	}
+	title := fmt.Sprintf(" 🚀 Batch %s: %d ok, %d failed, %d running, %d queued ",
+		bv.action, counts[BatchOK], counts[BatchFailed], counts[BatchRunning], counts[BatchQueued])
+	if counts[BatchSkipped] > 0 {
+		title += fmt.Sprintf("(%d skipped) ", counts[BatchSkipped])
+	}
+	bv.table.SetTitle(title)
+
+	headers := []string{"#", "STACK", "STATUS", "CHANGES", "DURATION"}
+	for col, h := range headers {
+		bv.table.SetCell(0, col, tview.NewTableCell(h).
+			SetTextColor(tcell.NewRGBColor(255, 215, 0)).
+			SetAttributes(tcell.AttrBold).
+			SetSelectable(false))
+	}
+
+	for i, r := range bv.rows {
+		status, color := "… queued", tcell.ColorGray
+		switch r.State {
+		case BatchRunning:
+			status, color = "⟳ running", tcell.ColorYellow
+		case BatchOK:
+			status, color = "✅ ok", tcell.NewRGBColor(100, 255, 100)
+		case BatchFailed:
+			status, color = "❌ failed", tcell.ColorRed
+		case BatchSkipped:
+			status, color = "⊘ skipped", tcell.NewRGBColor(255, 165, 0)
+		}
+
+		changes, changeColor := r.Changes, tcell.ColorWhite
+		if changes == "" {
+			changes = "-"
+		} else if changes != "no changes" {
+			changeColor = tcell.ColorYellow
+		}
+
+		duration := "-"
+		if r.State == BatchOK || r.State == BatchFailed {
+			duration = r.Duration.Round(time.Second).String()
+		}
+
+		bv.table.SetCell(i+1, 0, tview.NewTableCell(fmt.Sprintf("%d", i+1)).SetTextColor(tcell.ColorGray))
+		bv.table.SetCell(i+1, 1, tview.NewTableCell(tview.Escape(r.Stack)).SetTextColor(tcell.NewRGBColor(100, 200, 255)).SetExpansion(1))
+		bv.table.SetCell(i+1, 2, tview.NewTableCell(status).SetTextColor(color))
+		bv.table.SetCell(i+1, 3, tview.NewTableCell(changes).SetTextColor(changeColor))
+		bv.table.SetCell(i+1, 4, tview.NewTableCell(duration).SetTextColor(tcell.ColorWhite))
+	}
+
+	if sel < 1 {
+		sel = 1
+	}
+	if sel > len(bv.rows) {
+		sel = len(bv.rows)
+	}
+	bv.table.Select(sel, 0)
+}
@@ -0,0 +1,402 @@
+package view
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// noopRunThreshold is the number of consecutive unchanged lines in a resource block
+// from which they are hidden
+const noopRunThreshold = 4
+
+var (
+	planBlockHeaderPattern = regexp.MustCompile(`^\s*# (\S+) (?:will be|must be|has been|has moved|has changed|is tainted)(.*)$`)
+	planBlockStartPattern  = regexp.MustCompile(`^\s*(?:[-+~<=/]+\s+)?(?:resource|data) "[^"]+" "[^"]+" \{$`)
+	planChangePattern      = regexp.MustCompile(`^\s*(?:[-+~]|<=|-/\+|\+/-)\s`)
+	heredocStartPattern    = regexp.MustCompile(`<<-?([A-Za-z_][A-Za-z0-9_]*)$`)
+)
+
+// planBlock is a `# aws_x.y will be updated in-place { ... }` block of plan or apply output,
+// as line indexes into the text
+type planBlock struct {
+	header  int // the "# address will be ..." line
+	start   int // the `resource "type" "name" {` line
+	end     int // the closing brace
+	address string
+	action  string // create, delete, update, replace, read, move, import or drift
+	folded  bool
+}
+
+// contentFold holds the folding state of plan or apply output in the content view
+type contentFold struct {
+	original string   // text with style tags as it was before folding
+	lines    []string // lines of original
+	plain    []string // lines of original without style tags
+	rendered string   // text set with the folds applied
+	blocks   []*planBlock
+	headers  []int // line of each block header in rendered, for jumping
+	current  int   // block jumped to last, -1 if none
+	showNoop bool  // show long runs of unchanged attributes
+}
+
+// FoldPlan finds the resource blocks of the plan or apply output shown in the view and
+// hides long runs of unchanged attributes in them. Blocks start expanded. It returns the
+// number of resource blocks, 0 if the content is not plan output.
+func (cv *ContentView) FoldPlan() int {
+	if cv.fold != nil {
+		cv.syncFolded()
+		return len(cv.fold.blocks)
+	}
+	cv.ClearSearch()
+	f := &contentFold{current: -1}
+	f.snapshot(cv.TextView.GetText(false))
+	if len(f.blocks) == 0 {
+		return 0
+	}
+	cv.fold = f
+	cv.renderFold()
+	return len(f.blocks)
+}
+
+// Folding reports whether plan output is shown with folding
+func (cv *ContentView) Folding() bool {
+	return cv.fold != nil
+}
+
+// FoldAll collapses every resource block, or expands them all if they are all collapsed
+func (cv *ContentView) FoldAll() {
+	if !cv.prepareFold() {
+		return
+	}
+	all := true
+	for _, b := range cv.fold.blocks {
+		all = all && b.folded
+	}
+	for _, b := range cv.fold.blocks {
+		b.folded = !all
+	}
+	cv.renderFold()
+}
+
+// FoldExceptDestructive expands only the replacements and deletions
+func (cv *ContentView) FoldExceptDestructive() {
+	if !cv.prepareFold() {
+		return
+	}
+	for _, b := range cv.fold.blocks {
+		b.folded = b.action != "replace" && b.action != "delete"
+	}
+	cv.renderFold()
+}
+
+// ToggleFold collapses or expands the block jumped to last, or the first block on screen
+func (cv *ContentView) ToggleFold() {
+	if !cv.prepareFold() {
+		return
+	}
+	f := cv.fold
+	f.current = cv.visibleBlock()
+	if f.current >= len(f.blocks) {
+		f.current = len(f.blocks) - 1
+	}
+	f.blocks[f.current].folded = !f.blocks[f.current].folded
+	cv.renderFold()
+}
+
+// ToggleUnchanged shows or hides long runs of unchanged attributes
+func (cv *ContentView) ToggleUnchanged() bool {
+	if !cv.prepareFold() {
+		return false
+	}
+	cv.fold.showNoop = !cv.fold.showNoop
+	cv.renderFold()
+	return cv.fold.showNoop
+}
+
+// NextBlock jumps to the next resource block, wrapping around
+func (cv *ContentView) NextBlock() {
+	cv.moveBlock(1)
+}
+
+// PrevBlock jumps to the previous resource block, wrapping around
+func (cv *ContentView) PrevBlock() {
+	cv.moveBlock(-1)
+}
+
+// FoldStatus describes the block jumped to last and the number of folded blocks
+func (cv *ContentView) FoldStatus() string {
+	f := cv.fold
+	if f == nil {
+		return ""
+	}
+	folded := 0
+	for _, b := range f.blocks {
+		if b.folded {
+			folded++
+		}
+	}
+	status := fmt.Sprintf("%d resource blocks, %d folded", len(f.blocks), folded)
+	if f.current >= 0 {
+		b := f.blocks[f.current]
+		status = fmt.Sprintf("%d/%d %s (%s), ", f.current+1, len(f.blocks), b.address, b.action) + status
+	}
+	return status
+}
+
+// prepareFold starts folding if needed, reporting whether there are blocks to fold
+func (cv *ContentView) prepareFold() bool {
+	if cv.FoldPlan() == 0 {
+		return false
+	}
+	cv.ClearSearch()
+	return true
+}
+
+// moveBlock moves the current block by delta and scrolls its header to the top
+func (cv *ContentView) moveBlock(delta int) {
+	if !cv.prepareFold() {
+		return
+	}
+	f := cv.fold
+	if current := cv.visibleBlock(); current != f.current {
+		// Start from the blocks around the top of the view
+		f.current = current
+		if delta > 0 {
+			f.current--
+		}
+	}
+	f.current = (f.current + delta + len(f.blocks)) % len(f.blocks)
+	cv.renderFold()
+
+	row := cv.lineRows()[f.headers[f.current]] - 1
+	if row < 0 {
+		row = 0
+	}
+	cv.ScrollTo(row, 0)
+}
+
+// visibleBlock returns the block jumped to last if its header is on screen, otherwise the
+// first block whose header is at or below the top of the view, len(blocks) if there is none
+func (cv *ContentView) visibleBlock() int {
+	f := cv.fold
+	top, _ := cv.GetScrollOffset()
+	_, _, _, height := cv.GetInnerRect()
+	rows := cv.lineRows()
+	if f.current >= 0 {
+		if row := rows[f.headers[f.current]]; row >= top && row < top+height {
+			return f.current
+		}
+	}
+	for i, line := range f.headers {
+		if rows[line] >= top {
+			return i
+		}
+	}
+	return len(f.blocks)
+}
+
+// snapshot stores the text to fold and finds its resource blocks
+func (f *contentFold) snapshot(text string) {
+	folded := make(map[string]bool)
+	for _, b := range f.blocks {
+		folded[b.address] = b.folded
+	}
+
+	f.original = text
+	f.lines = strings.Split(text, "\n")
+	f.plain = strings.Split(stripTags(text), "\n")
+	f.rendered = text
+	f.blocks = findPlanBlocks(f.plain)
+	for _, b := range f.blocks {
+		b.folded = folded[b.address]
+	}
+	if f.current >= len(f.blocks) {
+		f.current = -1
+	}
+}
+
+// syncFolded folds output written since the text was folded into the folded text
+func (cv *ContentView) syncFolded() {
+	f := cv.fold
+	text := cv.TextView.GetText(false)
+	if len(text) <= len(f.rendered) || !strings.HasPrefix(text, f.rendered) {
+		return
+	}
+	f.snapshot(f.original + text[len(f.rendered):])
+	cv.renderFold()
+}
+
+// renderFold sets the text with folded blocks and unchanged attributes hidden
+func (cv *ContentView) renderFold() {
+	f := cv.fold
+	var out []string
+	f.headers = f.headers[:0]
+
+	next := 0
+	for _, b := range f.blocks {
+		out = append(out, f.lines[next:b.header]...)
+
+		f.headers = append(f.headers, len(out))
+		header := f.lines[b.header]
+		if len(f.headers)-1 == f.current {
+			header = "[#00ffff]▶[-]" + strings.TrimPrefix(header, " ")
+		}
+		out = append(out, header)
+		out = append(out, f.lines[b.header+1:b.start]...)
+
+		if b.folded {
+			out = append(out, fmt.Sprintf("%s [gray]… %s }[-]", f.lines[b.start], countLines(b.end-b.start-1)))
+		} else {
+			out = append(out, f.lines[b.start])
+			out = append(out, f.body(b)...)
+			out = append(out, f.lines[b.end])
+		}
+		next = b.end + 1
+	}
+	out = append(out, f.lines[next:]...)
+
+	row, col := cv.GetScrollOffset()
+	f.rendered = strings.Join(out, "\n")
+	cv.TextView.SetText(f.rendered)
+	cv.ScrollTo(row, col)
+}
+
+// body returns the lines inside a block, with long runs of unchanged lines replaced by a note.
+// The lines opening and closing a heredoc are always shown.
+func (f *contentFold) body(b *planBlock) []string {
+	lines := f.lines[b.start+1 : b.end]
+	if f.showNoop {
+		return lines
+	}
+
+	var out []string
+	run := 0
+	flush := func(i int) {
+		if run >= noopRunThreshold {
+			plain := f.plain[b.start+1+i-run]
+			indent := plain[:len(plain)-len(strings.TrimLeft(plain, " "))]
+			out = append(out, fmt.Sprintf("%s[gray]… %d unchanged lines hidden[-]", indent, run))
+		} else {
+			out = append(out, lines[i-run:i]...)
+		}
+		run = 0
+	}
+	heredoc := "" // delimiter of the heredoc being read
+	for i := range lines {
+		plain := f.plain[b.start+1+i]
+		unchanged := isUnchangedLine(plain)
+		if heredoc == "" {
+			if m := heredocStartPattern.FindStringSubmatch(strings.TrimSpace(plain)); m != nil {
+				heredoc = m[1]
+				unchanged = false
+			}
+		} else if strings.TrimSpace(plain) == heredoc {
+			heredoc = ""
+			unchanged = false
+		}
+		if unchanged {
+			run++
+			continue
+		}
+		flush(i)
+		out = append(out, lines[i])
+	}
+	flush(len(lines))
+	return out
+}
+
+// isUnchangedLine reports whether a line of a resource block shows an attribute that does not change.
+// Lines opening or closing a nested block, map or list are kept so that the structure stays visible.
+func isUnchangedLine(plain string) bool {
+	trimmed := strings.TrimSpace(plain)
+	switch {
+	case trimmed == "":
+		return false
+	case strings.HasPrefix(trimmed, "#"):
+		// Terraform's own notes, e.g. "# (3 unchanged attributes hidden)"
+		return false
+	case strings.Trim(trimmed, "}]),") == "":
+		return false
+	case strings.HasSuffix(trimmed, "{"), strings.HasSuffix(trimmed, "["), strings.HasSuffix(trimmed, "("):
+		return false
+	}
+	return !planChangePattern.MatchString(plain)
+}
+
+// findPlanBlocks finds the resource blocks in the plain lines of plan or apply output
+func findPlanBlocks(plain []string) []*planBlock {
+	var blocks []*planBlock
+	for i := 0; i < len(plain); i++ {
+		m := planBlockHeaderPattern.FindStringSubmatch(plain[i])
+		if m == nil {
+			continue
+		}
+		b := &planBlock{header: i, address: m[1], action: planBlockAction(plain[i])}
+
+		// Explanations such as "# (because ...)" may follow the header
+		start := i + 1
+		for start < len(plain) && strings.HasPrefix(strings.TrimSpace(plain[start]), "# (") {
+			start++
+		}
+		if start >= len(plain) || !planBlockStartPattern.MatchString(plain[start]) {
+			continue
+		}
+		b.start = start
+
+		// The block ends where its braces balance
+		depth := 0
+		for j := start; j < len(plain); j++ {
+			trimmed := strings.TrimSpace(plain[j])
+			if j > start && planBlockHeaderPattern.MatchString(plain[j]) {
+				break
+			}
+			if strings.HasPrefix(trimmed, "}") || strings.HasPrefix(trimmed, "]") || strings.HasPrefix(trimmed, ")") {
+				depth--
+			}
+			if strings.HasSuffix(trimmed, "{") || strings.HasSuffix(trimmed, "[") || strings.HasSuffix(trimmed, "(") {
+				depth++
+			}
+			if depth == 0 {
+				b.end = j
+				break
+			}
+		}
+		if b.end == 0 {
+			continue
+		}
+		blocks = append(blocks, b)
+		i = b.end
+	}
+	return blocks
+}
+
+// planBlockAction classifies a block header by the change it announces
+func planBlockAction(header string) string {
+	switch {
+	case strings.Contains(header, "will be created"):
+		return "create"
+	case strings.Contains(header, "will be destroyed"):
+		return "delete"
+	case strings.Contains(header, "must be replaced"), strings.Contains(header, "will be replaced"):
+		return "replace"
+	case strings.Contains(header, "will be updated in-place"):
+		return "update"
+	case strings.Contains(header, "will be read during apply"):
+		return "read"
+	case strings.Contains(header, "has moved to"):
+		return "move"
+	case strings.Contains(header, "will be imported"):
+		return "import"
+	default:
+		return "drift"
+	}
+}
+
+// countLines formats a number of lines, e.g. "1 line" or "12 lines"
+func countLines(n int) string {
+	if n == 1 {
+		return "1 line"
+	}
+	return fmt.Sprintf("%d lines", n)
+}
@@ -0,0 +1,314 @@
+package view
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func readSample(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestFindPlanBlocks(t *testing.T) {
+	type block struct {
+		address            string
+		action             string
+		header, start, end int
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want []block
+	}{
+		{
+			name: "plan with nested blocks, heredoc and replacement",
+			text: readSample(t, "plan.txt"),
+			want: []block{
+				{"data.aws_iam_policy_document.assume", "read", 15, 17, 33},
+				{"aws_instance.web", "replace", 35, 36, 69},
+				{"aws_security_group.web", "update", 71, 72, 95},
+				{"aws_s3_bucket.logs", "create", 97, 98, 103},
+				{"aws_iam_role.legacy", "delete", 105, 107, 110},
+			},
+		},
+		{
+			name: "apply with create before destroy replacement",
+			text: readSample(t, "apply.txt"),
+			want: []block{
+				{"aws_s3_bucket.logs", "update", 9, 10, 16},
+				{"aws_launch_template.web", "replace", 18, 19, 23},
+			},
+		},
+		{
+			name: "moved resource",
+			text: `  # aws_instance.a has moved to aws_instance.b
+    resource "aws_instance" "b" {
+        id   = "i-0123"
+        tags = {}
+    }`,
+			want: []block{
+				{"aws_instance.a", "move", 0, 1, 4},
+			},
+		},
+		{
+			name: "unterminated block at the end",
+			text: `  # aws_s3_bucket.logs will be created
+  + resource "aws_s3_bucket" "logs" {
+      + bucket = "acme-logs"
+    }
+
+  # aws_instance.web will be updated in-place
+  ~ resource "aws_instance" "web" {
+      ~ tags = {
+          + "Team" = "platform"`,
+			want: []block{
+				{"aws_s3_bucket.logs", "create", 0, 1, 3},
+			},
+		},
+		{
+			name: "unterminated block followed by another block",
+			text: `  # aws_instance.web will be updated in-place
+  ~ resource "aws_instance" "web" {
+      ~ instance_type = "t3.micro" -> "t3.small"
+  # aws_s3_bucket.logs will be created
+  + resource "aws_s3_bucket" "logs" {
+      + bucket = "acme-logs"
+    }`,
+			want: []block{
+				{"aws_s3_bucket.logs", "create", 3, 4, 6},
+			},
+		},
+		{
+			name: "header without a resource block",
+			text: `  # aws_instance.web will be created
+Error: Invalid reference`,
+		},
+		{
+			name: "not plan output",
+			text: "Success! The configuration is valid.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []block
+			for _, b := range findPlanBlocks(strings.Split(tt.text, "\n")) {
+				got = append(got, block{b.address, b.action, b.header, b.start, b.end})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findPlanBlocks() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFoldBody(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		showNoop bool
+		want     []string
+	}{
+		{
+			name: "long run hidden, short run kept",
+			text: `  # aws_instance.web will be updated in-place
+  ~ resource "aws_instance" "web" {
+        ami                         = "ami-0c55b159cbfafe1f0"
+        availability_zone           = "eu-west-1a"
+        ebs_optimized               = false
+        get_password_data           = false
+      ~ instance_type               = "t3.micro" -> "t3.small"
+        monitoring                  = false
+        tenancy                     = "default"
+        # (20 unchanged attributes hidden)
+    }`,
+			want: []string{
+				"        [gray]… 4 unchanged lines hidden[-]",
+				`      ~ instance_type               = "t3.micro" -> "t3.small"`,
+				"        monitoring                  = false",
+				`        tenancy                     = "default"`,
+				"        # (20 unchanged attributes hidden)",
+			},
+		},
+		{
+			name: "unchanged lines shown on request",
+			text: `  # aws_instance.web will be updated in-place
+  ~ resource "aws_instance" "web" {
+        ami                         = "ami-0c55b159cbfafe1f0"
+        availability_zone           = "eu-west-1a"
+        ebs_optimized               = false
+        get_password_data           = false
+      ~ instance_type               = "t3.micro" -> "t3.small"
+    }`,
+			showNoop: true,
+			want: []string{
+				`        ami                         = "ami-0c55b159cbfafe1f0"`,
+				`        availability_zone           = "eu-west-1a"`,
+				"        ebs_optimized               = false",
+				"        get_password_data           = false",
+				`      ~ instance_type               = "t3.micro" -> "t3.small"`,
+			},
+		},
+		{
+			name: "nested list and object keep their brackets",
+			text: `  # aws_security_group.web will be updated in-place
+  ~ resource "aws_security_group" "web" {
+        description = "web"
+        egress      = []
+        id          = "sg-0a1b2c3d"
+        name        = "web"
+      ~ ingress     = [
+          - {
+              - from_port = 22
+                protocol  = "tcp"
+                self      = false
+                to_port   = 22
+                ipv6      = []
+            },
+        ]
+    }`,
+			want: []string{
+				"        [gray]… 4 unchanged lines hidden[-]",
+				"      ~ ingress     = [",
+				"          - {",
+				"              - from_port = 22",
+				"                [gray]… 4 unchanged lines hidden[-]",
+				"            },",
+				"        ]",
+			},
+		},
+		{
+			name: "unchanged map opener is kept",
+			text: `  # aws_instance.web will be updated in-place
+  ~ resource "aws_instance" "web" {
+      ~ instance_type = "t3.micro" -> "t3.small"
+        tags          = {
+            "Env"   = "prod"
+            "Name"  = "web"
+            "Owner" = "platform"
+            "Team"  = "sre"
+        }
+    }`,
+			want: []string{
+				`      ~ instance_type = "t3.micro" -> "t3.small"`,
+				"        tags          = {",
+				"            [gray]… 4 unchanged lines hidden[-]",
+				"        }",
+			},
+		},
+		{
+			name: "changed heredoc",
+			text: `  # aws_instance.web will be updated in-place
+  ~ resource "aws_instance" "web" {
+      ~ user_data = <<-EOT
+            #!/bin/bash
+            set -euo pipefail
+            yum install -y nginx
+            systemctl enable nginx
+            systemctl start nginx
+          - echo "v1" > /etc/motd
+          + echo "v2" > /etc/motd
+        EOT
+        ami       = "ami-0c55b159cbfafe1f0"
+    }`,
+			want: []string{
+				"      ~ user_data = <<-EOT",
+				"            #!/bin/bash",
+				"            [gray]… 4 unchanged lines hidden[-]",
+				`          - echo "v1" > /etc/motd`,
+				`          + echo "v2" > /etc/motd`,
+				"        EOT",
+				`        ami       = "ami-0c55b159cbfafe1f0"`,
+			},
+		},
+		{
+			name: "unchanged heredoc keeps its delimiters",
+			text: `  # aws_instance.web will be updated in-place
+  ~ resource "aws_instance" "web" {
+      ~ instance_type = "t3.micro" -> "t3.small"
+        user_data     = <<EOT
+            line 1
+            line 2
+            line 3
+            line 4
+        EOT
+        ami           = "ami-0c55b159cbfafe1f0"
+    }`,
+			want: []string{
+				`      ~ instance_type = "t3.micro" -> "t3.small"`,
+				"        user_data     = <<EOT",
+				"            [gray]… 4 unchanged lines hidden[-]",
+				"        EOT",
+				`        ami           = "ami-0c55b159cbfafe1f0"`,
+			},
+		},
+		{
+			name: "style tags are kept on shown lines",
+			text: `  [::b]# aws_instance.web[::-] will be updated in-place
+  [yellow]~[-] resource "aws_instance" "web" {
+        ami               = "ami-0c55b159cbfafe1f0"
+        availability_zone = "eu-west-1a"
+        ebs_optimized     = false
+        monitoring        = false
+      [yellow]~[-] instance_type     = "t3.micro" [yellow]->[-] "t3.small"
+    }`,
+			want: []string{
+				"        [gray]… 4 unchanged lines hidden[-]",
+				`      [yellow]~[-] instance_type     = "t3.micro" [yellow]->[-] "t3.small"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &contentFold{current: -1, showNoop: tt.showNoop}
+			f.snapshot(tt.text)
+			if len(f.blocks) != 1 {
+				t.Fatalf("found %d blocks, want 1", len(f.blocks))
+			}
+			if got := f.body(f.blocks[0]); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("body() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestFoldPlanSample(t *testing.T) {
+	f := &contentFold{current: -1}
+	f.snapshot(readSample(t, "plan.txt"))
+
+	var notes []string
+	for _, b := range f.blocks {
+		body := f.body(b)
+		for _, line := range body {
+			if strings.Contains(line, "unchanged lines hidden") {
+				notes = append(notes, b.address+":"+strings.TrimSpace(line))
+			}
+		}
+		if b.address == "aws_instance.web" && !containsLine(body, "        EOT") {
+			t.Errorf("heredoc delimiter of %s hidden", b.address)
+		}
+	}
+	want := []string{"aws_instance.web:[gray]… 6 unchanged lines hidden[-]"}
+	if !reflect.DeepEqual(notes, want) {
+		t.Errorf("hidden runs = %q, want %q", notes, want)
+	}
+}
+
+func containsLine(lines []string, line string) bool {
+	for _, l := range lines {
+		if l == line {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,233 @@
+package view
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// searchMatch is a match of the content search, as byte offsets into a line of plain text
+type searchMatch struct {
+	line, start, end int
+}
+
+// contentSearch holds the state of an incremental search over the content
+type contentSearch struct {
+	original string   // text with style tags as it was before highlighting
+	lines    []string // lines of original
+	plain    []string // lines of original without style tags
+	rendered string   // text set with highlighted matches
+	matches  []searchMatch
+	current  int
+}
+
+// Search highlights the matches of query over the plain text of the content and scrolls to
+// the first match at or below the top of the view. The text keeps its colors except on
+// lines with a match. It returns the number of matches, or an error for an invalid regex.
+func (cv *ContentView) Search(query string, caseSensitive, regex bool) (int, error) {
+	if cv.search == nil {
+		cv.search = &contentSearch{}
+		cv.snapshot(cv.TextView.GetText(false))
+	} else {
+		cv.syncAppended()
+	}
+	s := cv.search
+	s.matches = nil
+	s.current = 0
+
+	if query == "" {
+		cv.render()
+		return 0, nil
+	}
+
+	pattern := query
+	if !regex {
+		pattern = regexp.QuoteMeta(query)
+	}
+	if !caseSensitive {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		cv.render()
+		return 0, err
+	}
+
+	for i, line := range s.plain {
+		for _, loc := range re.FindAllStringIndex(line, -1) {
+			if loc[1] > loc[0] {
+				s.matches = append(s.matches, searchMatch{line: i, start: loc[0], end: loc[1]})
+			}
+		}
+	}
+
+	// Start from the first match visible on screen, like an incremental search in a pager
+	top, _ := cv.GetScrollOffset()
+	rows := cv.lineRows()
+	for i, m := range s.matches {
+		if rows[m.line] >= top {
+			s.current = i
+			break
+		}
+	}
+	cv.render()
+	cv.scrollToCurrent()
+	return len(s.matches), nil
+}
+
+// NextMatch moves to the next match, wrapping around
+func (cv *ContentView) NextMatch() {
+	cv.moveMatch(1)
+}
+
+// PrevMatch moves to the previous match, wrapping around
+func (cv *ContentView) PrevMatch() {
+	cv.moveMatch(-1)
+}
+
+// SearchStatus returns the 1-based index of the current match and the number of matches
+func (cv *ContentView) SearchStatus() (current, total int) {
+	if cv.search == nil || len(cv.search.matches) == 0 {
+		return 0, 0
+	}
+	return cv.search.current + 1, len(cv.search.matches)
+}
+
+// Searching reports whether matches are highlighted
+func (cv *ContentView) Searching() bool {
+	return cv.search != nil
+}
+
+// ClearSearch removes the highlights and restores the original text, keeping any output
+// appended during the search
+func (cv *ContentView) ClearSearch() {
+	if cv.search == nil {
+		return
+	}
+	cv.syncAppended()
+	row, col := cv.GetScrollOffset()
+	original := cv.search.original
+	cv.search = nil
+	cv.TextView.SetText(original)
+	cv.ScrollTo(row, col)
+}
+
+// moveMatch moves the current match by delta
+func (cv *ContentView) moveMatch(delta int) {
+	if cv.search == nil || len(cv.search.matches) == 0 {
+		return
+	}
+	cv.syncAppended()
+	s := cv.search
+	s.current = (s.current + delta + len(s.matches)) % len(s.matches)
+	cv.render()
+	cv.scrollToCurrent()
+}
+
+// snapshot stores the text the search runs over
+func (cv *ContentView) snapshot(text string) {
+	s := cv.search
+	s.original = text
+	s.lines = strings.Split(text, "\n")
+	s.plain = strings.Split(stripTags(text), "\n")
+	s.rendered = text
+}
+
+// syncAppended folds output written while searching into the searched text. Lines
+// appended after the highlighted text are kept as they were written.
+func (cv *ContentView) syncAppended() {
+	s := cv.search
+	text := cv.TextView.GetText(false)
+	if len(text) <= len(s.rendered) || !strings.HasPrefix(text, s.rendered) {
+		return
+	}
+	matches, current := s.matches, s.current
+	cv.snapshot(s.original + text[len(s.rendered):])
+	s.rendered = text
+	s.matches, s.current = matches, current
+}
+
+// render sets the text with the matches highlighted, the current one in a stronger color
+func (cv *ContentView) render() {
+	s := cv.search
+	byLine := make(map[int][]int)
+	for i, m := range s.matches {
+		byLine[m.line] = append(byLine[m.line], i)
+	}
+
+	lines := make([]string, len(s.lines))
+	copy(lines, s.lines)
+	for line, indexes := range byLine {
+		plain := s.plain[line]
+		var b strings.Builder
+		pos := 0
+		for _, i := range indexes {
+			m := s.matches[i]
+			b.WriteString(tview.Escape(plain[pos:m.start]))
+			if i == s.current {
+				b.WriteString("[black:orange:b]")
+			} else {
+				b.WriteString("[black:yellow]")
+			}
+			b.WriteString(tview.Escape(plain[m.start:m.end]))
+			b.WriteString("[-:-:-]")
+			pos = m.end
+		}
+		b.WriteString(tview.Escape(plain[pos:]))
+		lines[line] = b.String()
+	}
+
+	row, col := cv.GetScrollOffset()
+	s.rendered = strings.Join(lines, "\n")
+	cv.TextView.SetText(s.rendered)
+	cv.ScrollTo(row, col)
+}
+
+// scrollToCurrent scrolls the current match into the upper third of the view
+func (cv *ContentView) scrollToCurrent() {
+	s := cv.search
+	if len(s.matches) == 0 {
+		return
+	}
+	_, _, _, height := cv.GetInnerRect()
+	row := cv.lineRows()[s.matches[s.current].line] - height/3
+	if row < 0 {
+		row = 0
+	}
+	cv.ScrollTo(row, 0)
+}
+
+// lineRows returns the screen row at which each line of text starts, accounting for
+// wrapped lines
+func (cv *ContentView) lineRows() []int {
+	_, _, width, _ := cv.GetInnerRect()
+	lines := strings.Split(cv.TextView.GetText(false), "\n")
+	rows := make([]int, len(lines))
+	row := 0
+	for i, line := range lines {
+		rows[i] = row
+		row++
+		if width <= 0 {
+			continue
+		}
+		switch cv.wrap {
+		case WrapSoft:
+			if wrapped := len(tview.WordWrap(line, width)); wrapped > 1 {
+				row += wrapped - 1
+			}
+		case WrapHard:
+			if w := tview.TaggedStringWidth(line); w > width {
+				row += (w - 1) / width
+			}
+		}
+	}
+	return rows
+}
+
+// stripTags removes style tags the way the text view does when drawing
+func stripTags(text string) string {
+	tv := tview.NewTextView().SetDynamicColors(true)
+	tv.SetText(text)
+	return tv.GetText(true)
+}
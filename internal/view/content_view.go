@@ -7,14 +7,43 @@ import (
 	"strings"
 
 	"github.com/gdamore/tcell/v2"
+	"github.com/idongju/t9s/internal/config"
 	"github.com/rivo/tview"
 )
 
 // ContentView represents the content display area
 type ContentView struct {
 	*tview.TextView
+	file   string         // file currently displayed, empty for any other content
+	wrap   WrapMode       // how long lines are wrapped
+	search *contentSearch // active search, nil when matches are not highlighted
+	fold   *contentFold   // folding of plan output, nil when the content is not folded
+	keymap *config.Keymap // keys shown on the welcome screen and used for fast scrolling
 }
 
+// WrapMode is how the content view wraps lines longer than its width
+type WrapMode int
+
+const (
+	WrapSoft WrapMode = iota // wrap at word boundaries
+	WrapHard                 // wrap at the exact column
+	WrapOff                  // no wrapping, scroll horizontally
+)
+
+func (w WrapMode) String() string {
+	switch w {
+	case WrapHard:
+		return "hard wrap"
+	case WrapOff:
+		return "no wrap"
+	default:
+		return "soft wrap"
+	}
+}
+
+// fileHeaderLines is the number of lines DisplayFile prints before the file content
+const fileHeaderLines = 3
+
 // NewContentView creates a new content view
 func NewContentView() *ContentView {
 	cv := &ContentView{
@@ -22,6 +51,8 @@ func NewContentView() *ContentView {
 			SetDynamicColors(true).
 			SetScrollable(true).
 			SetWordWrap(true),
+		wrap:   WrapSoft,
+		keymap: config.DefaultKeymap(),
 	}
 
 	cv.SetBackgroundColor(tcell.ColorBlack)
@@ -67,19 +98,18 @@ func NewContentView() *ContentView {
 		case tcell.KeyEnd:
 			cv.ScrollToEnd()
 			return nil
-		case tcell.KeyRune:
-			switch event.Rune() {
-			case 'u', 'U': // Fast scroll up
-				newRow := row - 10
-				if newRow < 0 {
-					newRow = 0
-				}
-				cv.ScrollTo(newRow, col)
-				return nil
-			case 'd', 'D': // Fast scroll down
-				cv.ScrollTo(row+10, col)
-				return nil
+		}
+		switch cv.keymap.Lookup(KeyName(event), config.ScopeContent) {
+		case config.ActionScrollUp: // Fast scroll up
+			newRow := row - 10
+			if newRow < 0 {
+				newRow = 0
 			}
+			cv.ScrollTo(newRow, col)
+			return nil
+		case config.ActionScrollDown: // Fast scroll down
+			cv.ScrollTo(row+10, col)
+			return nil
 		}
 		return event
 	})
@@ -89,6 +119,46 @@ func NewContentView() *ContentView {
 	return cv
 }
 
+// welcomeCommands are the commands listed on the welcome screen with the actions whose keys run them
+var welcomeCommands = []struct {
+	actions     []string
+	description string
+}{
+	{[]string{config.ActionInit}, "Terraform init (with confirmation)"},
+	{[]string{config.ActionPlan}, "Terraform plan (with confirmation)"},
+	{[]string{config.ActionApply}, "Terraform apply (with confirmation)"},
+	{[]string{config.ActionDestroy}, "Terraform destroy (with confirmation, tree focused)"},
+	{[]string{config.ActionRefresh}, "Refresh-only plan (show drift) or apply (accept drift into state)"},
+	{[]string{config.ActionHistory}, "View terraform history"},
+	{[]string{config.ActionJobs}, "Open the output of finished background jobs"},
+	{[]string{config.ActionProgress}, "Live resource progress of the last apply with -json"},
+	{[]string{config.ActionDiagnostics}, "Errors and warnings of the last run, Enter opens the editor at the line"},
+	{[]string{config.ActionEdit}, "Edit current file"},
+	{[]string{config.ActionDefinition}, "Jump to a var., local. or module. definition (content focused)"},
+	{[]string{config.ActionWrap}, "Cycle soft wrap, hard wrap and no wrap"},
+	{[]string{config.ActionFoldAll, config.ActionFoldDestructive, config.ActionPrevBlock, config.ActionNextBlock, config.ActionUnchanged},
+		"Fold plan output: all, destructive only, jump, unchanged attributes"},
+	{[]string{config.ActionSettings}, "Settings"},
+	{[]string{config.ActionContext}, "Switch context"},
+	{[]string{config.ActionWorkspaces}, "Manage workspaces"},
+	{[]string{config.ActionState}, "Browse state"},
+	{[]string{config.ActionOutputs}, "View outputs"},
+	{[]string{config.ActionMark, config.ActionBatch}, "Mark stacks / batch init, plan or validate"},
+	{[]string{config.ActionStacksOnly}, "Show only stacks and their tfvars"},
+	{[]string{config.ActionDashboard}, "Stack dashboard (dependency graph, batch plan/apply)"},
+	{[]string{config.ActionGraph}, "Resource dependency graph"},
+	{[]string{config.ActionHelp}, "Help"},
+	{[]string{config.ActionCommand}, "Command mode"},
+	{[]string{config.ActionFinder}, "Find stacks, files and history"},
+	{[]string{config.ActionHome}, "Show this screen"},
+	{[]string{config.ActionQuit}, "Quit"},
+}
+
+// SetKeymap sets the key bindings used for fast scrolling and shown on the welcome screen
+func (cv *ContentView) SetKeymap(km *config.Keymap) {
+	cv.keymap = km
+}
+
 // ShowWelcome displays the welcome message
 func (cv *ContentView) ShowWelcome() {
 	cv.Clear()
@@ -96,21 +166,45 @@ func (cv *ContentView) ShowWelcome() {
 	fmt.Fprintf(cv, "[yellow]Welcome to T9s![white]\n\n")
 	fmt.Fprintf(cv, "Select a file from the tree to view its content.\n\n")
 	fmt.Fprintf(cv, "[cyan]Available Commands:[white]\n")
-	fmt.Fprintf(cv, "  • [green]i[white] - Terraform init (with confirmation)\n")
-	fmt.Fprintf(cv, "  • [green]p[white] - Terraform plan (with confirmation)\n")
-	fmt.Fprintf(cv, "  • [green]a[white] - Terraform apply (with confirmation)\n")
-	fmt.Fprintf(cv, "  • [green]d[white] - Terraform destroy (with confirmation)\n")
-	fmt.Fprintf(cv, "  • [green]h[white] - View terraform history\n")
-	fmt.Fprintf(cv, "  • [green]e[white] - Edit current file\n")
-	fmt.Fprintf(cv, "  • [green]s[white] - Settings\n")
-	fmt.Fprintf(cv, "  • [green]?[white] or [green]Shift+H[white] - Help\n")
-	fmt.Fprintf(cv, "  • [green]/[white] - Command mode\n")
-	fmt.Fprintf(cv, "  • [green]Shift+C[white] - Show this screen\n")
-	fmt.Fprintf(cv, "  • [green]q[white] - Quit\n")
-}
-
-// DisplayFile displays the content of a file
+	for _, cmd := range welcomeCommands {
+		var keys []string
+		for _, action := range cmd.actions {
+			if label := cv.keymap.Label(action); label != "" {
+				keys = append(keys, "[green]"+tview.Escape(label)+"[white]")
+			}
+		}
+		if len(keys) == 0 {
+			continue
+		}
+		fmt.Fprintf(cv, "  • %s - %s\n", strings.Join(keys, " / "), cmd.description)
+	}
+}
+
+// DisplayFile displays the content of a file with line numbers, highlighting the syntax
+// of terraform, HCL, JSON and YAML files
 func (cv *ContentView) DisplayFile(path string) error {
+	return cv.displayFile(path, 0)
+}
+
+// GoToLine displays a file with a line marked and scrolled into view
+func (cv *ContentView) GoToLine(path string, line int) error {
+	if err := cv.displayFile(path, line); err != nil {
+		return err
+	}
+	_, _, _, height := cv.GetInnerRect()
+	rows := cv.lineRows()
+	if idx := fileHeaderLines + line - 1; idx >= 0 && idx < len(rows) {
+		row := rows[idx] - height/3
+		if row < 0 {
+			row = 0
+		}
+		cv.ScrollTo(row, 0)
+	}
+	return nil
+}
+
+// displayFile displays a file, marking the line number of mark unless it is 0
+func (cv *ContentView) displayFile(path string, mark int) error {
 	content, err := ioutil.ReadFile(path)
 	if err != nil {
 		cv.Clear()
@@ -119,19 +213,92 @@ func (cv *ContentView) DisplayFile(path string) error {
 	}
 
 	cv.Clear()
+	cv.file = path
 	cv.SetTitle(fmt.Sprintf(" 📄 %s ", filepath.Base(path)))
 	
 	fmt.Fprintf(cv, "[yellow]File:[white] %s\n", path)
 	fmt.Fprintf(cv, "[cyan]%s[white]\n\n", strings.Repeat("─", 60))
-	fmt.Fprintf(cv, "%s", string(content))
+
+	lines := Highlight(path, strings.TrimSuffix(string(content), "\n"))
+	width := len(fmt.Sprint(len(lines)))
+	var b strings.Builder
+	for i, line := range lines {
+		if i+1 == mark {
+			fmt.Fprintf(&b, "[black:yellow]%*d[-:-] [gray]│[-] %s\n", width, i+1, line)
+		} else {
+			fmt.Fprintf(&b, "[gray]%*d │[-] %s\n", width, i+1, line)
+		}
+	}
+	fmt.Fprint(cv, b.String())
 
 	if strings.HasSuffix(path, ".tfvars") || strings.HasSuffix(path, ".tf") {
-		fmt.Fprintf(cv, "\n\n[gray]Press 'e' to edit this file[white]")
+		fmt.Fprintf(cv, "\n[gray]Press %s to edit this file, %s to jump to a definition, %s to change wrapping (%s)[white]",
+			tview.Escape(cv.keymap.Label(config.ActionEdit)), tview.Escape(cv.keymap.Label(config.ActionDefinition)),
+			tview.Escape(cv.keymap.Label(config.ActionWrap)), cv.wrap)
 	}
 
 	return nil
 }
 
+// CycleWrap switches to the next wrap mode: soft, hard, off
+func (cv *ContentView) CycleWrap() WrapMode {
+	cv.wrap = (cv.wrap + 1) % 3
+	switch cv.wrap {
+	case WrapSoft:
+		cv.SetWrap(true).SetWordWrap(true)
+	case WrapHard:
+		cv.SetWrap(true).SetWordWrap(false)
+	case WrapOff:
+		cv.SetWrap(false)
+	}
+	return cv.wrap
+}
+
+// Clear clears the content and forgets the displayed file
+func (cv *ContentView) Clear() *tview.TextView {
+	cv.file = ""
+	cv.search = nil
+	cv.fold = nil
+	return cv.TextView.Clear()
+}
+
+// CurrentFile returns the file currently displayed, or an empty string
+func (cv *ContentView) CurrentFile() string {
+	return cv.file
+}
+
+// TopLine returns the line number of the displayed file at the top of the view, or 0
+func (cv *ContentView) TopLine() int {
+	if cv.file == "" {
+		return 0
+	}
+	top, _ := cv.GetScrollOffset()
+	line := 0
+	for i, row := range cv.lineRows() {
+		if row > top {
+			break
+		}
+		line = i - fileHeaderLines + 1
+	}
+	if line < 1 {
+		line = 1
+	}
+	return line
+}
+
+// ReloadFile redisplays the current file, keeping the scroll position
+func (cv *ContentView) ReloadFile() error {
+	if cv.file == "" {
+		return nil
+	}
+	row, col := cv.GetScrollOffset()
+	if err := cv.DisplayFile(cv.file); err != nil {
+		return err
+	}
+	cv.ScrollTo(row, col)
+	return nil
+}
+
 // DisplayText displays arbitrary text with a title
 func (cv *ContentView) DisplayText(title, content string) {
 	cv.Clear()
@@ -0,0 +1,179 @@
+package view
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/idongju/t9s/internal/config"
+	"github.com/idongju/t9s/internal/model"
+	"github.com/rivo/tview"
+)
+
+// DiagnosticsView lists the errors and warnings of a terraform run with their source locations
+type DiagnosticsView struct {
+	*tview.Flex
+	table  *tview.Table
+	detail *tview.TextView
+	root   string
+	diags  []*model.Diagnostic
+}
+
+// NewDiagnosticsView creates a new diagnostics view; root is used to shorten file paths
+// and km gives the keys of the help line
+func NewDiagnosticsView(title, root string, diags []*model.Diagnostic, km *config.Keymap) *DiagnosticsView {
+	// Errors first, otherwise in the order terraform reported them
+	sorted := make([]*model.Diagnostic, len(diags))
+	copy(sorted, diags)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].IsError() && !sorted[j].IsError()
+	})
+
+	dv := &DiagnosticsView{
+		Flex:  tview.NewFlex(),
+		root:  root,
+		diags: sorted,
+	}
+
+	errors := 0
+	for _, d := range diags {
+		if d.IsError() {
+			errors++
+		}
+	}
+
+	// Diagnostic table
+	dv.table = tview.NewTable().
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	dv.table.SetBackgroundColor(tcell.ColorBlack)
+	dv.table.SetBorder(true)
+	dv.table.SetBorderColor(tcell.NewRGBColor(255, 100, 100))
+	dv.table.SetTitle(fmt.Sprintf(" 🩺 Diagnostics: %s (%d errors, %d warnings) ", title, errors, len(diags)-errors))
+	dv.table.SetSelectedStyle(tcell.StyleDefault.
+		Background(tcell.NewRGBColor(0, 95, 135)).
+		Foreground(tcell.ColorWhite))
+	dv.table.SetSelectionChangedFunc(func(row, column int) {
+		dv.showDetail()
+	})
+
+	// Detail pane
+	dv.detail = tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetWrap(true)
+	dv.detail.SetBackgroundColor(tcell.ColorBlack)
+	dv.detail.SetBorder(true)
+	dv.detail.SetBorderColor(tcell.NewRGBColor(0, 255, 255))
+	dv.detail.SetTitle(" Detail ")
+
+	// Help line
+	help := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignLeft)
+	help.SetBackgroundColor(tcell.ColorBlack)
+	fmt.Fprint(help, helpLine(
+		"[yellow]<Enter>[white] Open in $EDITOR",
+		keyHint(km, config.ActionDiagnosticsView),
+		"[yellow]<Tab>[white] Table/Detail",
+		"[yellow]<Esc>[white] Back",
+	))
+
+	dv.SetDirection(tview.FlexRow).
+		AddItem(dv.table, 0, 1, true).
+		AddItem(dv.detail, 0, 1, false).
+		AddItem(help, 1, 0, false)
+	dv.SetBackgroundColor(tcell.ColorBlack)
+
+	dv.render()
+	return dv
+}
+
+// GetTable returns the diagnostic table
+func (dv *DiagnosticsView) GetTable() *tview.Table {
+	return dv.table
+}
+
+// GetDetail returns the detail pane
+func (dv *DiagnosticsView) GetDetail() *tview.TextView {
+	return dv.detail
+}
+
+// Selected returns the selected diagnostic, or nil
+func (dv *DiagnosticsView) Selected() *model.Diagnostic {
+	row, _ := dv.table.GetSelection()
+	if row < 1 || row > len(dv.diags) {
+		return nil
+	}
+	return dv.diags[row-1]
+}
+
+// render fills the table
+func (dv *DiagnosticsView) render() {
+	headers := []string{"SEVERITY", "SUMMARY", "LOCATION"}
+	for col, h := range headers {
+		dv.table.SetCell(0, col, tview.NewTableCell(h).
+			SetTextColor(tcell.NewRGBColor(255, 215, 0)).
+			SetSelectable(false).
+			SetAttributes(tcell.AttrBold))
+	}
+
+	for i, d := range dv.diags {
+		severity := "⚠ warning"
+		color := tcell.NewRGBColor(255, 215, 0)
+		if d.IsError() {
+			severity = "✗ error"
+			color = tcell.NewRGBColor(255, 100, 100)
+		}
+		dv.table.SetCell(i+1, 0, tview.NewTableCell(severity).SetTextColor(color))
+		dv.table.SetCell(i+1, 1, tview.NewTableCell(tview.Escape(d.Summary)).
+			SetTextColor(tcell.ColorWhite).
+			SetExpansion(1))
+		dv.table.SetCell(i+1, 2, tview.NewTableCell(tview.Escape(dv.location(d))).
+			SetTextColor(tcell.NewRGBColor(100, 200, 255)))
+	}
+
+	if len(dv.diags) > 0 {
+		dv.table.Select(1, 0)
+	}
+	dv.showDetail()
+}
+
+// showDetail shows the detail of the selected diagnostic
+func (dv *DiagnosticsView) showDetail() {
+	dv.detail.Clear()
+	d := dv.Selected()
+	if d == nil {
+		fmt.Fprintf(dv.detail, "[gray]No diagnostics[white]")
+		return
+	}
+
+	fmt.Fprintf(dv.detail, "[::b]%s[::-]\n\n", tview.Escape(d.Summary))
+	if d.File != "" {
+		fmt.Fprintf(dv.detail, "[cyan]Location:[white] %s\n", tview.Escape(dv.location(d)))
+	}
+	if d.Address != "" {
+		fmt.Fprintf(dv.detail, "[cyan]Resource:[white] %s\n", tview.Escape(d.Address))
+	}
+	if d.Detail != "" {
+		fmt.Fprintf(dv.detail, "\n%s\n", tview.Escape(d.Detail))
+	}
+	dv.detail.ScrollToBeginning()
+}
+
+// location formats the source location of a diagnostic relative to the root
+func (dv *DiagnosticsView) location(d *model.Diagnostic) string {
+	if d.File == "" {
+		return "-"
+	}
+	path := d.File
+	if rel, err := filepath.Rel(dv.root, d.File); err == nil && !strings.HasPrefix(rel, "..") {
+		path = rel
+	}
+	if d.Column > 0 {
+		return fmt.Sprintf("%s:%d:%d", path, d.Line, d.Column)
+	}
+	return fmt.Sprintf("%s:%d", path, d.Line)
+}
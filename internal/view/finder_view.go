@@ -0,0 +1,347 @@
+package view
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Kinds of finder items
+const (
+	FinderStack   = "stack"
+	FinderFile    = "tf"
+	FinderTfvars  = "tfvars"
+	FinderHistory = "history"
+)
+
+// finderLimit is the number of results listed at once
+const finderLimit = 200
+
+// FinderItem is an entry of the fuzzy finder
+type FinderItem struct {
+	Kind    string
+	Path    string    // path revealed in the tree when the item is chosen
+	Name    string    // text matched against the query, usually the path relative to the root
+	Detail  string    // dimmed text shown after the name
+	Used    time.Time // last time the item was used, zero if never
+	Preview string    // preview text; files and directories are read when empty
+}
+
+// FinderView is a Ctrl+P style overlay that fuzzy-searches stacks, files and history
+type FinderView struct {
+	*tview.Flex
+	input   *tview.InputField
+	table   *tview.Table
+	preview *tview.TextView
+	items   []*FinderItem
+	results []*FinderItem
+}
+
+// NewFinderView creates a new finder over items
+func NewFinderView(items []*FinderItem) *FinderView {
+	fv := &FinderView{
+		Flex:  tview.NewFlex(),
+		items: items,
+	}
+
+	// Query input
+	fv.input = tview.NewInputField().
+		SetLabel("🔍 ").
+		SetPlaceholder("stack, file or history, e.g. prdvpc or app/config").
+		SetFieldBackgroundColor(tcell.NewRGBColor(30, 30, 30)).
+		SetFieldTextColor(tcell.ColorWhite)
+	fv.input.SetBackgroundColor(tcell.ColorBlack)
+	fv.input.SetLabelColor(tcell.NewRGBColor(0, 255, 255))
+	fv.input.SetChangedFunc(func(text string) {
+		fv.search()
+	})
+
+	// Results
+	fv.table = tview.NewTable().
+		SetSelectable(true, false)
+	fv.table.SetBackgroundColor(tcell.ColorBlack)
+	fv.table.SetSelectedStyle(tcell.StyleDefault.
+		Background(tcell.NewRGBColor(0, 95, 135)).
+		Foreground(tcell.ColorWhite))
+	fv.table.SetSelectionChangedFunc(func(row, column int) {
+		fv.showPreview()
+	})
+
+	// Preview pane
+	fv.preview = tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetWrap(false)
+	fv.preview.SetBackgroundColor(tcell.ColorBlack)
+	fv.preview.SetBorder(true)
+	fv.preview.SetBorderColor(tcell.NewRGBColor(0, 255, 255))
+	fv.preview.SetTitle(" Preview ")
+
+	// Help line
+	help := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignLeft)
+	help.SetBackgroundColor(tcell.ColorBlack)
+	fmt.Fprintf(help, "[yellow]<↑/↓>[white] Move  [yellow]<Enter>[white] Reveal in Tree  [yellow]<Esc>[white] Close")
+
+	left := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(fv.input, 1, 0, true).
+		AddItem(fv.table, 0, 1, false).
+		AddItem(help, 1, 0, false)
+	left.SetBackgroundColor(tcell.ColorBlack)
+	left.SetBorder(true)
+	left.SetBorderColor(tcell.NewRGBColor(0, 255, 255))
+	left.SetTitle(fmt.Sprintf(" ⚡ Find (%d) ", len(items)))
+
+	panel := tview.NewFlex().
+		AddItem(left, 0, 1, true).
+		AddItem(fv.preview, 0, 1, false)
+
+	// Centered overlay
+	fv.SetDirection(tview.FlexRow).
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexColumn).
+			AddItem(nil, 0, 1, false).
+			AddItem(panel, 0, 8, true).
+			AddItem(nil, 0, 1, false), 0, 8, true).
+		AddItem(nil, 0, 1, false)
+	fv.SetBackgroundColor(tcell.ColorDefault)
+
+	fv.search()
+	return fv
+}
+
+// GetInput returns the query input
+func (fv *FinderView) GetInput() *tview.InputField {
+	return fv.input
+}
+
+// Move moves the selection by delta results
+func (fv *FinderView) Move(delta int) {
+	if len(fv.results) == 0 {
+		return
+	}
+	row, _ := fv.table.GetSelection()
+	row += delta
+	if row < 0 {
+		row = 0
+	}
+	if row >= len(fv.results) {
+		row = len(fv.results) - 1
+	}
+	fv.table.Select(row, 0)
+}
+
+// Selected returns the selected result, or nil
+func (fv *FinderView) Selected() *FinderItem {
+	row, _ := fv.table.GetSelection()
+	if row < 0 || row >= len(fv.results) {
+		return nil
+	}
+	return fv.results[row]
+}
+
+// search ranks the items against the query and lists the best matches.
+// Fuzzy score comes first, recency breaks ties and lifts recently used items.
+func (fv *FinderView) search() {
+	query := strings.TrimSpace(fv.input.GetText())
+
+	type ranked struct {
+		item  *FinderItem
+		score int
+	}
+	var matches []ranked
+	now := time.Now()
+	for _, item := range fv.items {
+		score, ok := fuzzyScore(query, item.Name)
+		if !ok {
+			continue
+		}
+		matches = append(matches, ranked{item, score + recencyBonus(now, item.Used)})
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		if !matches[i].item.Used.Equal(matches[j].item.Used) {
+			return matches[i].item.Used.After(matches[j].item.Used)
+		}
+		return matches[i].item.Name < matches[j].item.Name
+	})
+	if len(matches) > finderLimit {
+		matches = matches[:finderLimit]
+	}
+
+	fv.results = fv.results[:0]
+	fv.table.Clear()
+	for i, m := range matches {
+		fv.results = append(fv.results, m.item)
+		fv.table.SetCell(i, 0, tview.NewTableCell(finderIcon(m.item.Kind)).SetSelectable(true))
+		fv.table.SetCell(i, 1, tview.NewTableCell(tview.Escape(m.item.Name)).
+			SetTextColor(finderColor(m.item.Kind)).
+			SetExpansion(1))
+		fv.table.SetCell(i, 2, tview.NewTableCell(tview.Escape(m.item.Detail)).
+			SetTextColor(tcell.ColorGray))
+	}
+	fv.table.Select(0, 0)
+	fv.table.ScrollToBeginning()
+	fv.showPreview()
+}
+
+// showPreview shows the selected item in the preview pane
+func (fv *FinderView) showPreview() {
+	fv.preview.Clear()
+	item := fv.Selected()
+	if item == nil {
+		fmt.Fprintf(fv.preview, "[gray]No matches[white]")
+		return
+	}
+	fv.preview.SetTitle(fmt.Sprintf(" %s ", tview.Escape(filepath.Base(item.Path))))
+
+	if item.Preview != "" {
+		fmt.Fprint(fv.preview, item.Preview)
+	} else if info, err := os.Stat(item.Path); err != nil {
+		fmt.Fprintf(fv.preview, "[red]%s[white]", tview.Escape(err.Error()))
+	} else if info.IsDir() {
+		fmt.Fprint(fv.preview, previewDir(item.Path))
+	} else {
+		fmt.Fprint(fv.preview, previewFile(item.Path))
+	}
+	fv.preview.ScrollToBeginning()
+}
+
+// previewDir lists the terraform files of a directory
+func previewDir(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Sprintf("[red]%s[white]", tview.Escape(err.Error()))
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "[yellow]Directory:[white] %s\n\n", tview.Escape(dir))
+	for _, entry := range entries {
+		name := entry.Name()
+		switch {
+		case strings.HasPrefix(name, "."):
+			continue
+		case entry.IsDir():
+			fmt.Fprintf(&b, "[#64c8ff]%s/[white]\n", tview.Escape(name))
+		case strings.HasSuffix(name, ".tfvars"):
+			fmt.Fprintf(&b, "[#ff64ff]%s[white]\n", tview.Escape(name))
+		case strings.HasSuffix(name, ".tf"):
+			fmt.Fprintf(&b, "[#64ff64]%s[white]\n", tview.Escape(name))
+		}
+	}
+	return b.String()
+}
+
+// previewFile returns the first lines of a file
+func previewFile(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Sprintf("[red]%s[white]", tview.Escape(err.Error()))
+	}
+	defer f.Close()
+
+	var b strings.Builder
+	scanner := bufio.NewScanner(f)
+	for n := 0; n < 200 && scanner.Scan(); n++ {
+		b.WriteString(tview.Escape(scanner.Text()))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// fuzzyScore matches the query as a subsequence of text, ignoring case. Consecutive
+// characters and characters starting a path segment or word score higher.
+func fuzzyScore(query, text string) (int, bool) {
+	if query == "" {
+		return 0, true
+	}
+	q := []rune(strings.ToLower(query))
+	t := []rune(strings.ToLower(text))
+
+	score, qi, prev := 0, 0, -2
+	for ti := 0; ti < len(t) && qi < len(q); ti++ {
+		if q[qi] == ' ' {
+			// Spaces separate terms and match anything
+			qi++
+			prev = -2
+			if qi == len(q) {
+				break
+			}
+		}
+		if t[ti] != q[qi] {
+			continue
+		}
+		score++
+		if ti == prev+1 {
+			score += 3
+		}
+		if ti == 0 || (!unicode.IsLetter(t[ti-1]) && !unicode.IsDigit(t[ti-1])) {
+			score += 2
+		}
+		prev = ti
+		qi++
+	}
+	if qi < len(q) {
+		return 0, false
+	}
+	// Prefer shorter names among equal matches
+	return score*10 - len(t)/10, true
+}
+
+// recencyBonus lifts items used recently
+func recencyBonus(now, used time.Time) int {
+	if used.IsZero() {
+		return 0
+	}
+	switch age := now.Sub(used); {
+	case age < time.Hour:
+		return 40
+	case age < 24*time.Hour:
+		return 30
+	case age < 7*24*time.Hour:
+		return 20
+	case age < 30*24*time.Hour:
+		return 10
+	default:
+		return 0
+	}
+}
+
+// finderIcon returns the icon of an item kind
+func finderIcon(kind string) string {
+	switch kind {
+	case FinderStack:
+		return "📦"
+	case FinderTfvars:
+		return "🔧"
+	case FinderHistory:
+		return "⏰"
+	default:
+		return "📄"
+	}
+}
+
+// finderColor returns the name color of an item kind, matching the tree
+func finderColor(kind string) tcell.Color {
+	switch kind {
+	case FinderStack:
+		return tcell.NewRGBColor(100, 200, 255)
+	case FinderTfvars:
+		return tcell.NewRGBColor(255, 100, 255)
+	case FinderFile:
+		return tcell.NewRGBColor(100, 255, 100)
+	default:
+		return tcell.NewRGBColor(255, 215, 0)
+	}
+}
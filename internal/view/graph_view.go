@@ -0,0 +1,240 @@
+package view
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/idongju/t9s/internal/config"
+	"github.com/idongju/t9s/internal/model"
+	"github.com/rivo/tview"
+)
+
+// graphNode is the reference of a resource node in the graph tree
+type graphNode struct {
+	address string
+	loaded  bool // dependency groups were added
+}
+
+// GraphView displays a resource dependency graph as a navigable tree
+type GraphView struct {
+	*tview.Flex
+	filter *tview.InputField
+	tree   *tview.TreeView
+	detail *tview.TextView
+	stack  string
+	graph  *model.ResourceGraph
+}
+
+// NewGraphView creates a new graph view, with the keys of km in its help line
+func NewGraphView(stack string, graph *model.ResourceGraph, km *config.Keymap) *GraphView {
+	gv := &GraphView{
+		Flex:  tview.NewFlex(),
+		stack: stack,
+		graph: graph,
+	}
+
+	// Filter input
+	gv.filter = tview.NewInputField().
+		SetLabel("Filter: ").
+		SetPlaceholder("address, e.g. aws_subnet or module.vpc").
+		SetFieldBackgroundColor(tcell.NewRGBColor(30, 30, 30)).
+		SetFieldTextColor(tcell.ColorWhite)
+	gv.filter.SetBackgroundColor(tcell.ColorBlack)
+	gv.filter.SetLabelColor(tcell.NewRGBColor(0, 255, 255))
+	gv.filter.SetChangedFunc(func(text string) {
+		gv.rebuild()
+	})
+
+	// Graph tree
+	gv.tree = tview.NewTreeView()
+	gv.tree.SetBackgroundColor(tcell.ColorBlack)
+	gv.tree.SetBorder(true)
+	gv.tree.SetBorderColor(tcell.NewRGBColor(0, 255, 255))
+	gv.tree.SetGraphicsColor(tcell.NewRGBColor(0, 255, 255))
+	gv.tree.SetChangedFunc(func(node *tview.TreeNode) {
+		gv.showDetail(node)
+	})
+	gv.tree.SetSelectedFunc(func(node *tview.TreeNode) {
+		gv.expand(node)
+	})
+
+	// Detail pane
+	gv.detail = tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetWrap(true)
+	gv.detail.SetBackgroundColor(tcell.ColorBlack)
+	gv.detail.SetBorder(true)
+	gv.detail.SetBorderColor(tcell.NewRGBColor(0, 255, 255))
+	gv.detail.SetTitle(" Dependencies ")
+
+	// Help line
+	help := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignLeft)
+	help.SetBackgroundColor(tcell.ColorBlack)
+	fmt.Fprint(help, helpLine(
+		keyHint(km, config.ActionGraphFilter),
+		"[yellow]<Enter>[white] Expand",
+		"[yellow]<Tab>[white] Tree/Dependencies",
+		keyHint(km, config.ActionGraphExport),
+		"[yellow]<Esc>[white] Back",
+	))
+
+	body := tview.NewFlex().
+		AddItem(gv.tree, 0, 1, true).
+		AddItem(gv.detail, 0, 1, false)
+
+	gv.SetDirection(tview.FlexRow).
+		AddItem(gv.filter, 1, 0, false).
+		AddItem(body, 0, 1, true).
+		AddItem(help, 1, 0, false)
+	gv.SetBackgroundColor(tcell.ColorBlack)
+
+	gv.rebuild()
+	return gv
+}
+
+// GetTree returns the graph tree
+func (gv *GraphView) GetTree() *tview.TreeView {
+	return gv.tree
+}
+
+// GetFilter returns the filter input
+func (gv *GraphView) GetFilter() *tview.InputField {
+	return gv.filter
+}
+
+// GetDetail returns the dependency pane
+func (gv *GraphView) GetDetail() *tview.TextView {
+	return gv.detail
+}
+
+// rebuild lists the nodes matching the current filter
+func (gv *GraphView) rebuild() {
+	filter := strings.TrimSpace(gv.filter.GetText())
+
+	root := tview.NewTreeNode(gv.stack).
+		SetColor(tcell.NewRGBColor(255, 215, 0)).
+		SetSelectable(true)
+
+	count := 0
+	for _, address := range gv.graph.Nodes {
+		if !gv.graph.Matches(address, filter) {
+			continue
+		}
+		root.AddChild(gv.newNode(address))
+		count++
+	}
+
+	gv.tree.SetRoot(root)
+	gv.tree.SetCurrentNode(root)
+	if filter != "" {
+		gv.tree.SetTitle(fmt.Sprintf(" 🕸  Graph: %d of %d nodes ", count, len(gv.graph.Nodes)))
+	} else {
+		gv.tree.SetTitle(fmt.Sprintf(" 🕸  Graph: %d nodes ", len(gv.graph.Nodes)))
+	}
+	gv.showDetail(root)
+}
+
+// newNode creates a collapsed tree node for an address
+func (gv *GraphView) newNode(address string) *tview.TreeNode {
+	return tview.NewTreeNode(address).
+		SetReference(&graphNode{address: address}).
+		SetColor(nodeColor(address)).
+		SetExpanded(false)
+}
+
+// expand toggles a node, adding its dependency groups on first use
+func (gv *GraphView) expand(node *tview.TreeNode) {
+	ref, ok := node.GetReference().(*graphNode)
+	if ok && !ref.loaded {
+		ref.loaded = true
+		deps := gv.graph.DependsOn(ref.address)
+		dependents := gv.graph.Dependents(ref.address)
+
+		up := tview.NewTreeNode(fmt.Sprintf("depends on (%d)", len(deps))).
+			SetColor(tcell.NewRGBColor(255, 165, 0))
+		for _, d := range deps {
+			up.AddChild(gv.newNode(d))
+		}
+		down := tview.NewTreeNode(fmt.Sprintf("required by (%d)", len(dependents))).
+			SetColor(tcell.NewRGBColor(255, 165, 0))
+		for _, d := range dependents {
+			down.AddChild(gv.newNode(d))
+		}
+		node.AddChild(up).AddChild(down)
+		node.SetExpanded(true)
+		return
+	}
+	node.SetExpanded(!node.IsExpanded())
+}
+
+// showDetail shows the direct and transitive dependencies of the selected node
+func (gv *GraphView) showDetail(node *tview.TreeNode) {
+	gv.detail.Clear()
+	gv.detail.ScrollToBeginning()
+	if node == nil {
+		return
+	}
+
+	ref, ok := node.GetReference().(*graphNode)
+	if !ok {
+		fmt.Fprintf(gv.detail, "[cyan]Stack:[white] %s\n", gv.stack)
+		fmt.Fprintf(gv.detail, "[cyan]Nodes:[white] %d\n", len(gv.graph.Nodes))
+		edges := 0
+		for _, deps := range gv.graph.Deps {
+			edges += len(deps)
+		}
+		fmt.Fprintf(gv.detail, "[cyan]Edges:[white] %d\n", edges)
+		return
+	}
+
+	deps := gv.graph.DependsOn(ref.address)
+	dependents := gv.graph.Dependents(ref.address)
+	fmt.Fprintf(gv.detail, "[cyan]Address:[white] %s\n\n", tview.Escape(ref.address))
+
+	fmt.Fprintf(gv.detail, "[yellow]Depends on (%d direct, %d total):[white]\n", len(deps), len(gv.closure(ref.address, gv.graph.DependsOn)))
+	for _, d := range deps {
+		fmt.Fprintf(gv.detail, "  • %s\n", tview.Escape(d))
+	}
+	fmt.Fprintf(gv.detail, "\n[yellow]Required by (%d direct, %d total):[white]\n", len(dependents), len(gv.closure(ref.address, gv.graph.Dependents)))
+	for _, d := range dependents {
+		fmt.Fprintf(gv.detail, "  • %s\n", tview.Escape(d))
+	}
+	if len(dependents) > 0 {
+		fmt.Fprintf(gv.detail, "\n[gray]Replacing this node can cascade to the nodes that require it.[white]\n")
+	}
+}
+
+// closure returns every node reachable from address through next, excluding address
+func (gv *GraphView) closure(address string, next func(string) []string) map[string]bool {
+	seen := make(map[string]bool)
+	var walk func(n string)
+	walk = func(n string) {
+		for _, m := range next(n) {
+			if !seen[m] && m != address {
+				seen[m] = true
+				walk(m)
+			}
+		}
+	}
+	walk(address)
+	return seen
+}
+
+// nodeColor colors a graph node by kind
+func nodeColor(address string) tcell.Color {
+	switch {
+	case strings.HasPrefix(address, "provider"):
+		return tcell.NewRGBColor(150, 150, 150)
+	case strings.HasPrefix(address, "data.") || strings.Contains(address, ".data."):
+		return tcell.NewRGBColor(100, 200, 255)
+	case strings.HasPrefix(address, "module.") && !strings.Contains(strings.TrimPrefix(address, "module."), "."):
+		return tcell.NewRGBColor(255, 100, 255)
+	case strings.HasPrefix(address, "var.") || strings.HasPrefix(address, "local.") || strings.HasPrefix(address, "output."):
+		return tcell.NewRGBColor(200, 200, 200)
+	}
+	return tcell.NewRGBColor(100, 255, 100)
+}
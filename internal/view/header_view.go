@@ -3,10 +3,11 @@ package view
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/gdamore/tcell/v2"
+	"github.com/idongju/t9s/internal/config"
 	"github.com/rivo/tview"
 )
 
@@ -14,9 +15,12 @@ import (
 type HeaderView struct {
 	*tview.Flex
 	currentDir string
+	context    string
 	workspace  string
 	gitBranch  string
 	gitDirty   bool
+	lastRun    string // last terraform result of the selected stack, with style tags
+	keymap     *config.Keymap
 }
 
 // NewHeaderView creates a new header view
@@ -24,7 +28,7 @@ func NewHeaderView(currentDir string) *HeaderView {
 	hv := &HeaderView{
 		Flex:       tview.NewFlex(),
 		currentDir: currentDir,
-		workspace:  "default",
+		keymap:     config.DefaultKeymap(),
 	}
 
 	hv.buildHeader()
@@ -45,15 +49,21 @@ func (hv *HeaderView) buildHeader() {
 	user := os.Getenv("USER")
 	host, _ := os.Hostname()
 
-	// Get terraform workspace
-	workspace := "default"
-	cmd := exec.Command("terraform", "workspace", "show")
-	if out, err := cmd.Output(); err == nil {
-		workspace = strings.TrimSpace(string(out))
+	// Workspace is fetched in the background for the selected stack
+	workspace := hv.workspace
+	if workspace == "" {
+		workspace = "-"
 	}
-	hv.workspace = workspace
 
-	fmt.Fprintf(infoText, "[cyan]Context:[white]  %s\n", workspace)
+	context := hv.context
+	if context == "" {
+		context = "-"
+	}
+	fmt.Fprintf(infoText, "[cyan]Context:[white]  %s\n", context)
+	fmt.Fprintf(infoText, "[cyan]Workspace:[white] %s\n", workspace)
+	if hv.lastRun != "" {
+		fmt.Fprintf(infoText, "[cyan]Last run:[white] %s\n", hv.lastRun)
+	}
 	fmt.Fprintf(infoText, "[cyan]Path:[white]     %s\n", hv.currentDir)
 
 	// Show git branch if available
@@ -75,10 +85,7 @@ func (hv *HeaderView) buildHeader() {
 		SetTextAlign(tview.AlignLeft)
 	shortcuts.SetBackgroundColor(tcell.ColorBlack)
 
-	fmt.Fprintf(shortcuts, "[yellow]<i>[white] Init        [yellow]<p>[white] Plan      [yellow]<a>[white] Apply\n")
-	fmt.Fprintf(shortcuts, "[yellow]<d>[white] Destroy     [yellow]<h>[white] History   [yellow]<e>[white] Edit\n")
-	fmt.Fprintf(shortcuts, "[yellow]<s>[white] Settings    [yellow]<B>[white] Branch    [yellow]<?>[white] Help\n")
-	fmt.Fprintf(shortcuts, "[yellow]</>[white] Command     [yellow]<C>[white] Home      [yellow]<q>[white] Quit")
+	hv.writeShortcuts(shortcuts)
 
 	// Logo
 	logo := tview.NewTextView().
@@ -105,12 +112,71 @@ func (hv *HeaderView) buildHeader() {
 	hv.SetBackgroundColor(tcell.ColorBlack)
 }
 
-// UpdateWorkspace updates the workspace information
+// headerShortcutRows is the number of rows of the shortcut list
+const headerShortcutRows = 7
+
+// writeShortcuts lists the actions shown in the header with their keys, column by column
+func (hv *HeaderView) writeShortcuts(w *tview.TextView) {
+	type shortcut struct{ key, label string }
+	var shortcuts []shortcut
+	for _, action := range config.KeyActions {
+		if key := hv.keymap.Label(action.Name); action.Header != "" && key != "" {
+			shortcuts = append(shortcuts, shortcut{key, action.Header})
+		}
+	}
+
+	// Pad each column to its widest entry
+	columns := (len(shortcuts) + headerShortcutRows - 1) / headerShortcutRows
+	widths := make([]int, columns)
+	for i, s := range shortcuts {
+		if width := utf8.RuneCountInString(s.key) + 1 + len(s.label); width > widths[i/headerShortcutRows] {
+			widths[i/headerShortcutRows] = width
+		}
+	}
+
+	for row := 0; row < headerShortcutRows && row < len(shortcuts); row++ {
+		if row > 0 {
+			fmt.Fprint(w, "\n")
+		}
+		for col := 0; col < columns; col++ {
+			i := col*headerShortcutRows + row
+			if i >= len(shortcuts) {
+				break
+			}
+			s := shortcuts[i]
+			padding := ""
+			if col < columns-1 {
+				padding = strings.Repeat(" ", widths[col]-utf8.RuneCountInString(s.key)-1-len(s.label)+2)
+			}
+			fmt.Fprintf(w, "[yellow]%s[white] %s%s", tview.Escape(s.key), s.label, padding)
+		}
+	}
+}
+
+// SetKeymap sets the key bindings listed in the shortcuts
+func (hv *HeaderView) SetKeymap(km *config.Keymap) {
+	hv.keymap = km
+	hv.buildHeader()
+}
+
+// UpdateWorkspace updates the workspace of the selected stack, "" when there is none
 func (hv *HeaderView) UpdateWorkspace(workspace string) {
 	hv.workspace = workspace
 	hv.buildHeader()
 }
 
+// SetLastRun updates the last terraform result of the selected stack, "" when there is none
+func (hv *HeaderView) SetLastRun(result string) {
+	hv.lastRun = result
+	hv.buildHeader()
+}
+
+// SetContext updates the active context name
+func (hv *HeaderView) SetContext(name string) {
+	hv.context = name
+	hv.buildHeader()
+}
+
 // SetGitBranch updates the git branch information
 func (hv *HeaderView) SetGitBranch(branch string, isDirty bool) {
 	hv.gitBranch = branch
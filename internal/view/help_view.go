@@ -4,91 +4,64 @@ import (
 	"fmt"
 
 	"github.com/gdamore/tcell/v2"
+	"github.com/idongju/t9s/internal/config"
 	"github.com/rivo/tview"
 )
 
 // HelpView represents the help screen
 type HelpView struct {
 	*tview.Flex
+	height int
 }
 
-// NewHelpView creates a new help view
-func NewHelpView() *HelpView {
+// helpSections are the sections of the help screen generated from the keymap, in order
+var helpSections = []string{"TERRAFORM", "STACKS", "CONTENT", "GENERAL"}
+
+// NewHelpView creates a new help view listing the keys of the active keymap
+func NewHelpView(km *config.Keymap) *HelpView {
 	hv := &HelpView{
 		Flex: tview.NewFlex(),
 	}
 
-	// Create help sections for T9s (실제 구현된 단축키)
-	resourceSection := hv.createSection("RESOURCE", []HelpItem{
-		{"<enter>", "Expand/View"},
-		{"<e>", "Edit File"},
-		{"<i>", "Terraform Init"},
-		{"<p>", "Terraform Plan"},
-		{"<a>", "Terraform Apply"},
-		{"<d>", "Terraform Destroy"},
-		{"<h>", "History"},
-		{"<s>", "Settings"},
-	})
-
-	generalSection := hv.createSection("GENERAL", []HelpItem{
-		{"<esc>", "Back/Clear"},
-		{"</>", "Command Mode"},
-		{"<shift-c>", "Home (Commands)"},
-		{"<shift-b>", "Branch Switch"},
-		{"<q>", "Quit"},
-		{"<ctrl-c>", "Quit"},
-		{"<?>", "Help"},
-		{"<shift-h>", "Help"},
-	})
-	
-	historySection := hv.createSection("HISTORY VIEW", []HelpItem{
-		{"<h>", "Show History"},
-		{"<shift-m>", "Toggle Details"},
-		{"<d>", "Load More (3)"},
-		{"<u>", "Load Less"},
-	})
-	
-	gitSection := hv.createSection("GIT", []HelpItem{
-		{"<shift-b>", "Branch Switch"},
+	// Sections of remappable actions, in the order of config.KeyActions.
+	// The other sub-views list their keys in their own help line.
+	items := make(map[string][]HelpItem)
+	for _, action := range config.KeyActions {
+		if label := km.Label(action.Name); label != "" {
+			items[action.Section] = append(items[action.Section], HelpItem{label, action.Description})
+		}
+	}
+	// Keys of the tree and the dialogs, which are not in the keymap
+	items["GENERAL"] = append(items["GENERAL"],
+		HelpItem{"<↑/↓>", "Navigate"},
+		HelpItem{"<enter>", "Expand/Collapse (tree)"},
+	)
+
+	historyItems := append([]HelpItem{{km.Label(config.ActionHistory), "Show History"}}, items["HISTORY VIEW"]...)
+	gitItems := []HelpItem{
+		{km.Label(config.ActionBranch), "Branch Switch"},
 		{"Stash", "Stash & Switch"},
 		{"Commit", "Commit & Switch"},
 		{"Force", "Discard & Switch"},
-	})
-
-	navigationSection := hv.createSection("NAVIGATION", []HelpItem{
-		{"<↑/↓>", "Navigate"},
-		{"<enter>", "Expand/Collapse"},
-		{"<tab>", "Switch Focus"},
-	})
-
-	tfSection := hv.createSection("TERRAFORM", []HelpItem{
-		{"<i>", "Init"},
-		{"<p>", "Plan"},
-		{"<a>", "Apply"},
-		{"<d>", "Destroy"},
-		{"<h>", "Show History"},
-	})
-
-	// Combine all sections
-	topRow := tview.NewFlex().
-		AddItem(resourceSection, 0, 1, false).
-		AddItem(generalSection, 0, 1, false)
-	topRow.SetBackgroundColor(tcell.ColorBlack)
-
-	bottomRow := tview.NewFlex().
-		AddItem(navigationSection, 0, 1, false).
-		AddItem(tfSection, 0, 1, false)
-	bottomRow.SetBackgroundColor(tcell.ColorBlack)
-	
-	thirdRow := tview.NewFlex().
-		AddItem(historySection, 0, 1, false).
-		AddItem(gitSection, 0, 1, false)
-	thirdRow.SetBackgroundColor(tcell.ColorBlack)
-
-	hv.SetDirection(tview.FlexRow).
-		AddItem(topRow, 0, 1, false).
-		AddItem(bottomRow, 0, 1, false).
-		AddItem(thirdRow, 0, 1, false)
+	}
+
+	left, leftLines := hv.createColumn(
+		[]string{helpSections[0], helpSections[1], "HISTORY VIEW"},
+		[][]HelpItem{items[helpSections[0]], items[helpSections[1]], historyItems},
+	)
+	right, rightLines := hv.createColumn(
+		[]string{helpSections[2], helpSections[3], "GIT"},
+		[][]HelpItem{items[helpSections[2]], items[helpSections[3]], gitItems},
+	)
+	hv.height = leftLines
+	if rightLines > hv.height {
+		hv.height = rightLines
+	}
+	hv.height += 2 // border
+
+	hv.SetDirection(tview.FlexColumn).
+		AddItem(left, 0, 1, false).
+		AddItem(right, 0, 1, false)
 	hv.SetBackgroundColor(tcell.ColorBlack)
 	hv.SetBorder(true)
 	hv.SetBorderColor(tcell.NewRGBColor(0, 255, 255))
@@ -97,6 +70,24 @@ func NewHelpView() *HelpView {
 	return hv
 }
 
+// Height returns the number of rows needed to show every section
+func (hv *HelpView) Height() int {
+	return hv.height
+}
+
+// createColumn stacks sections on top of each other, returning the column and its number of lines
+func (hv *HelpView) createColumn(titles []string, sections [][]HelpItem) (*tview.Flex, int) {
+	column := tview.NewFlex().SetDirection(tview.FlexRow)
+	lines := 0
+	for i, title := range titles {
+		column.AddItem(hv.createSection(title, sections[i]), len(sections[i])+1, 0, false)
+		lines += len(sections[i]) + 1
+	}
+	column.AddItem(nil, 0, 1, false)
+	column.SetBackgroundColor(tcell.ColorBlack)
+	return column, lines
+}
+
 // HelpItem represents a single help item
 type HelpItem struct {
 	Key         string
@@ -112,7 +103,7 @@ func (hv *HelpView) createSection(title string, items []HelpItem) *tview.TextVie
 
 	fmt.Fprintf(section, "[green::b]%s[white]\n", title)
 	for _, item := range items {
-		fmt.Fprintf(section, "[blue]%-15s[white] %s\n", item.Key, item.Description)
+		fmt.Fprintf(section, "[blue]%-15s[white] %s\n", tview.Escape(item.Key), item.Description)
 	}
 
 	return section
@@ -0,0 +1,390 @@
+package view
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// Syntax highlighting colors
+const (
+	colorComment   = "#75715e"
+	colorKeyword   = "#f92672"
+	colorKey       = "#66d9ef"
+	colorString    = "#e6db74"
+	colorInterp    = "#fd971f"
+	colorConstant  = "#ae81ff"
+	colorReference = "#a6e22e"
+	colorFunction  = "#fd971f"
+)
+
+// hclKeywords are the block types highlighted at the start of a block header
+var hclKeywords = map[string]bool{
+	"resource": true, "data": true, "variable": true, "output": true, "module": true,
+	"locals": true, "provider": true, "terraform": true, "backend": true, "moved": true,
+	"import": true, "removed": true, "check": true, "dynamic": true, "lifecycle": true,
+	"provisioner": true, "connection": true, "required_providers": true, "content": true,
+	"precondition": true, "postcondition": true, "validation": true, "cloud": true,
+}
+
+// hclReferences are the prefixes of references highlighted as a whole, e.g. var.region
+var hclReferences = map[string]bool{
+	"var": true, "local": true, "module": true, "data": true, "each": true,
+	"count": true, "path": true, "self": true, "terraform": true,
+}
+
+// Highlight returns the lines of a file with style tags for its syntax, chosen by file
+// extension. Files of other types are escaped without highlighting.
+func Highlight(path, src string) []string {
+	var text string
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".tf", ".tfvars", ".hcl":
+		text = highlightHCL(src)
+	case ".json":
+		text = highlightJSON(src)
+	case ".yaml", ".yml":
+		text = highlightYAML(src)
+	default:
+		text = tview.Escape(src)
+	}
+	return strings.Split(text, "\n")
+}
+
+// highlighter accumulates tagged text; uncolored text is escaped in runs
+type highlighter struct {
+	b     strings.Builder
+	plain strings.Builder
+}
+
+// text adds uncolored text
+func (h *highlighter) text(s string) {
+	h.plain.WriteString(s)
+}
+
+// color adds colored text. The color is closed at every line break so lines can be
+// displayed independently.
+func (h *highlighter) color(color, s string) {
+	h.flush()
+	for i, line := range strings.Split(s, "\n") {
+		if i > 0 {
+			h.b.WriteString("\n")
+		}
+		if line != "" {
+			h.b.WriteString("[" + color + "]" + tview.Escape(line) + "[-]")
+		}
+	}
+}
+
+// flush writes the pending uncolored text
+func (h *highlighter) flush() {
+	if h.plain.Len() > 0 {
+		h.b.WriteString(tview.Escape(h.plain.String()))
+		h.plain.Reset()
+	}
+}
+
+// String returns the tagged text
+func (h *highlighter) String() string {
+	h.flush()
+	return h.b.String()
+}
+
+// highlightHCL highlights Terraform and HCL configuration or tfvars
+func highlightHCL(src string) string {
+	h := &highlighter{}
+	lineStart := true
+	i := 0
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == '\n':
+			h.text("\n")
+			lineStart = true
+			i++
+			continue
+		case c == ' ' || c == '\t' || c == '\r':
+			h.text(string(c))
+			i++
+			continue
+		case c == '#' || (c == '/' && strings.HasPrefix(src[i:], "//")):
+			end := strings.IndexByte(src[i:], '\n')
+			if end < 0 {
+				end = len(src) - i
+			}
+			h.color(colorComment, src[i:i+end])
+			i += end
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				end = len(src) - i
+			} else {
+				end += 4
+			}
+			h.color(colorComment, src[i:i+end])
+			i += end
+		case c == '"':
+			i = h.hclString(src, i)
+		case strings.HasPrefix(src[i:], "<<") && i+2 < len(src) && (isWordStart(src[i+2]) || src[i+2] == '-'):
+			end := heredocEnd(src, i)
+			h.color(colorString, src[i:end])
+			i = end
+		case isWordStart(c):
+			i = h.hclWord(src, i, lineStart)
+		case c >= '0' && c <= '9':
+			end := i
+			for end < len(src) && (isWordPart(src[end]) || src[end] == '.') {
+				end++
+			}
+			h.color(colorConstant, src[i:end])
+			i = end
+		default:
+			h.text(string(c))
+			i++
+		}
+		lineStart = false
+	}
+	return h.String()
+}
+
+// hclWord highlights an identifier: a block type at the start of a block header, an
+// attribute name, a constant, a reference such as var.region or a function call
+func (h *highlighter) hclWord(src string, i int, lineStart bool) int {
+	end := i
+	for end < len(src) && isWordPart(src[end]) {
+		end++
+	}
+	word := src[i:end]
+	rest := strings.TrimLeft(src[end:], " \t")
+
+	switch {
+	case word == "true" || word == "false" || word == "null":
+		h.color(colorConstant, word)
+	case hclReferences[word] && strings.HasPrefix(src[end:], "."):
+		// Take the whole traversal, e.g. module.vpc.vpc_id
+		for end < len(src) && src[end] == '.' && end+1 < len(src) && (isWordStart(src[end+1]) || src[end+1] == '*') {
+			end++
+			for end < len(src) && (isWordPart(src[end]) || src[end] == '*') {
+				end++
+			}
+		}
+		h.color(colorReference, src[i:end])
+	case lineStart && (strings.HasPrefix(rest, "=") && !strings.HasPrefix(rest, "==")):
+		h.color(colorKey, word)
+	case lineStart && hclKeywords[word] && (strings.HasPrefix(rest, "\"") || strings.HasPrefix(rest, "{") || (rest != "" && isWordStart(rest[0]))):
+		h.color(colorKeyword, word)
+	case strings.HasPrefix(rest, "("):
+		h.color(colorFunction, word)
+	case strings.HasPrefix(rest, "=") && !strings.HasPrefix(rest, "=="), strings.HasPrefix(rest, ":"):
+		// Object keys
+		h.color(colorKey, word)
+	default:
+		h.text(word)
+	}
+	return end
+}
+
+// hclString highlights a quoted string starting at i with its ${...} interpolations,
+// returning the offset after the closing quote
+func (h *highlighter) hclString(src string, i int) int {
+	start := i
+	i++
+	for i < len(src) {
+		switch src[i] {
+		case '\\':
+			i += 2
+			continue
+		case '"':
+			h.color(colorString, src[start:i+1])
+			return i + 1
+		case '\n':
+			// Unterminated string
+			h.color(colorString, src[start:i])
+			return i
+		case '$', '%':
+			if i+1 < len(src) && src[i+1] == '{' {
+				h.color(colorString, src[start:i])
+				end := interpolationEnd(src, i+2)
+				h.color(colorInterp, src[i:end])
+				i, start = end, end
+				continue
+			}
+		}
+		i++
+	}
+	h.color(colorString, src[start:])
+	return len(src)
+}
+
+// interpolationEnd returns the offset after the "}" closing an interpolation
+func interpolationEnd(src string, i int) int {
+	depth := 1
+	for i < len(src) {
+		switch src[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		case '\n':
+			return i
+		}
+		i++
+	}
+	return len(src)
+}
+
+// heredocEnd returns the offset after the closing marker of a heredoc starting at i
+func heredocEnd(src string, i int) int {
+	i += 2
+	if i < len(src) && src[i] == '-' {
+		i++
+	}
+	start := i
+	for i < len(src) && isWordPart(src[i]) {
+		i++
+	}
+	marker := src[start:i]
+	for i < len(src) {
+		nl := strings.IndexByte(src[i:], '\n')
+		if nl < 0 {
+			return len(src)
+		}
+		i += nl + 1
+		lineEnd := strings.IndexByte(src[i:], '\n')
+		if lineEnd < 0 {
+			lineEnd = len(src) - i
+		}
+		if strings.TrimSpace(src[i:i+lineEnd]) == marker {
+			return i + lineEnd
+		}
+	}
+	return len(src)
+}
+
+// highlightJSON highlights JSON, telling keys from string values
+func highlightJSON(src string) string {
+	h := &highlighter{}
+	i := 0
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == '"':
+			end := i + 1
+			for end < len(src) && src[end] != '"' && src[end] != '\n' {
+				if src[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end < len(src) && src[end] == '"' {
+				end++
+			}
+			if strings.HasPrefix(strings.TrimLeft(src[end:], " \t"), ":") {
+				h.color(colorKey, src[i:end])
+			} else {
+				h.color(colorString, src[i:end])
+			}
+			i = end
+		case c == '-' || (c >= '0' && c <= '9'):
+			end := i + 1
+			for end < len(src) && strings.IndexByte("0123456789.eE+-", src[end]) >= 0 {
+				end++
+			}
+			h.color(colorConstant, src[i:end])
+			i = end
+		case isWordStart(c):
+			end := i
+			for end < len(src) && isWordPart(src[end]) {
+				end++
+			}
+			h.color(colorConstant, src[i:end])
+			i = end
+		default:
+			h.text(string(c))
+			i++
+		}
+	}
+	return h.String()
+}
+
+var (
+	yamlKeyPattern   = regexp.MustCompile(`^(\s*(?:-\s+)?)([^\s#'"{\[][^#]*?|"[^"]*"|'[^']*'):(\s|$)`)
+	yamlConstPattern = regexp.MustCompile(`^(true|false|yes|no|on|off|null|~|-?[0-9][0-9_.eE+-]*)$`)
+)
+
+// highlightYAML highlights YAML line by line: comments, keys, scalars and anchors
+func highlightYAML(src string) string {
+	h := &highlighter{}
+	for n, line := range strings.Split(src, "\n") {
+		if n > 0 {
+			h.text("\n")
+		}
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "#"):
+			h.color(colorComment, line)
+			continue
+		case trimmed == "---" || trimmed == "...":
+			h.color(colorKeyword, line)
+			continue
+		}
+
+		rest := line
+		if m := yamlKeyPattern.FindStringSubmatchIndex(line); m != nil {
+			h.text(line[:m[4]])
+			h.color(colorKey, line[m[4]:m[5]])
+			h.text(":")
+			rest = line[m[5]+1:]
+		} else if strings.HasPrefix(trimmed, "- ") {
+			indent := len(line) - len(strings.TrimLeft(line, " \t"))
+			h.text(line[:indent])
+			h.color(colorKeyword, "-")
+			rest = line[indent+1:]
+		}
+		yamlValue(h, rest)
+	}
+	return h.String()
+}
+
+// yamlValue highlights the value part of a YAML line with its trailing comment
+func yamlValue(h *highlighter, value string) {
+	comment := ""
+	if idx := strings.Index(value, " #"); idx >= 0 && !strings.ContainsAny(value[:idx], `"'`) {
+		value, comment = value[:idx], value[idx:]
+	}
+	lead := value[:len(value)-len(strings.TrimLeft(value, " \t"))]
+	trimmed := strings.TrimRight(value[len(lead):], " \t\r")
+	tail := value[len(lead)+len(trimmed):]
+	switch {
+	case trimmed == "":
+		h.text(value)
+		tail = ""
+	case strings.HasPrefix(trimmed, "&") || strings.HasPrefix(trimmed, "*"):
+		h.text(lead)
+		h.color(colorReference, trimmed)
+	case strings.HasPrefix(trimmed, `"`) || strings.HasPrefix(trimmed, "'"):
+		h.text(lead)
+		h.color(colorString, trimmed)
+	case yamlConstPattern.MatchString(trimmed):
+		h.text(lead)
+		h.color(colorConstant, trimmed)
+	default:
+		h.text(lead + trimmed)
+	}
+	h.text(tail)
+	if comment != "" {
+		h.color(colorComment, comment)
+	}
+}
+
+func isWordStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isWordPart(c byte) bool {
+	return isWordStart(c) || c == '-' || (c >= '0' && c <= '9')
+}
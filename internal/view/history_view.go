@@ -6,6 +6,7 @@ import (
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
+	"github.com/idongju/t9s/internal/config"
 	"github.com/idongju/t9s/internal/db"
 )
 
@@ -17,10 +18,11 @@ type HistoryView struct {
 	displaySize int
 	showDetails bool
 	directory   string
+	keymap      *config.Keymap
 }
 
-// NewHistoryView creates a new history view
-func NewHistoryView(directory string, entries []*db.HistoryEntry) *HistoryView {
+// NewHistoryView creates a new history view, with the keys of km in its shortcuts line
+func NewHistoryView(directory string, entries []*db.HistoryEntry, km *config.Keymap) *HistoryView {
 	hv := &HistoryView{
 		TextView:    tview.NewTextView().SetDynamicColors(true),
 		entries:     entries,
@@ -28,6 +30,7 @@ func NewHistoryView(directory string, entries []*db.HistoryEntry) *HistoryView {
 		displaySize: 3,
 		showDetails: false,
 		directory:   directory,
+		keymap:      km,
 	}
 
 	hv.SetBorder(true)
@@ -49,13 +52,19 @@ func (hv *HistoryView) render() {
 	
 	// Show keyboard shortcuts at the top
 	fmt.Fprintf(hv.TextView, "[yellow]Shortcuts:[white] ")
-	if !hv.showDetails {
-		fmt.Fprintf(hv.TextView, "[green]<Shift+M>[white] Show Details  ")
-	} else {
-		fmt.Fprintf(hv.TextView, "[green]<Shift+M>[white] Hide Details  ")
+	if label := hv.keymap.Label(config.ActionHistoryDetails); label != "" {
+		if !hv.showDetails {
+			fmt.Fprintf(hv.TextView, "[green]%s[white] Show Details  ", tview.Escape(label))
+		} else {
+			fmt.Fprintf(hv.TextView, "[green]%s[white] Hide Details  ", tview.Escape(label))
+		}
+	}
+	if label := hv.keymap.Label(config.ActionHistoryMore); label != "" {
+		fmt.Fprintf(hv.TextView, "[green]%s[white] Load More  ", tview.Escape(label))
+	}
+	if label := hv.keymap.Label(config.ActionHistoryLess); label != "" {
+		fmt.Fprintf(hv.TextView, "[green]%s[white] Load Less  ", tview.Escape(label))
 	}
-	fmt.Fprintf(hv.TextView, "[green]<d>[white] Load More  ")
-	fmt.Fprintf(hv.TextView, "[green]<u>[white] Load Less  ")
 	fmt.Fprintf(hv.TextView, "[green]<Esc>[white] Back\n")
 	fmt.Fprintf(hv.TextView, "[cyan]%s[white]\n\n", strings.Repeat("─", 60))
 
@@ -110,6 +119,9 @@ func (hv *HistoryView) renderEntry(entry *db.HistoryEntry, index int) {
 		if entry.Branch != "" {
 			fmt.Fprintf(hv.TextView, "  [gray]Branch:[white] [cyan]%s[white]", entry.Branch)
 		}
+		if entry.Workspace != "" {
+			fmt.Fprintf(hv.TextView, "  [gray]Workspace:[white] [cyan]%s[white]", entry.Workspace)
+		}
 		fmt.Fprintf(hv.TextView, "\n")
 	}
 
@@ -117,6 +129,20 @@ func (hv *HistoryView) renderEntry(entry *db.HistoryEntry, index int) {
 		fmt.Fprintf(hv.TextView, "     [gray]Config:[white] %s\n", entry.ConfigFile)
 	}
 
+	if entry.Addresses != "" {
+		fmt.Fprintf(hv.TextView, "     [gray]Addresses:[white]\n")
+		for _, addr := range strings.Split(entry.Addresses, "\n") {
+			fmt.Fprintf(hv.TextView, "       [cyan]%s[white]\n", tview.Escape(addr))
+		}
+	}
+
+	if entry.Targets != "" {
+		fmt.Fprintf(hv.TextView, "     [red]⚠ Targeted:[white]\n")
+		for _, addr := range strings.Split(entry.Targets, "\n") {
+			fmt.Fprintf(hv.TextView, "       [yellow]-target=%s[white]\n", tview.Escape(addr))
+		}
+	}
+
 	if !entry.Success && entry.ErrorMsg != "" {
 		fmt.Fprintf(hv.TextView, "     [red]Error:[white] %s\n", entry.ErrorMsg)
 	}
@@ -0,0 +1,82 @@
+package view
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/idongju/t9s/internal/config"
+	"github.com/rivo/tview"
+)
+
+// eventKeyNames are the canonical names of the special keys, as used in keys.yaml
+var eventKeyNames = map[tcell.Key]string{
+	tcell.KeyTab:        "Tab",
+	tcell.KeyBacktab:    "Backtab",
+	tcell.KeyEnter:      "Enter",
+	tcell.KeyEscape:     "Esc",
+	tcell.KeyBackspace:  "Backspace",
+	tcell.KeyBackspace2: "Backspace",
+	tcell.KeyDelete:     "Delete",
+	tcell.KeyInsert:     "Insert",
+	tcell.KeyUp:         "Up",
+	tcell.KeyDown:       "Down",
+	tcell.KeyLeft:       "Left",
+	tcell.KeyRight:      "Right",
+	tcell.KeyHome:       "Home",
+	tcell.KeyEnd:        "End",
+	tcell.KeyPgUp:       "PgUp",
+	tcell.KeyPgDn:       "PgDn",
+}
+
+// KeyName returns the canonical name of a key event as used in keys.yaml, e.g. "p", "G",
+// "Space", "Ctrl+P" or "Enter", or "" for a key that cannot be bound
+func KeyName(event *tcell.EventKey) string {
+	key := event.Key()
+	if key == tcell.KeyRune {
+		if event.Modifiers()&tcell.ModAlt != 0 {
+			return ""
+		}
+		if event.Rune() == ' ' {
+			return "Space"
+		}
+		return string(event.Rune())
+	}
+	// Tab, Enter, Esc and Backspace share their codes with Ctrl+I, Ctrl+M, Ctrl+[ and Ctrl+H
+	if name, ok := eventKeyNames[key]; ok {
+		return name
+	}
+	if key >= tcell.KeyCtrlA && key <= tcell.KeyCtrlZ {
+		return fmt.Sprintf("Ctrl+%c", 'A'+rune(key-tcell.KeyCtrlA))
+	}
+	if key >= tcell.KeyF1 && key <= tcell.KeyF12 {
+		return fmt.Sprintf("F%d", key-tcell.KeyF1+1)
+	}
+	return ""
+}
+
+// keyHint formats the keys and description of an action for the help line of a view,
+// e.g. "[yellow]<m>[white] Move", or "" for an unbound action
+func keyHint(km *config.Keymap, action string) string {
+	label := km.Label(action)
+	if label == "" {
+		return ""
+	}
+	for _, a := range config.KeyActions {
+		if a.Name == action {
+			return fmt.Sprintf("[yellow]%s[white] %s", tview.Escape(label), a.Description)
+		}
+	}
+	return ""
+}
+
+// helpLine joins the hints of a help line, leaving out empty ones
+func helpLine(hints ...string) string {
+	var shown []string
+	for _, hint := range hints {
+		if hint != "" {
+			shown = append(shown, hint)
+		}
+	}
+	return strings.Join(shown, "  ")
+}
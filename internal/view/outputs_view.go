@@ -0,0 +1,296 @@
+package view
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/idongju/t9s/internal/config"
+	"github.com/idongju/t9s/internal/model"
+	"github.com/rivo/tview"
+)
+
+// OutputsView displays the outputs of a stack with the stacks that consume them
+type OutputsView struct {
+	*tview.Flex
+	table     *tview.Table
+	detail    *tview.TextView
+	stack     string
+	root      string
+	outputs   []*model.StateOutput
+	revealed  map[string]bool
+	consumers []*model.OutputConsumer
+	scanned   bool
+}
+
+// NewOutputsView creates a new outputs view; root is used to shorten consumer paths
+// and km gives the keys of the help line
+func NewOutputsView(stack, root string, outputs []*model.StateOutput, km *config.Keymap) *OutputsView {
+	ov := &OutputsView{
+		Flex:     tview.NewFlex(),
+		stack:    stack,
+		root:     root,
+		outputs:  outputs,
+		revealed: make(map[string]bool),
+	}
+
+	// Output table
+	ov.table = tview.NewTable().
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	ov.table.SetBackgroundColor(tcell.ColorBlack)
+	ov.table.SetBorder(true)
+	ov.table.SetBorderColor(tcell.NewRGBColor(0, 255, 255))
+	ov.table.SetTitle(fmt.Sprintf(" 📤 Outputs: %s (%d) ", stack, len(outputs)))
+	ov.table.SetSelectedStyle(tcell.StyleDefault.
+		Background(tcell.NewRGBColor(0, 95, 135)).
+		Foreground(tcell.ColorWhite))
+	ov.table.SetSelectionChangedFunc(func(row, column int) {
+		ov.showDetail()
+	})
+
+	// Detail pane
+	ov.detail = tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetWrap(true)
+	ov.detail.SetBackgroundColor(tcell.ColorBlack)
+	ov.detail.SetBorder(true)
+	ov.detail.SetBorderColor(tcell.NewRGBColor(0, 255, 255))
+	ov.detail.SetTitle(" Value ")
+
+	// Help line
+	help := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignLeft)
+	help.SetBackgroundColor(tcell.ColorBlack)
+	fmt.Fprint(help, helpLine(
+		keyHint(km, config.ActionOutputsReveal),
+		keyHint(km, config.ActionOutputsRevealAll),
+		keyHint(km, config.ActionOutputsCopy),
+		"[yellow]<Tab>[white] Table/Value",
+		"[yellow]<Esc>[white] Back",
+	))
+
+	ov.SetDirection(tview.FlexRow).
+		AddItem(ov.table, 0, 1, true).
+		AddItem(ov.detail, 0, 1, false).
+		AddItem(help, 1, 0, false)
+	ov.SetBackgroundColor(tcell.ColorBlack)
+
+	ov.render()
+	return ov
+}
+
+// GetTable returns the output table
+func (ov *OutputsView) GetTable() *tview.Table {
+	return ov.table
+}
+
+// GetDetail returns the value pane
+func (ov *OutputsView) GetDetail() *tview.TextView {
+	return ov.detail
+}
+
+// SelectedOutput returns the output under the cursor
+func (ov *OutputsView) SelectedOutput() *model.StateOutput {
+	row, _ := ov.table.GetSelection()
+	if row < 1 || row > len(ov.outputs) {
+		return nil
+	}
+	return ov.outputs[row-1]
+}
+
+// ToggleReveal shows or hides the value of the selected sensitive output
+func (ov *OutputsView) ToggleReveal() {
+	out := ov.SelectedOutput()
+	if out == nil || !out.Sensitive {
+		return
+	}
+	ov.revealed[out.Name] = !ov.revealed[out.Name]
+	ov.render()
+}
+
+// ToggleRevealAll shows all sensitive values, or hides them if any is shown
+func (ov *OutputsView) ToggleRevealAll() {
+	reveal := true
+	for _, shown := range ov.revealed {
+		if shown {
+			reveal = false
+			break
+		}
+	}
+	ov.revealed = make(map[string]bool)
+	if reveal {
+		for _, out := range ov.outputs {
+			if out.Sensitive {
+				ov.revealed[out.Name] = true
+			}
+		}
+	}
+	ov.render()
+}
+
+// SetConsumers sets the stacks reading these outputs through terraform_remote_state
+func (ov *OutputsView) SetConsumers(consumers []*model.OutputConsumer) {
+	ov.consumers = consumers
+	ov.scanned = true
+	ov.render()
+}
+
+// render fills the table, keeping the selection
+func (ov *OutputsView) render() {
+	row, _ := ov.table.GetSelection()
+	ov.table.Clear()
+
+	headers := []string{"NAME", "TYPE", "SENSITIVE", "USED BY", "VALUE"}
+	for col, h := range headers {
+		ov.table.SetCell(0, col, tview.NewTableCell(h).
+			SetTextColor(tcell.NewRGBColor(255, 215, 0)).
+			SetAttributes(tcell.AttrBold).
+			SetSelectable(false))
+	}
+
+	for i, out := range ov.outputs {
+		sensitive := ""
+		if out.Sensitive {
+			sensitive = "yes"
+		}
+		usedBy := "-"
+		if ov.scanned {
+			usedBy = fmt.Sprintf("%d", len(ov.stacksUsing(out.Name)))
+		}
+		value := strings.ReplaceAll(ov.displayValue(out, false), "\n", " ")
+		if len(value) > 80 {
+			value = value[:77] + "..."
+		}
+
+		ov.table.SetCell(i+1, 0, tview.NewTableCell(out.Name).SetTextColor(tcell.NewRGBColor(100, 255, 100)))
+		ov.table.SetCell(i+1, 1, tview.NewTableCell(FormatOutputType(out.Type)).SetTextColor(tcell.NewRGBColor(100, 200, 255)))
+		ov.table.SetCell(i+1, 2, tview.NewTableCell(sensitive).SetTextColor(tcell.ColorYellow))
+		ov.table.SetCell(i+1, 3, tview.NewTableCell(usedBy).SetTextColor(tcell.NewRGBColor(255, 100, 255)))
+		ov.table.SetCell(i+1, 4, tview.NewTableCell(tview.Escape(value)).SetTextColor(tcell.ColorWhite).SetExpansion(1))
+	}
+
+	if row < 1 {
+		row = 1
+	}
+	if row > len(ov.outputs) {
+		row = len(ov.outputs)
+	}
+	ov.table.Select(row, 0)
+	ov.showDetail()
+}
+
+// showDetail shows the full value and consumers of the selected output
+func (ov *OutputsView) showDetail() {
+	ov.detail.Clear()
+	ov.detail.ScrollToBeginning()
+
+	out := ov.SelectedOutput()
+	if out == nil {
+		fmt.Fprintf(ov.detail, "[gray]No outputs found for this stack.[white]\n")
+		return
+	}
+
+	fmt.Fprintf(ov.detail, "[cyan]Output:[white] %s\n", out.Name)
+	fmt.Fprintf(ov.detail, "[cyan]Type:[white]   %s\n", FormatOutputType(out.Type))
+	if out.Sensitive {
+		if ov.revealed[out.Name] {
+			fmt.Fprintf(ov.detail, "[red]Sensitive (revealed, press v to hide)[white]\n")
+		} else {
+			fmt.Fprintf(ov.detail, "[yellow]Sensitive (press v to reveal)[white]\n")
+		}
+	}
+	fmt.Fprintf(ov.detail, "\n%s\n", tview.Escape(ov.displayValue(out, true)))
+
+	fmt.Fprintf(ov.detail, "\n[yellow]Used by:[white]\n")
+	if !ov.scanned {
+		fmt.Fprintf(ov.detail, "  [gray]Scanning stacks for terraform_remote_state...[white]\n")
+		return
+	}
+	refs := 0
+	for _, c := range ov.consumers {
+		if c.Output != out.Name {
+			continue
+		}
+		fmt.Fprintf(ov.detail, "  • [green]%s[white] [gray](%s:%d)[white]\n",
+			tview.Escape(ov.relative(c.Stack)), filepath.Base(c.File), c.Line)
+		refs++
+	}
+	if refs == 0 {
+		fmt.Fprintf(ov.detail, "  [gray]No stack reads this output through terraform_remote_state[white]\n")
+	}
+}
+
+// displayValue formats a value, masking sensitive values that are not revealed
+func (ov *OutputsView) displayValue(out *model.StateOutput, indent bool) string {
+	if out.Sensitive && !ov.revealed[out.Name] {
+		return model.SensitiveMask
+	}
+	if !indent {
+		if s, ok := out.Value.(string); ok {
+			return s
+		}
+		data, err := json.Marshal(out.Value)
+		if err != nil {
+			return fmt.Sprintf("%v", out.Value)
+		}
+		return string(data)
+	}
+	return formatValue(out.Value)
+}
+
+// stacksUsing returns the distinct stacks reading an output
+func (ov *OutputsView) stacksUsing(name string) []string {
+	seen := make(map[string]bool)
+	var stacks []string
+	for _, c := range ov.consumers {
+		if c.Output == name && !seen[c.Stack] {
+			seen[c.Stack] = true
+			stacks = append(stacks, c.Stack)
+		}
+	}
+	return stacks
+}
+
+// relative shortens a stack path relative to the root
+func (ov *OutputsView) relative(path string) string {
+	if rel, err := filepath.Rel(ov.root, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+// OutputValue returns the value of an output as copied to the clipboard:
+// strings as is, anything else as JSON
+func OutputValue(out *model.StateOutput) string {
+	if s, ok := out.Value.(string); ok {
+		return s
+	}
+	return formatValue(out.Value)
+}
+
+// FormatOutputType formats a terraform type constraint, e.g. ["list","string"] as list(string)
+func FormatOutputType(t interface{}) string {
+	switch v := t.(type) {
+	case string:
+		return v
+	case []interface{}:
+		if len(v) == 2 {
+			kind, _ := v[0].(string)
+			switch kind {
+			case "list", "set", "map":
+				return fmt.Sprintf("%s(%s)", kind, FormatOutputType(v[1]))
+			case "object", "tuple":
+				return kind
+			}
+		}
+	case nil:
+		return "-"
+	}
+	data, _ := json.Marshal(t)
+	return string(data)
+}
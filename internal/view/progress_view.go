@@ -0,0 +1,245 @@
+package view
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/idongju/t9s/internal/model"
+	"github.com/rivo/tview"
+)
+
+// Tabs of the progress view
+const (
+	ProgressTab = iota
+	LogTab
+)
+
+// progressBarWidth is the number of cells of the overall progress bar
+const progressBarWidth = 40
+
+// ProgressView shows the live per-resource status of an apply run with -json,
+// with the human-readable log in a second tab
+type ProgressView struct {
+	*tview.Flex
+	tabs    *tview.TextView
+	summary *tview.TextView
+	pages   *tview.Pages
+	table   *tview.Table
+	log     *tview.TextView
+	tab     int
+	status  string // set when the run has finished
+}
+
+// NewProgressView creates a new progress view for an action such as "Apply" on stack
+func NewProgressView(action, stack string) *ProgressView {
+	pv := &ProgressView{
+		Flex: tview.NewFlex(),
+	}
+
+	pv.tabs = tview.NewTextView().
+		SetDynamicColors(true)
+	pv.tabs.SetBackgroundColor(tcell.ColorBlack)
+
+	pv.summary = tview.NewTextView().
+		SetDynamicColors(true)
+	pv.summary.SetBackgroundColor(tcell.ColorBlack)
+
+	// Resource table
+	pv.table = tview.NewTable().
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	pv.table.SetBackgroundColor(tcell.ColorBlack)
+	pv.table.SetSelectedStyle(tcell.StyleDefault.
+		Background(tcell.NewRGBColor(0, 95, 135)).
+		Foreground(tcell.ColorWhite))
+
+	// Raw log
+	pv.log = tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetWordWrap(true)
+	pv.log.SetBackgroundColor(tcell.ColorBlack)
+
+	pv.pages = tview.NewPages().
+		AddPage("progress", pv.table, true, true).
+		AddPage("log", pv.log, true, false)
+
+	body := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(pv.tabs, 1, 0, false).
+		AddItem(pv.summary, 2, 0, false).
+		AddItem(pv.pages, 0, 1, true)
+	body.SetBackgroundColor(tcell.ColorBlack)
+	body.SetBorder(true)
+	body.SetBorderColor(tcell.NewRGBColor(0, 255, 255))
+	body.SetTitle(fmt.Sprintf(" 🚀 %s: %s ", action, stack))
+
+	// Help line
+	help := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignLeft)
+	help.SetBackgroundColor(tcell.ColorBlack)
+	fmt.Fprintf(help, "[yellow]<1/2>[white] Progress/Log  [yellow]<Tab>[white] Switch Tab  [yellow]<Esc>[white] Back (keeps running)")
+
+	pv.SetDirection(tview.FlexRow).
+		AddItem(body, 0, 1, true).
+		AddItem(help, 1, 0, false)
+	pv.SetBackgroundColor(tcell.ColorBlack)
+
+	pv.Update(model.NewApplyProgress())
+	pv.ShowTab(ProgressTab)
+	return pv
+}
+
+// Focusable returns the primitive of the current tab
+func (pv *ProgressView) Focusable() tview.Primitive {
+	if pv.tab == LogTab {
+		return pv.log
+	}
+	return pv.table
+}
+
+// Tab returns the current tab
+func (pv *ProgressView) Tab() int {
+	return pv.tab
+}
+
+// ShowTab switches to the progress table or the log
+func (pv *ProgressView) ShowTab(tab int) {
+	pv.tab = tab
+	pv.tabs.Clear()
+	labels := []string{"1 Progress", "2 Log"}
+	for i, label := range labels {
+		if i == tab {
+			fmt.Fprintf(pv.tabs, " [black:#00ffff:b] %s [-:-:-]", label)
+		} else {
+			fmt.Fprintf(pv.tabs, " [gray] %s [-]", label)
+		}
+	}
+	if tab == LogTab {
+		pv.pages.SwitchToPage("log")
+	} else {
+		pv.pages.SwitchToPage("progress")
+	}
+}
+
+// AppendLog adds a line of human-readable output, which may contain ANSI colors
+func (pv *ProgressView) AppendLog(line string) {
+	w := tview.ANSIWriter(pv.log)
+	w.Write([]byte(line + "\n"))
+	if pv.tab != LogTab {
+		pv.log.ScrollToEnd()
+	}
+}
+
+// Finish shows the outcome of the run; err is nil when it succeeded
+func (pv *ProgressView) Finish(p *model.ApplyProgress, err error) {
+	if err != nil {
+		pv.status = fmt.Sprintf("[red::b]✗ Failed:[-:-:-] %s", tview.Escape(err.Error()))
+	} else {
+		pv.status = "[green::b]✓ Done[-:-:-]"
+	}
+	pv.Update(p)
+}
+
+// Update renders the counts, the progress bar and the resource table
+func (pv *ProgressView) Update(p *model.ApplyProgress) {
+	pending, running, complete, errored := p.Counts()
+	total := len(p.Resources)
+
+	pv.summary.Clear()
+	fmt.Fprintf(pv.summary, " [white]%d resources  [gray]%d pending[-]  [yellow]%d running[-]  [green]%d complete[-]  [red]%d errored[-]",
+		total, pending, running, complete, errored)
+	if p.Finished {
+		fmt.Fprintf(pv.summary, "  [gray]│[-] %d added, %d changed, %d destroyed", p.Added, p.Changed, p.Destroyed)
+	}
+	fmt.Fprintf(pv.summary, "\n %s", progressBar(complete+errored, total, errored > 0))
+	if pv.status != "" {
+		fmt.Fprintf(pv.summary, "  %s", pv.status)
+	}
+
+	row, _ := pv.table.GetSelection()
+	pv.table.Clear()
+	headers := []string{"STATE", "ACTION", "RESOURCE", "ELAPSED"}
+	for col, h := range headers {
+		pv.table.SetCell(0, col, tview.NewTableCell(h).
+			SetTextColor(tcell.NewRGBColor(255, 215, 0)).
+			SetSelectable(false).
+			SetAttributes(tcell.AttrBold))
+	}
+	for i, r := range p.Resources {
+		icon, color := resourceState(r.State)
+		elapsed := ""
+		if r.State != model.ResourcePending {
+			elapsed = formatElapsed(r.Elapsed)
+		}
+		pv.table.SetCell(i+1, 0, tview.NewTableCell(icon+" "+r.State).SetTextColor(color))
+		pv.table.SetCell(i+1, 1, tview.NewTableCell(r.Action).SetTextColor(actionColor(r.Action)))
+		pv.table.SetCell(i+1, 2, tview.NewTableCell(tview.Escape(r.Address)).
+			SetTextColor(tcell.ColorWhite).
+			SetExpansion(1))
+		pv.table.SetCell(i+1, 3, tview.NewTableCell(elapsed).
+			SetTextColor(tcell.ColorGray).
+			SetAlign(tview.AlignRight))
+	}
+	if row < 1 {
+		row = 1
+	}
+	if row <= total {
+		pv.table.Select(row, 0)
+	}
+}
+
+// progressBar draws done out of total as a bar with a percentage
+func progressBar(done, total int, failed bool) string {
+	percent := 100
+	if total > 0 {
+		percent = done * 100 / total
+	}
+	filled := percent * progressBarWidth / 100
+	color := "green"
+	if failed {
+		color = "red"
+	}
+	return fmt.Sprintf("[%s]%s[gray]%s[-] %3d%% (%d/%d)", color,
+		strings.Repeat("█", filled), strings.Repeat("░", progressBarWidth-filled), percent, done, total)
+}
+
+// resourceState returns the icon and color of a resource state
+func resourceState(state string) (string, tcell.Color) {
+	switch state {
+	case model.ResourceRunning:
+		return "⟳", tcell.NewRGBColor(255, 215, 0)
+	case model.ResourceComplete:
+		return "✓", tcell.NewRGBColor(100, 255, 100)
+	case model.ResourceErrored:
+		return "✗", tcell.NewRGBColor(255, 100, 100)
+	default:
+		return "·", tcell.ColorGray
+	}
+}
+
+// actionColor colors an action like the plan output does
+func actionColor(action string) tcell.Color {
+	switch action {
+	case "create":
+		return tcell.NewRGBColor(100, 255, 100)
+	case "delete":
+		return tcell.NewRGBColor(255, 100, 100)
+	case "replace":
+		return tcell.NewRGBColor(255, 100, 255)
+	default:
+		return tcell.NewRGBColor(255, 215, 0)
+	}
+}
+
+// formatElapsed formats a duration as 5s or 2m05s
+func formatElapsed(d time.Duration) string {
+	d = d.Round(time.Second)
+	if d < time.Minute {
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	}
+	return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+}
@@ -0,0 +1,86 @@
+package view
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// SearchBar is the incremental search prompt of the content pane
+type SearchBar struct {
+	*tview.Flex
+	input         *tview.InputField
+	status        *tview.TextView
+	caseSensitive bool
+	regex         bool
+}
+
+// NewSearchBar creates a new search bar
+func NewSearchBar() *SearchBar {
+	sb := &SearchBar{
+		Flex: tview.NewFlex(),
+	}
+
+	sb.input = tview.NewInputField().
+		SetLabel("/").
+		SetFieldBackgroundColor(tcell.NewRGBColor(30, 30, 30)).
+		SetFieldTextColor(tcell.ColorWhite)
+	sb.input.SetBackgroundColor(tcell.ColorBlack)
+	sb.input.SetLabelColor(tcell.NewRGBColor(255, 215, 0))
+
+	sb.status = tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignRight)
+	sb.status.SetBackgroundColor(tcell.ColorBlack)
+
+	sb.AddItem(sb.input, 0, 1, true).
+		AddItem(sb.status, 44, 0, false)
+	sb.SetBackgroundColor(tcell.ColorBlack)
+
+	sb.SetStatus(0, 0, nil)
+	return sb
+}
+
+// GetInput returns the query input
+func (sb *SearchBar) GetInput() *tview.InputField {
+	return sb.input
+}
+
+// Options returns whether the search is case sensitive and whether the query is a regex
+func (sb *SearchBar) Options() (caseSensitive, regex bool) {
+	return sb.caseSensitive, sb.regex
+}
+
+// ToggleCase switches between case sensitive and insensitive matching
+func (sb *SearchBar) ToggleCase() {
+	sb.caseSensitive = !sb.caseSensitive
+}
+
+// ToggleRegex switches between literal and regex queries
+func (sb *SearchBar) ToggleRegex() {
+	sb.regex = !sb.regex
+}
+
+// SetStatus shows the match counter, or the error of an invalid regex, with the toggles
+func (sb *SearchBar) SetStatus(current, total int, err error) {
+	sb.status.Clear()
+	switch {
+	case err != nil:
+		fmt.Fprintf(sb.status, "[red]invalid regex[white]  ")
+	case sb.input.GetText() == "":
+	case total == 0:
+		fmt.Fprintf(sb.status, "[red]no matches[white]  ")
+	default:
+		fmt.Fprintf(sb.status, "[green]%d/%d[white]  ", current, total)
+	}
+	fmt.Fprintf(sb.status, "%s [gray]^T[white]  %s [gray]^R[white]", toggleLabel("Aa", sb.caseSensitive), toggleLabel(".*", sb.regex))
+}
+
+// toggleLabel renders a search option, highlighted when it is on
+func toggleLabel(label string, on bool) string {
+	if on {
+		return fmt.Sprintf("[black:green]%s[-:-]", tview.Escape(label))
+	}
+	return fmt.Sprintf("[gray]%s[white]", tview.Escape(label))
+}
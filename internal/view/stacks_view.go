@@ -0,0 +1,307 @@
+package view
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/idongju/t9s/internal/config"
+	"github.com/idongju/t9s/internal/model"
+	"github.com/rivo/tview"
+)
+
+// StacksView is the stack dashboard: stacks in dependency order with an ASCII dependency graph
+type StacksView struct {
+	*tview.Flex
+	table  *tview.Table
+	detail *tview.TextView
+	root   string
+	graph  *model.StackGraph
+	order  []string
+	levels map[string]int
+	cycle  error
+	marked map[string]bool
+}
+
+// NewStacksView creates a new stack dashboard; root is used to shorten stack paths
+// and km gives the keys of the help line
+func NewStacksView(root string, graph *model.StackGraph, km *config.Keymap) *StacksView {
+	sv := &StacksView{
+		Flex:   tview.NewFlex(),
+		root:   root,
+		graph:  graph,
+		marked: make(map[string]bool),
+	}
+
+	// Rows follow the dependency order; with a cycle they fall back to path order
+	sv.order, sv.cycle = graph.Order(graph.Stacks)
+	if sv.cycle != nil {
+		sv.order = graph.Stacks
+	}
+	sv.levels, _ = graph.Levels()
+
+	// Stack table
+	sv.table = tview.NewTable().
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	sv.table.SetBackgroundColor(tcell.ColorBlack)
+	sv.table.SetBorder(true)
+	sv.table.SetBorderColor(tcell.NewRGBColor(0, 255, 255))
+	sv.table.SetSelectedStyle(tcell.StyleDefault.
+		Background(tcell.NewRGBColor(0, 95, 135)).
+		Foreground(tcell.ColorWhite))
+	sv.table.SetSelectionChangedFunc(func(row, column int) {
+		sv.showDetail()
+	})
+
+	// Graph pane
+	sv.detail = tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetWrap(false)
+	sv.detail.SetBackgroundColor(tcell.ColorBlack)
+	sv.detail.SetBorder(true)
+	sv.detail.SetBorderColor(tcell.NewRGBColor(0, 255, 255))
+	sv.detail.SetTitle(" Dependency Graph ")
+
+	// Help line
+	help := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignLeft)
+	help.SetBackgroundColor(tcell.ColorBlack)
+	fmt.Fprint(help, helpLine(
+		keyHint(km, config.ActionStacksMark),
+		keyHint(km, config.ActionStacksMarkDeps),
+		keyHint(km, config.ActionStacksPlan),
+		keyHint(km, config.ActionStacksApply),
+		keyHint(km, config.ActionStacksBatch),
+		"[yellow]<Tab>[white] Table/Graph",
+		"[yellow]<Esc>[white] Back",
+	))
+
+	body := tview.NewFlex().
+		AddItem(sv.table, 0, 1, true).
+		AddItem(sv.detail, 0, 1, false)
+
+	sv.SetDirection(tview.FlexRow).
+		AddItem(body, 0, 1, true).
+		AddItem(help, 1, 0, false)
+	sv.SetBackgroundColor(tcell.ColorBlack)
+
+	sv.render()
+	return sv
+}
+
+// GetTable returns the stack table
+func (sv *StacksView) GetTable() *tview.Table {
+	return sv.table
+}
+
+// GetDetail returns the graph pane
+func (sv *StacksView) GetDetail() *tview.TextView {
+	return sv.detail
+}
+
+// SelectedStack returns the stack under the cursor
+func (sv *StacksView) SelectedStack() string {
+	row, _ := sv.table.GetSelection()
+	if row < 1 || row > len(sv.order) {
+		return ""
+	}
+	return sv.order[row-1]
+}
+
+// ToggleMark marks or unmarks the selected stack
+func (sv *StacksView) ToggleMark() {
+	stack := sv.SelectedStack()
+	if stack == "" {
+		return
+	}
+	sv.marked[stack] = !sv.marked[stack]
+	sv.render()
+}
+
+// MarkWithDependencies marks the selected stack and everything it depends on, transitively
+func (sv *StacksView) MarkWithDependencies() {
+	var mark func(stack string)
+	mark = func(stack string) {
+		if sv.marked[stack] {
+			return
+		}
+		sv.marked[stack] = true
+		for _, dep := range sv.graph.DependsOn(stack) {
+			mark(dep)
+		}
+	}
+	if stack := sv.SelectedStack(); stack != "" {
+		sv.marked[stack] = false
+		mark(stack)
+		sv.render()
+	}
+}
+
+// Marked returns the marked stacks, or the selected stack if none is marked
+func (sv *StacksView) Marked() []string {
+	var stacks []string
+	for _, s := range sv.order {
+		if sv.marked[s] {
+			stacks = append(stacks, s)
+		}
+	}
+	if len(stacks) == 0 && sv.SelectedStack() != "" {
+		stacks = []string{sv.SelectedStack()}
+	}
+	return stacks
+}
+
+// Relative shortens a stack path relative to the root
+func (sv *StacksView) Relative(path string) string {
+	if rel, err := filepath.Rel(sv.root, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+// render fills the table, keeping the selection
+func (sv *StacksView) render() {
+	row, _ := sv.table.GetSelection()
+	sv.table.Clear()
+
+	marked := 0
+	for _, m := range sv.marked {
+		if m {
+			marked++
+		}
+	}
+	sv.table.SetTitle(fmt.Sprintf(" 🧱 Stacks: %d (%d marked) ", len(sv.order), marked))
+
+	headers := []string{"", "#", "STACK", "LEVEL", "DEPENDS ON", "USED BY"}
+	for col, h := range headers {
+		sv.table.SetCell(0, col, tview.NewTableCell(h).
+			SetTextColor(tcell.NewRGBColor(255, 215, 0)).
+			SetAttributes(tcell.AttrBold).
+			SetSelectable(false))
+	}
+
+	for i, stack := range sv.order {
+		mark := "[ ]"
+		if sv.marked[stack] {
+			mark = "[x]"
+		}
+		level := "-"
+		if sv.cycle == nil {
+			level = fmt.Sprintf("%d", sv.levels[stack])
+		}
+		sv.table.SetCell(i+1, 0, tview.NewTableCell(tview.Escape(mark)).SetTextColor(tcell.ColorYellow))
+		sv.table.SetCell(i+1, 1, tview.NewTableCell(fmt.Sprintf("%d", i+1)).SetTextColor(tcell.ColorGray))
+		sv.table.SetCell(i+1, 2, tview.NewTableCell(tview.Escape(sv.Relative(stack))).SetTextColor(tcell.NewRGBColor(100, 255, 100)).SetExpansion(1))
+		sv.table.SetCell(i+1, 3, tview.NewTableCell(level).SetTextColor(tcell.NewRGBColor(100, 200, 255)))
+		sv.table.SetCell(i+1, 4, tview.NewTableCell(fmt.Sprintf("%d", len(sv.graph.Deps[stack]))).SetTextColor(tcell.ColorWhite))
+		sv.table.SetCell(i+1, 5, tview.NewTableCell(fmt.Sprintf("%d", len(sv.graph.Dependents(stack)))).SetTextColor(tcell.NewRGBColor(255, 100, 255)))
+	}
+
+	if row < 1 {
+		row = 1
+	}
+	if row > len(sv.order) {
+		row = len(sv.order)
+	}
+	sv.table.Select(row, 0)
+	sv.showDetail()
+}
+
+// showDetail shows the dependencies of the selected stack above the whole graph
+func (sv *StacksView) showDetail() {
+	sv.detail.Clear()
+	sv.detail.ScrollToBeginning()
+
+	if sv.cycle != nil {
+		fmt.Fprintf(sv.detail, "[red::b]⚠ %s[-:-:-]\n[gray]Batch runs are disabled until the cycle is resolved.[white]\n\n", tview.Escape(sv.cycle.Error()))
+	}
+	for _, w := range sv.graph.Warnings {
+		fmt.Fprintf(sv.detail, "[yellow]⚠ %s[white]\n", tview.Escape(w))
+	}
+
+	if stack := sv.SelectedStack(); stack != "" {
+		fmt.Fprintf(sv.detail, "[cyan]Stack:[white] %s\n", tview.Escape(sv.Relative(stack)))
+		fmt.Fprintf(sv.detail, "[cyan]Depends on:[white]\n")
+		if len(sv.graph.Deps[stack]) == 0 {
+			fmt.Fprintf(sv.detail, "  [gray]nothing[white]\n")
+		}
+		for _, dep := range sv.graph.Deps[stack] {
+			where := dep.Source
+			if dep.Line > 0 {
+				where = fmt.Sprintf("%s, %s:%d", dep.Source, filepath.Base(dep.File), dep.Line)
+			}
+			fmt.Fprintf(sv.detail, "  • [green]%s[white] [gray](%s)[white]\n", tview.Escape(sv.Relative(dep.Stack)), tview.Escape(where))
+		}
+		fmt.Fprintf(sv.detail, "[cyan]Used by:[white]\n")
+		dependents := sv.graph.Dependents(stack)
+		if len(dependents) == 0 {
+			fmt.Fprintf(sv.detail, "  [gray]nothing[white]\n")
+		}
+		for _, d := range dependents {
+			fmt.Fprintf(sv.detail, "  • [green]%s[white]\n", tview.Escape(sv.Relative(d)))
+		}
+		fmt.Fprintf(sv.detail, "[cyan]%s[white]\n", strings.Repeat("─", 40))
+	}
+
+	fmt.Fprintf(sv.detail, "%s", tview.Escape(RenderStackGraph(sv.graph, sv.Relative)))
+}
+
+// RenderStackGraph draws the graph as ASCII trees from the stacks that depend on nothing
+// down to their dependents. Stacks drawn before are marked with ↑ instead of repeated.
+func RenderStackGraph(graph *model.StackGraph, name func(stack string) string) string {
+	var b strings.Builder
+	drawn := make(map[string]bool)
+
+	var draw func(stack, prefix string, last bool, depth int)
+	draw = func(stack, prefix string, last bool, depth int) {
+		branch, indent := "├─▶ ", "│   "
+		if last {
+			branch, indent = "└─▶ ", "    "
+		}
+		if depth == 0 {
+			branch, indent = "", ""
+		}
+		if drawn[stack] {
+			b.WriteString(prefix + branch + name(stack) + " ↑\n")
+			return
+		}
+		drawn[stack] = true
+		b.WriteString(prefix + branch + name(stack) + "\n")
+
+		dependents := graph.Dependents(stack)
+		for i, d := range dependents {
+			draw(d, prefix+indent, i == len(dependents)-1, depth+1)
+		}
+	}
+
+	var independent []string
+	for _, root := range graph.Roots() {
+		if len(graph.Dependents(root)) == 0 {
+			independent = append(independent, name(root))
+			continue
+		}
+		draw(root, "", true, 0)
+	}
+
+	// Stacks only reachable through a cycle are not below any root
+	for _, stack := range graph.Stacks {
+		if !drawn[stack] && len(graph.Deps[stack]) > 0 {
+			draw(stack, "", true, 0)
+		}
+	}
+
+	if len(independent) > 0 {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString("No dependencies:\n")
+		for _, s := range independent {
+			b.WriteString("  " + s + "\n")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,256 @@
+package view
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/idongju/t9s/internal/config"
+	"github.com/idongju/t9s/internal/model"
+	"github.com/rivo/tview"
+)
+
+// StateView displays the resources of a terraform state as a tree with a detail pane
+type StateView struct {
+	*tview.Flex
+	filter *tview.InputField
+	tree   *tview.TreeView
+	detail *tview.TextView
+	stack  string
+	state  *model.State
+}
+
+// NewStateView creates a new state view, with the keys of km in its help line
+func NewStateView(stack string, state *model.State, km *config.Keymap) *StateView {
+	sv := &StateView{
+		Flex:  tview.NewFlex(),
+		stack: stack,
+		state: state,
+	}
+
+	// Filter input
+	sv.filter = tview.NewInputField().
+		SetLabel("Filter: ").
+		SetPlaceholder("type or address, e.g. aws_s3_bucket or module.vpc").
+		SetFieldBackgroundColor(tcell.NewRGBColor(30, 30, 30)).
+		SetFieldTextColor(tcell.ColorWhite)
+	sv.filter.SetBackgroundColor(tcell.ColorBlack)
+	sv.filter.SetLabelColor(tcell.NewRGBColor(0, 255, 255))
+	sv.filter.SetChangedFunc(func(text string) {
+		sv.rebuild()
+	})
+
+	// Resource tree
+	sv.tree = tview.NewTreeView()
+	sv.tree.SetBackgroundColor(tcell.ColorBlack)
+	sv.tree.SetBorder(true)
+	sv.tree.SetBorderColor(tcell.NewRGBColor(0, 255, 255))
+	sv.tree.SetGraphicsColor(tcell.NewRGBColor(0, 255, 255))
+	sv.tree.SetChangedFunc(func(node *tview.TreeNode) {
+		sv.showDetail(node)
+	})
+	sv.tree.SetSelectedFunc(func(node *tview.TreeNode) {
+		node.SetExpanded(!node.IsExpanded())
+	})
+
+	// Detail pane
+	sv.detail = tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetWrap(true)
+	sv.detail.SetBackgroundColor(tcell.ColorBlack)
+	sv.detail.SetBorder(true)
+	sv.detail.SetBorderColor(tcell.NewRGBColor(0, 255, 255))
+	sv.detail.SetTitle(" Attributes ")
+
+	// Help line
+	help := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignLeft)
+	help.SetBackgroundColor(tcell.ColorBlack)
+	fmt.Fprint(help, helpLine(
+		keyHint(km, config.ActionStateFilter),
+		"[yellow]<Tab>[white] Tree/Attributes",
+		"[yellow]<Enter>[white] Expand",
+		keyHint(km, config.ActionStateMove),
+		keyHint(km, config.ActionStateRemove),
+		keyHint(km, config.ActionStateImport),
+		keyHint(km, config.ActionStateReplace),
+		keyHint(km, config.ActionStateTarget),
+		"[yellow]<Esc>[white] Back",
+	))
+
+	body := tview.NewFlex().
+		AddItem(sv.tree, 0, 1, true).
+		AddItem(sv.detail, 0, 1, false)
+
+	sv.SetDirection(tview.FlexRow).
+		AddItem(sv.filter, 1, 0, false).
+		AddItem(body, 0, 1, true).
+		AddItem(help, 1, 0, false)
+	sv.SetBackgroundColor(tcell.ColorBlack)
+
+	sv.rebuild()
+	return sv
+}
+
+// GetTree returns the resource tree
+func (sv *StateView) GetTree() *tview.TreeView {
+	return sv.tree
+}
+
+// GetFilter returns the filter input
+func (sv *StateView) GetFilter() *tview.InputField {
+	return sv.filter
+}
+
+// GetDetail returns the attribute pane
+func (sv *StateView) GetDetail() *tview.TextView {
+	return sv.detail
+}
+
+// SelectedResource returns the resource under the cursor, nil for modules and outputs
+func (sv *StateView) SelectedResource() *model.StateResource {
+	node := sv.tree.GetCurrentNode()
+	if node == nil {
+		return nil
+	}
+	res, _ := node.GetReference().(*model.StateResource)
+	return res
+}
+
+// rebuild rebuilds the tree for the current filter
+func (sv *StateView) rebuild() {
+	filter := strings.TrimSpace(sv.filter.GetText())
+
+	root := tview.NewTreeNode(sv.stack).
+		SetColor(tcell.NewRGBColor(255, 215, 0)).
+		SetSelectable(true)
+
+	count := 0
+	if sv.state != nil && sv.state.Root != nil {
+		count = sv.addModule(root, sv.state.Root, filter)
+	}
+
+	// Outputs are listed under their own node
+	if sv.state != nil && len(sv.state.Outputs) > 0 && filter == "" {
+		outputs := tview.NewTreeNode(fmt.Sprintf("outputs (%d)", len(sv.state.Outputs))).
+			SetColor(tcell.NewRGBColor(255, 165, 0)).
+			SetSelectable(true)
+		for _, out := range sv.state.Outputs {
+			outputs.AddChild(tview.NewTreeNode(out.Name).
+				SetReference(out).
+				SetColor(tcell.NewRGBColor(200, 200, 200)))
+		}
+		root.AddChild(outputs)
+	}
+
+	sv.tree.SetRoot(root)
+	sv.tree.SetCurrentNode(root)
+
+	total := len(sv.state.Resources())
+	if filter != "" {
+		sv.tree.SetTitle(fmt.Sprintf(" 🗃  State: %d of %d resources ", count, total))
+	} else {
+		sv.tree.SetTitle(fmt.Sprintf(" 🗃  State: %d resources ", total))
+	}
+	sv.showDetail(root)
+}
+
+// addModule adds the resources and child modules matching filter, returning the number of resources added
+func (sv *StateView) addModule(target *tview.TreeNode, module *model.StateModule, filter string) int {
+	count := 0
+	for _, res := range module.Resources {
+		if !res.Matches(filter) {
+			continue
+		}
+		label := strings.TrimPrefix(res.Address, module.Address+".")
+		color := tcell.NewRGBColor(100, 255, 100)
+		if res.Mode == "data" {
+			color = tcell.NewRGBColor(100, 200, 255)
+		}
+		if res.Tainted {
+			label += " (tainted)"
+			color = tcell.ColorRed
+		}
+		target.AddChild(tview.NewTreeNode(label).
+			SetReference(res).
+			SetColor(color))
+		count++
+	}
+
+	for _, child := range module.Children {
+		node := tview.NewTreeNode(strings.TrimPrefix(child.Address, module.Address+".")).
+			SetReference(child).
+			SetColor(tcell.NewRGBColor(255, 100, 255)).
+			SetSelectable(true)
+		n := sv.addModule(node, child, filter)
+		if n == 0 {
+			continue
+		}
+		target.AddChild(node)
+		count += n
+	}
+	return count
+}
+
+// showDetail shows the attributes of the selected node
+func (sv *StateView) showDetail(node *tview.TreeNode) {
+	sv.detail.Clear()
+	sv.detail.ScrollToBeginning()
+	if node == nil {
+		return
+	}
+
+	switch ref := node.GetReference().(type) {
+	case *model.StateResource:
+		fmt.Fprintf(sv.detail, "[cyan]Address:[white]  %s\n", tview.Escape(ref.Address))
+		fmt.Fprintf(sv.detail, "[cyan]Type:[white]     %s\n", ref.Type)
+		fmt.Fprintf(sv.detail, "[cyan]Mode:[white]     %s\n", ref.Mode)
+		fmt.Fprintf(sv.detail, "[cyan]Provider:[white] %s\n", ref.ProviderName)
+		if ref.Index != nil {
+			fmt.Fprintf(sv.detail, "[cyan]Index:[white]    %v\n", ref.Index)
+		}
+		if ref.Tainted {
+			fmt.Fprintf(sv.detail, "[red]Tainted[white]\n")
+		}
+		if len(ref.DependsOn) > 0 {
+			fmt.Fprintf(sv.detail, "[cyan]Depends on:[white]\n")
+			for _, dep := range ref.DependsOn {
+				fmt.Fprintf(sv.detail, "  • %s\n", tview.Escape(dep))
+			}
+		}
+		fmt.Fprintf(sv.detail, "\n[yellow]Attributes:[white]\n")
+		fmt.Fprintf(sv.detail, "%s\n", tview.Escape(formatValue(ref.Values)))
+	case *model.StateModule:
+		fmt.Fprintf(sv.detail, "[cyan]Module:[white] %s\n", tview.Escape(ref.Address))
+		fmt.Fprintf(sv.detail, "[cyan]Resources:[white] %d\n", len(ref.Resources))
+		fmt.Fprintf(sv.detail, "[cyan]Child Modules:[white] %d\n", len(ref.Children))
+	case *model.StateOutput:
+		fmt.Fprintf(sv.detail, "[cyan]Output:[white] %s\n", ref.Name)
+		if ref.Sensitive {
+			fmt.Fprintf(sv.detail, "[yellow]Sensitive[white]\n")
+		}
+		fmt.Fprintf(sv.detail, "\n%s\n", tview.Escape(formatValue(ref.Value)))
+	default:
+		if sv.state == nil {
+			return
+		}
+		fmt.Fprintf(sv.detail, "[cyan]Stack:[white] %s\n", sv.stack)
+		if sv.state.TerraformVersion != "" {
+			fmt.Fprintf(sv.detail, "[cyan]Terraform:[white] %s\n", sv.state.TerraformVersion)
+		}
+		fmt.Fprintf(sv.detail, "[cyan]Resources:[white] %d\n", len(sv.state.Resources()))
+		fmt.Fprintf(sv.detail, "[cyan]Outputs:[white] %d\n", len(sv.state.Outputs))
+	}
+}
+
+// formatValue formats an attribute value as indented JSON
+func formatValue(value interface{}) string {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
@@ -13,6 +13,9 @@ type StatusBar struct {
 	*tview.TextView
 	currentDir      string
 	focusIndicator  string
+	result          string // last terraform result of the selected stack, with style tags
+	path            string // path shown by UpdatePath, "" while the default message is shown
+	message         bool   // a message from ShowMessage is shown
 }
 
 // NewStatusBar creates a new status bar
@@ -32,31 +35,56 @@ func NewStatusBar(currentDir string) *StatusBar {
 // ShowDefault shows the default status message
 func (sb *StatusBar) ShowDefault() {
 	sb.Clear()
+	sb.path, sb.message = "", false
 	
 	helpText := "[yellow]↑↓[white] Navigate  [yellow]Enter[white] Expand/View"
 	if sb.focusIndicator == "Content View" {
 		helpText = "[yellow]↑↓[white] Scroll  [yellow]u/d[white] Fast Scroll"
 	}
 	
-	fmt.Fprintf(sb, "[green]● %s[white]  [yellow]|[white]  [yellow]Tab[white] Switch Focus  [yellow]|[white]  %s  [yellow]q[white] Quit", sb.focusIndicator, helpText)
+	fmt.Fprintf(sb, "%s[green]● %s[white]  [yellow]|[white]  [yellow]Tab[white] Switch Focus  [yellow]|[white]  %s  [yellow]q[white] Quit", sb.resultText(), sb.focusIndicator, helpText)
 }
 
 // UpdatePath updates the status bar with current path
 func (sb *StatusBar) UpdatePath(path string) {
 	relPath, _ := filepath.Rel(sb.currentDir, path)
 	sb.Clear()
+	sb.path, sb.message = path, false
 	
 	helpText := "[yellow]h[white] History  [yellow]e[white] Edit"
 	if sb.focusIndicator == "Content View" {
 		helpText = "[yellow]u/d[white] Fast Scroll"
 	}
 	
-	fmt.Fprintf(sb, "[yellow]Current:[white] %s  [yellow]|[white]  [green]● %s[white]  [yellow]|[white]  [yellow]Tab[white] Switch Focus  [yellow]|[white]  %s  [yellow]q[white] Quit", relPath, sb.focusIndicator, helpText)
+	fmt.Fprintf(sb, "[yellow]Current:[white] %s  [yellow]|[white]  %s[green]● %s[white]  [yellow]|[white]  [yellow]Tab[white] Switch Focus  [yellow]|[white]  %s  [yellow]q[white] Quit", relPath, sb.resultText(), sb.focusIndicator, helpText)
+}
+
+// SetResult sets the last terraform result of the selected stack, "" to show none.
+// It is shown with the default message and the current path, and redrawn at once
+// unless a message is shown.
+func (sb *StatusBar) SetResult(result string) {
+	sb.result = result
+	switch {
+	case sb.message:
+	case sb.path != "":
+		sb.UpdatePath(sb.path)
+	default:
+		sb.ShowDefault()
+	}
+}
+
+// resultText returns the last result followed by a separator, or ""
+func (sb *StatusBar) resultText() string {
+	if sb.result == "" {
+		return ""
+	}
+	return sb.result + "  [yellow]|[white]  "
 }
 
 // ShowMessage displays a message in the status bar
 func (sb *StatusBar) ShowMessage(msg string) {
 	sb.Clear()
+	sb.message = true
 	fmt.Fprintf(sb, "%s", msg)
 }
 
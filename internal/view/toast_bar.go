@@ -0,0 +1,57 @@
+package view
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/idongju/t9s/internal/config"
+	"github.com/rivo/tview"
+)
+
+// ToastBar is the one-line notification shown above the status bar when a background job finishes
+type ToastBar struct {
+	*tview.TextView
+	keymap  *config.Keymap
+	clicked func()
+}
+
+// NewToastBar creates a new toast bar
+func NewToastBar() *ToastBar {
+	tb := &ToastBar{
+		TextView: tview.NewTextView().SetDynamicColors(true),
+	}
+	tb.SetBackgroundColor(tcell.NewRGBColor(30, 30, 30))
+	tb.SetMouseCapture(func(action tview.MouseAction, event *tcell.EventMouse) (tview.MouseAction, *tcell.EventMouse) {
+		if action == tview.MouseLeftClick && tb.clicked != nil {
+			tb.clicked()
+			return action, nil
+		}
+		return action, event
+	})
+	return tb
+}
+
+// SetClickedFunc sets the function called when the toast is clicked
+func (tb *ToastBar) SetClickedFunc(fn func()) {
+	tb.clicked = fn
+}
+
+// SetKeymap sets the key bindings named in the hint to open the output
+func (tb *ToastBar) SetKeymap(km *config.Keymap) {
+	tb.keymap = km
+}
+
+// Show shows the outcome of a finished job in a color by severity; more is the number of
+// other finished jobs whose output has not been opened
+func (tb *ToastBar) Show(color, stack, summary string, more int) {
+	tb.Clear()
+	fmt.Fprintf(tb, " [%s::b]●[-:-:-] [white::b]%s[-:-:-]  [%s]%s[-]", color, tview.Escape(stack), color, tview.Escape(summary))
+	if more > 0 {
+		fmt.Fprintf(tb, "  [gray](+%d more)[-]", more)
+	}
+	if tb.keymap != nil {
+		if label := tb.keymap.Label(config.ActionJobs); label != "" {
+			fmt.Fprintf(tb, "  [yellow]%s[white]/click Open Output", tview.Escape(label))
+		}
+	}
+}
@@ -1,12 +1,18 @@
 package view
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
+	"github.com/idongju/t9s/internal/db"
+	"github.com/idongju/t9s/internal/git"
+	"github.com/idongju/t9s/internal/model"
 	"github.com/rivo/tview"
 )
 
@@ -15,6 +21,15 @@ type TreeView struct {
 	*tview.TreeView
 	currentDir  string
 	onFileSelect func(path string)
+	marked       map[string]bool // directories marked for batch operations
+
+	// Node annotations, keyed by absolute path
+	status      map[string]model.TerraformStatus
+	gitStates   map[string]git.FileState
+	lastApplied map[string]*db.HistoryEntry
+
+	stacksOnly map[string]bool // stack directories shown when only stacks are listed, nil to list everything
+	ancestors  map[string]bool // directories leading to a stack in stacks-only mode
 }
 
 // NewTreeView creates a new tree view
@@ -22,6 +37,8 @@ func NewTreeView(rootDir string) *TreeView {
 	tv := &TreeView{
 		TreeView:   tview.NewTreeView(),
 		currentDir: rootDir,
+		marked:     make(map[string]bool),
+		status:     make(map[string]model.TerraformStatus),
 	}
 
 	root := tview.NewTreeNode(filepath.Base(rootDir)).
@@ -87,21 +104,93 @@ func (tv *TreeView) addTreeChildren(target *tview.TreeNode, path string) {
 		}
 
 		fullPath := filepath.Join(path, file.Name())
-		node := tview.NewTreeNode(file.Name()).
-			SetReference(fullPath).
-			SetSelectable(true)
-
-		if file.IsDir() {
-			node.SetColor(tcell.NewRGBColor(100, 200, 255))
-		} else if strings.HasSuffix(file.Name(), ".tfvars") {
-			node.SetColor(tcell.NewRGBColor(255, 100, 255))
-		} else if strings.HasSuffix(file.Name(), ".tf") {
-			node.SetColor(tcell.NewRGBColor(100, 255, 100))
-		} else {
-			node.SetColor(tcell.NewRGBColor(200, 200, 200))
+		if !tv.visible(fullPath, file) {
+			continue
 		}
+		target.AddChild(tv.newNode(fullPath, file))
+	}
+}
+
+// newNode creates the tree node of a directory entry
+func (tv *TreeView) newNode(fullPath string, file os.FileInfo) *tview.TreeNode {
+	node := tview.NewTreeNode(tv.label(fullPath, file.Name())).
+		SetReference(fullPath).
+		SetSelectable(true)
+
+	if file.IsDir() {
+		node.SetColor(tcell.NewRGBColor(100, 200, 255))
+	} else if strings.HasSuffix(file.Name(), ".tfvars") {
+		node.SetColor(tcell.NewRGBColor(255, 100, 255))
+	} else if strings.HasSuffix(file.Name(), ".tf") {
+		node.SetColor(tcell.NewRGBColor(100, 255, 100))
+	} else {
+		node.SetColor(tcell.NewRGBColor(200, 200, 200))
+	}
+	return node
+}
+
+// Refresh re-reads the children of the expanded nodes of the given directories.
+// Existing nodes are kept so expansion, selection and marks survive the refresh.
+func (tv *TreeView) Refresh(dirs []string) {
+	changed := make(map[string]bool, len(dirs))
+	for _, dir := range dirs {
+		changed[dir] = true
+	}
 
-		target.AddChild(node)
+	root := tv.GetRoot()
+	var walk func(node *tview.TreeNode)
+	walk = func(node *tview.TreeNode) {
+		path, _ := node.GetReference().(string)
+		expanded := node == root || len(node.GetChildren()) > 0
+		if expanded && changed[path] {
+			tv.syncChildren(node, path)
+		}
+		for _, child := range node.GetChildren() {
+			walk(child)
+		}
+	}
+	walk(root)
+}
+
+// syncChildren updates the children of node to match the directory at path
+func (tv *TreeView) syncChildren(node *tview.TreeNode, path string) {
+	files, err := ioutil.ReadDir(path)
+	if err != nil {
+		return
+	}
+
+	existing := make(map[string]*tview.TreeNode)
+	for _, child := range node.GetChildren() {
+		if ref, ok := child.GetReference().(string); ok {
+			existing[ref] = child
+		}
+	}
+
+	current := tv.GetCurrentPath()
+	var children []*tview.TreeNode
+	for _, file := range files {
+		if strings.HasPrefix(file.Name(), ".") && file.Name() != ".terraform" {
+			continue
+		}
+		fullPath := filepath.Join(path, file.Name())
+		if !tv.visible(fullPath, file) {
+			continue
+		}
+		if child, ok := existing[fullPath]; ok {
+			children = append(children, child)
+			delete(existing, fullPath)
+			continue
+		}
+		children = append(children, tv.newNode(fullPath, file))
+	}
+	node.SetChildren(children)
+
+	// Move the selection up when the selected entry was removed
+	for ref := range existing {
+		delete(tv.marked, ref)
+		if current == ref || strings.HasPrefix(current, ref+string(filepath.Separator)) {
+			tv.SetCurrentNode(node)
+		}
 	}
 }
 
@@ -118,3 +207,203 @@ func (tv *TreeView) GetCurrentPath() string {
 	return ref.(string)
 }
 
+// Reveal expands the directories leading to path and selects its node.
+// It returns false when path is outside the root or not listed.
+func (tv *TreeView) Reveal(path string) bool {
+	rel, err := filepath.Rel(tv.currentDir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+
+	node := tv.GetRoot()
+	if rel != "." {
+		dir := tv.currentDir
+		for _, part := range strings.Split(rel, string(filepath.Separator)) {
+			if len(node.GetChildren()) == 0 {
+				tv.addTreeChildren(node, dir)
+			}
+			dir = filepath.Join(dir, part)
+
+			var next *tview.TreeNode
+			for _, child := range node.GetChildren() {
+				if ref, ok := child.GetReference().(string); ok && ref == dir {
+					next = child
+					break
+				}
+			}
+			if next == nil {
+				return false
+			}
+			node = next
+		}
+	}
+	tv.SetCurrentNode(node)
+	return true
+}
+
+// ToggleMark marks or unmarks the selected directory for batch operations
+func (tv *TreeView) ToggleMark() {
+	node := tv.GetCurrentNode()
+	path := tv.GetCurrentPath()
+	if node == nil || path == "" || path == tv.currentDir {
+		return
+	}
+	if info, err := os.Stat(path); err != nil || !info.IsDir() {
+		return
+	}
+	if tv.marked[path] {
+		delete(tv.marked, path)
+	} else {
+		tv.marked[path] = true
+	}
+	node.SetText(tv.label(path, filepath.Base(path)))
+}
+
+// Marked returns the marked directories, sorted
+func (tv *TreeView) Marked() []string {
+	var paths []string
+	for path := range tv.marked {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// SetStatus sets the status badge of a stack directory
+func (tv *TreeView) SetStatus(dir string, status model.TerraformStatus) {
+	tv.status[dir] = status
+	tv.relabel()
+}
+
+// SetGitStates sets the git markers of changed files
+func (tv *TreeView) SetGitStates(states map[string]git.FileState) {
+	tv.gitStates = states
+	tv.relabel()
+}
+
+// SetLastApplied sets the last successful apply of each directory, skipping entries without a valid time
+func (tv *TreeView) SetLastApplied(applied map[string]*db.HistoryEntry) {
+	tv.lastApplied = make(map[string]*db.HistoryEntry, len(applied))
+	for dir, entry := range applied {
+		if !entry.Timestamp.IsZero() {
+			tv.lastApplied[dir] = entry
+		}
+	}
+	tv.relabel()
+}
+
+// SetStacksOnly lists only the given stack directories, the directories leading to them
+// and their tfvars files. A nil map lists everything again. The tree is rebuilt collapsed.
+func (tv *TreeView) SetStacksOnly(stacks map[string]bool) {
+	tv.stacksOnly = stacks
+	tv.ancestors = make(map[string]bool)
+	for stack := range stacks {
+		for dir := stack; dir != tv.currentDir && filepath.Dir(dir) != dir; {
+			dir = filepath.Dir(dir)
+			tv.ancestors[dir] = true
+		}
+	}
+
+	root := tv.GetRoot()
+	root.ClearChildren()
+	tv.addTreeChildren(root, tv.currentDir)
+	tv.SetCurrentNode(root)
+}
+
+// StacksOnly reports whether only stacks are listed
+func (tv *TreeView) StacksOnly() bool {
+	return tv.stacksOnly != nil
+}
+
+// visible reports whether an entry is listed, which is always the case unless only stacks are listed
+func (tv *TreeView) visible(path string, file os.FileInfo) bool {
+	if tv.stacksOnly == nil {
+		return true
+	}
+	if file.IsDir() {
+		if tv.stacksOnly[path] || tv.ancestors[path] {
+			return true
+		}
+		// Directories such as config/ holding the tfvars of a stack
+		if tv.stack(path) != "" {
+			matches, _ := filepath.Glob(filepath.Join(path, "*.tfvars"))
+			return len(matches) > 0
+		}
+		return false
+	}
+	return strings.HasSuffix(file.Name(), ".tfvars") && tv.stack(filepath.Dir(path)) != ""
+}
+
+// stack returns the listed stack containing dir, or an empty string
+func (tv *TreeView) stack(dir string) string {
+	for {
+		if tv.stacksOnly[dir] {
+			return dir
+		}
+		if dir == tv.currentDir || filepath.Dir(dir) == dir {
+			return ""
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// relabel updates the text of every node after annotations changed
+func (tv *TreeView) relabel() {
+	tv.GetRoot().Walk(func(node, parent *tview.TreeNode) bool {
+		if path, ok := node.GetReference().(string); ok {
+			node.SetText(tv.label(path, filepath.Base(path)))
+		}
+		return true
+	})
+}
+
+// label returns the node text of a path: a check mark when it is marked, the name
+// and the stack status, git state and last apply of the path
+func (tv *TreeView) label(path, name string) string {
+	text := tview.Escape(name)
+	if tv.marked[path] {
+		text = "✔ " + text
+	}
+
+	switch tv.status[path] {
+	case model.StatusSynced:
+		text += " [green]✓ synced[-]"
+	case model.StatusDrift:
+		text += " [yellow]⚠ drift[-]"
+	case model.StatusError:
+		text += " [red]✗ error[-]"
+	case model.StatusPending:
+		text += " [orange]⌛ pending[-]"
+	}
+
+	switch tv.gitStates[path] {
+	case git.FileModified:
+		text += " [yellow]M[-]"
+	case git.FileStaged:
+		text += " [green]S[-]"
+	case git.FileUntracked:
+		text += " [gray]U[-]"
+	}
+
+	if entry := tv.lastApplied[path]; entry != nil {
+		text += fmt.Sprintf(" [gray]last applied %s by %s[-]", FormatAge(entry.Timestamp), tview.Escape(entry.User))
+	}
+	return text
+}
+
+// FormatAge describes how long ago t was, e.g. "just now", "5m ago" or "3 days ago"
+func FormatAge(t time.Time) string {
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	case d < 48*time.Hour:
+		return "1 day ago"
+	default:
+		return fmt.Sprintf("%d days ago", int(d.Hours()/24))
+	}
+}